@@ -6,6 +6,7 @@ package di
 import (
 	"github.com/google/wire"
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor"
 	internalDI "github.com/nuea/backend-golang-test/internal/di"
 )
 
@@ -13,6 +14,7 @@ var MainSet = wire.NewSet(
 	internalDI.InternalSet,
 	ProviderSet,
 	handler.HandlerSet,
+	interceptor.InterceptorSet,
 
 	wire.Struct(new(Container), "*"),
 )
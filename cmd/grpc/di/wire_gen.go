@@ -9,15 +9,36 @@ package di
 import (
 	"github.com/google/wire"
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler"
+	apikey3 "github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/apikey"
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/auth"
 	user2 "github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/user"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor"
+	auth2 "github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor/auth"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor/permission"
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/server"
 	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/client/mailer"
 	"github.com/nuea/backend-golang-test/internal/client/mongodb"
+	"github.com/nuea/backend-golang-test/internal/client/oidc"
 	"github.com/nuea/backend-golang-test/internal/config"
 	"github.com/nuea/backend-golang-test/internal/di"
 	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/emailverification"
+	"github.com/nuea/backend-golang-test/internal/repository/loginattempt"
+	"github.com/nuea/backend-golang-test/internal/repository/mfachallenge"
+	"github.com/nuea/backend-golang-test/internal/repository/oauthclient"
+	"github.com/nuea/backend-golang-test/internal/repository/oidcstate"
+	"github.com/nuea/backend-golang-test/internal/repository/passwordreset"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/revokedtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
+	apikey2 "github.com/nuea/backend-golang-test/internal/service/apikey"
+	"github.com/nuea/backend-golang-test/internal/service/password"
+	"github.com/nuea/backend-golang-test/internal/service/token"
 )
 
 // Injectors from di.go:
@@ -28,36 +49,102 @@ func InitContainer() (*Container, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	mailerMailer, err := mailer.ProvideMailer(appConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	provider := oidc.ProvideOIDCProvider(appConfig)
 	clients := &client.Clients{
 		MongoDB: mongoDB,
+		Mailer:  mailerMailer,
+		OIDC:    provider,
 	}
 	userRepository := user.ProvideUserRepository(clients)
+	refreshTokenRepository := refreshtoken.ProvideRefreshTokenRepository(clients)
+	revokedTokenRepository := revokedtoken.ProvideRevokedTokenRepository(clients)
+	passwordResetRepository := passwordreset.ProvidePasswordResetRepository(clients)
+	emailVerificationRepository := emailverification.ProvideEmailVerificationRepository(clients)
+	loginAttemptRepository := loginattempt.ProvideLoginAttemptRepository(clients)
+	mfaChallengeRepository := mfachallenge.ProvideMFAChallengeRepository(clients)
+	apiKeyRepository := apikey.ProvideAPIKeyRepository(clients)
+	serviceAccountRepository := serviceaccount.ProvideServiceAccountRepository(clients)
+	oidcStateRepository := oidcstate.ProvideOIDCStateRepository(clients)
+	oAuthClientRepository := oauthclient.ProvideOAuthClientRepository(clients)
+	sessionRepository := session.ProvideSessionRepository(clients)
+	auditLogRepository := auditlog.ProvideAuditLogRepository(clients)
 	repositoryRepository := &repository.Repository{
-		UserRepository: userRepository,
+		UserRepository:              userRepository,
+		RefreshTokenRepository:      refreshTokenRepository,
+		RevokedTokenRepository:      revokedTokenRepository,
+		PasswordResetRepository:     passwordResetRepository,
+		EmailVerificationRepository: emailVerificationRepository,
+		LoginAttemptRepository:      loginAttemptRepository,
+		MFAChallengeRepository:      mfaChallengeRepository,
+		APIKeyRepository:            apiKeyRepository,
+		ServiceAccountRepository:    serviceAccountRepository,
+		OIDCStateRepository:         oidcStateRepository,
+		OAuthClientRepository:       oAuthClientRepository,
+		SessionRepository:           sessionRepository,
+		AuditLogRepository:          auditLogRepository,
 	}
-	userServiceServer, err := user2.ProvideUserGRPCService(repositoryRepository)
+	hasher, cleanup2, err := password.ProvideHasher(appConfig)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
-	authServiceServer, err := auth.ProvideAuthGRPCService(repositoryRepository)
+	passwordPolicy, err := password.ProvidePasswordPolicy(appConfig, hasher)
 	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	userServiceServer, err := user2.ProvideUserGRPCService(appConfig, repositoryRepository, clients, passwordPolicy, hasher)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	tokenService, err := token.ProvideTokenService(appConfig)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	authServiceServer, err := auth.ProvideAuthGRPCService(appConfig, repositoryRepository, clients, tokenService, passwordPolicy, hasher)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	apiKeyService := apikey2.ProvideAPIKeyService(appConfig, repositoryRepository)
+	apiKeyServiceServer, err := apikey3.ProvideAPIKeyGRPCService(repositoryRepository, apiKeyService)
+	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
 	grpcServices := &handler.GrpcServices{
-		UserServiceServer: userServiceServer,
-		AuthServiceServer: authServiceServer,
+		UserServiceServer:   userServiceServer,
+		AuthServiceServer:   authServiceServer,
+		APIKeyServiceServer: apiKeyServiceServer,
+	}
+	authInterceptor := auth2.ProvideAuthInterceptor(appConfig, repositoryRepository, tokenService, apiKeyService)
+	permissionInterceptor := permission.ProvidePermissionInterceptor()
+	interceptors := &interceptor.Interceptors{
+		Auth:       authInterceptor,
+		Permission: permissionInterceptor,
 	}
-	grpcServer := server.ProvideGRPCServer(appConfig, grpcServices, repositoryRepository)
+	grpcServer := server.ProvideGRPCServer(appConfig, grpcServices, repositoryRepository, interceptors)
 	container := &Container{
 		server: grpcServer,
 	}
 	return container, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
 
 // di.go:
 
-var MainSet = wire.NewSet(di.InternalSet, ProviderSet, handler.HandlerSet, wire.Struct(new(Container), "*"))
+var MainSet = wire.NewSet(di.InternalSet, ProviderSet, handler.HandlerSet, interceptor.InterceptorSet, wire.Struct(new(Container), "*"))
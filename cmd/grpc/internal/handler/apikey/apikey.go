@@ -0,0 +1,245 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/oauthclient"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	keysv "github.com/nuea/backend-golang-test/internal/service/apikey"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type grpcService struct {
+	userv1.UnimplementedAPIKeyServiceServer
+	keyrepo    apikey.APIKeyRepository
+	sarepo     serviceaccount.ServiceAccountRepository
+	clientrepo oauthclient.OAuthClientRepository
+	apikeysv   keysv.APIKeyService
+}
+
+func ProvideAPIKeyGRPCService(repo *repository.Repository, apikeysv keysv.APIKeyService) (userv1.APIKeyServiceServer, error) {
+	return &grpcService{
+		keyrepo:    repo.APIKeyRepository,
+		sarepo:     repo.ServiceAccountRepository,
+		clientrepo: repo.OAuthClientRepository,
+		apikeysv:   apikeysv,
+	}, nil
+}
+
+type owner struct {
+	Type string
+	ID   string
+}
+
+// ownerOf returns the owner of the keys a request acts on: the service
+// account when serviceAccountID is set, the caller otherwise.
+func (g *grpcService) ownerOf(ctx context.Context, serviceAccountID *string) (*owner, error) {
+	claims := identity.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	if serviceAccountID != nil {
+		if !claims.HasPermission(rbac.PermissionServiceAccountsManage) {
+			return nil, status.Error(codes.PermissionDenied, "Permission denied.")
+		}
+		sa, err := g.sarepo.FindByID(ctx, *serviceAccountID)
+		if err != nil {
+			if errors.Is(err, serviceaccount.ErrServiceAccountNotFound) {
+				return nil, status.Error(codes.NotFound, "Service account not found.")
+			}
+			return nil, err
+		}
+		return &owner{Type: apikey.OwnerTypeServiceAccount, ID: sa.ID.Hex()}, nil
+	}
+
+	if claims.ServiceAccountID != "" {
+		return &owner{Type: apikey.OwnerTypeServiceAccount, ID: claims.ServiceAccountID}, nil
+	}
+	return &owner{Type: apikey.OwnerTypeUser, ID: claims.UserID}, nil
+}
+
+// canManage reports whether the caller may revoke k: its owner, or anyone
+// allowed to manage the service account that owns it.
+func canManage(claims *token.JwtToken, k *apikey.APIKey) bool {
+	switch k.OwnerType {
+	case apikey.OwnerTypeUser:
+		return claims.UserID != "" && k.OwnerID == claims.UserID
+	case apikey.OwnerTypeServiceAccount:
+		return k.OwnerID == claims.ServiceAccountID || claims.HasPermission(rbac.PermissionServiceAccountsManage)
+	}
+	return false
+}
+
+// CreateAPIKey is not allowed with an API key, see handler.SessionOnlyMethods.
+func (g *grpcService) CreateAPIKey(ctx context.Context, req *userv1.CreateAPIKeyRequest) (*userv1.CreateAPIKeyResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "Name is required.")
+	}
+
+	// Any known permission is accepted, the roles of the owner are checked
+	// again on every call, see token.JwtToken.HasPermission.
+	for _, scope := range req.Scopes {
+		if _, err := rbac.ParsePermission(scope); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Scope %s is invalid.", scope)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.AsTime().After(time.Now()) {
+		return nil, status.Error(codes.InvalidArgument, "Expiry must be in the future.")
+	}
+
+	o, err := g.ownerOf(ctx, req.ServiceAccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	k, key, err := apikey.NewAPIKey(req.Name, o.ID, o.Type, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	k.CreatedBy = identity.UserIDFromContext(ctx)
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.AsTime().UTC()
+		k.ExpiresAt = &expiresAt
+	}
+
+	if err := g.keyrepo.InsertOne(ctx, k); err != nil {
+		return nil, err
+	}
+
+	data, err := mapGRPCAPIKey(k)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.CreateAPIKeyResponse{ApiKey: data, Key: key}, nil
+}
+
+func (g *grpcService) ListAPIKeys(ctx context.Context, req *userv1.ListAPIKeysRequest) (*userv1.ListAPIKeysResponse, error) {
+	o, err := g.ownerOf(ctx, req.ServiceAccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := g.keyrepo.FindByOwner(ctx, o.Type, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	datas, err := util.MapToSlice(mapGRPCAPIKey, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.ListAPIKeysResponse{Data: datas}, nil
+}
+
+func (g *grpcService) RevokeAPIKey(ctx context.Context, req *userv1.RevokeAPIKeyRequest) (*userv1.RevokeAPIKeyResponse, error) {
+	claims := identity.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	k, err := g.keyrepo.FindByID(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+			return nil, status.Error(codes.NotFound, "API key not found.")
+		}
+		return nil, err
+	}
+
+	// Keys of other owners are reported as missing, not forbidden, so ids
+	// cannot be probed.
+	if !canManage(claims, k) {
+		return nil, status.Error(codes.NotFound, "API key not found.")
+	}
+
+	if err := g.keyrepo.Revoke(ctx, k.ID); err != nil {
+		return nil, err
+	}
+	return &userv1.RevokeAPIKeyResponse{}, nil
+}
+
+// VerifyAPIKey is public, the HTTP gateway calls it to authenticate requests
+// carrying an X-API-Key header.
+func (g *grpcService) VerifyAPIKey(ctx context.Context, req *userv1.VerifyAPIKeyRequest) (*userv1.VerifyAPIKeyResponse, error) {
+	claims, err := g.apikeysv.Authenticate(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &userv1.VerifyAPIKeyResponse{
+		ApiKeyId:  claims.APIKeyID,
+		OwnerId:   claims.UserID,
+		OwnerType: apikey.OwnerTypeUser,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+	}
+	if claims.ServiceAccountID != "" {
+		res.OwnerId = claims.ServiceAccountID
+		res.OwnerType = apikey.OwnerTypeServiceAccount
+	}
+	return res, nil
+}
+
+func (g *grpcService) CreateServiceAccount(ctx context.Context, req *userv1.CreateServiceAccountRequest) (*userv1.CreateServiceAccountResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "Name is required.")
+	}
+
+	for _, r := range req.Roles {
+		if _, err := rbac.ParseRole(r); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	sa := serviceaccount.NewServiceAccount(req.Name, req.Description, req.Roles, identity.UserIDFromContext(ctx))
+	if err := g.sarepo.InsertOne(ctx, sa); err != nil {
+		if errors.Is(err, serviceaccount.ErrServiceAccountExists) {
+			return nil, status.Error(codes.AlreadyExists, "Service account already exists.")
+		}
+		return nil, err
+	}
+
+	data, err := mapGRPCServiceAccount(sa)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.CreateServiceAccountResponse{ServiceAccount: data}, nil
+}
+
+func (g *grpcService) ListServiceAccounts(ctx context.Context, req *userv1.ListServiceAccountsRequest) (*userv1.ListServiceAccountsResponse, error) {
+	accounts, err := g.sarepo.Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	datas, err := util.MapToSlice(mapGRPCServiceAccount, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.ListServiceAccountsResponse{Data: datas}, nil
+}
+
+// DeleteServiceAccount also revokes the keys of the account. They would be
+// rejected anyway once the owner is gone, revoking makes it visible.
+func (g *grpcService) DeleteServiceAccount(ctx context.Context, req *userv1.DeleteServiceAccountRequest) (*userv1.DeleteServiceAccountResponse, error) {
+	if err := g.sarepo.DeleteByID(ctx, req.Id); err != nil {
+		if errors.Is(err, serviceaccount.ErrServiceAccountNotFound) {
+			return nil, status.Error(codes.NotFound, "Service account not found.")
+		}
+		return nil, err
+	}
+
+	if err := g.keyrepo.RevokeByOwner(ctx, apikey.OwnerTypeServiceAccount, req.Id); err != nil {
+		return nil, err
+	}
+	return &userv1.DeleteServiceAccountResponse{}, nil
+}
@@ -0,0 +1,472 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	keysv "github.com/nuea/backend-golang-test/internal/service/apikey"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type mockAPIKeyRepository struct {
+	mock.Mock
+	apikey.APIKeyRepository
+}
+
+func (m *mockAPIKeyRepository) InsertOne(ctx context.Context, k *apikey.APIKey) error {
+	args := m.Called(ctx, k)
+	return args.Error(0)
+}
+
+func (m *mockAPIKeyRepository) FindByID(ctx context.Context, id string) (*apikey.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.APIKey), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) FindByOwner(ctx context.Context, ownerType, ownerID string) ([]*apikey.APIKey, error) {
+	args := m.Called(ctx, ownerType, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*apikey.APIKey), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockAPIKeyRepository) RevokeByOwner(ctx context.Context, ownerType, ownerID string) error {
+	args := m.Called(ctx, ownerType, ownerID)
+	return args.Error(0)
+}
+
+type mockServiceAccountRepository struct {
+	mock.Mock
+	serviceaccount.ServiceAccountRepository
+}
+
+func (m *mockServiceAccountRepository) InsertOne(ctx context.Context, sa *serviceaccount.ServiceAccount) error {
+	args := m.Called(ctx, sa)
+	return args.Error(0)
+}
+
+func (m *mockServiceAccountRepository) FindByID(ctx context.Context, id string) (*serviceaccount.ServiceAccount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceaccount.ServiceAccount), args.Error(1)
+}
+
+func (m *mockServiceAccountRepository) Find(ctx context.Context) ([]*serviceaccount.ServiceAccount, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*serviceaccount.ServiceAccount), args.Error(1)
+}
+
+func (m *mockServiceAccountRepository) DeleteByID(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockAPIKeyService struct {
+	mock.Mock
+	keysv.APIKeyService
+}
+
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, key string) (*token.JwtToken, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*token.JwtToken), args.Error(1)
+}
+
+func withClaims(claims *token.JwtToken) context.Context {
+	return identity.WithClaims(context.Background(), claims)
+}
+
+func TestProvideAPIKeyGRPCService(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		sv, err := ProvideAPIKeyGRPCService(&repository.Repository{}, new(mockAPIKeyService))
+
+		assert.NotNil(t, sv)
+		assert.NoError(t, err)
+	})
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	said := primitive.NewObjectID()
+
+	t.Run("success", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"user"}})
+		expiresAt := time.Now().Add(time.Hour)
+
+		keyrepo.On("InsertOne", ctx, mock.MatchedBy(func(k *apikey.APIKey) bool {
+			return k.OwnerType == apikey.OwnerTypeUser && k.OwnerID == uid && k.CreatedBy == uid &&
+				k.ExpiresAt != nil && k.ExpiresAt.Equal(expiresAt)
+		})).Return(nil).Once()
+
+		res, err := sv.CreateAPIKey(ctx, &userv1.CreateAPIKeyRequest{
+			Name:      "batch",
+			Scopes:    []string{"users:read"},
+			ExpiresAt: timestamppb.New(expiresAt),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, res.Key[:12], res.ApiKey.Prefix)
+		assert.Equal(t, []string{"users:read"}, res.ApiKey.Scopes)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("success - service account", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{keyrepo: keyrepo, sarepo: sarepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"admin"}})
+
+		sarepo.On("FindByID", ctx, said.Hex()).Return(&serviceaccount.ServiceAccount{ID: said}, nil).Once()
+		keyrepo.On("InsertOne", ctx, mock.MatchedBy(func(k *apikey.APIKey) bool {
+			return k.OwnerType == apikey.OwnerTypeServiceAccount && k.OwnerID == said.Hex() && k.CreatedBy == uid
+		})).Return(nil).Once()
+
+		res, err := sv.CreateAPIKey(ctx, &userv1.CreateAPIKeyRequest{
+			Name:             "batch",
+			Scopes:           []string{"users:read"},
+			ServiceAccountId: ptr.String(said.Hex()),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, apikey.OwnerTypeServiceAccount, res.ApiKey.OwnerType)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("service account without permission", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{keyrepo: keyrepo, sarepo: sarepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"support"}})
+
+		res, err := sv.CreateAPIKey(ctx, &userv1.CreateAPIKeyRequest{
+			Name:             "batch",
+			ServiceAccountId: ptr.String(said.Hex()),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		keyrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("service account not found", func(t *testing.T) {
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{sarepo: sarepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"admin"}})
+
+		sarepo.On("FindByID", ctx, said.Hex()).Return(nil, serviceaccount.ErrServiceAccountNotFound).Once()
+
+		res, err := sv.CreateAPIKey(ctx, &userv1.CreateAPIKeyRequest{
+			Name:             "batch",
+			ServiceAccountId: ptr.String(said.Hex()),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.NotFound, "Service account not found."), err)
+	})
+
+	t.Run("name is required", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.CreateAPIKey(withClaims(&token.JwtToken{UserID: uid}), &userv1.CreateAPIKeyRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Name is required."), err)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.CreateAPIKey(withClaims(&token.JwtToken{UserID: uid}), &userv1.CreateAPIKeyRequest{
+			Name:   "batch",
+			Scopes: []string{"users:everything"},
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Scope users:everything is invalid."), err)
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.CreateAPIKey(withClaims(&token.JwtToken{UserID: uid}), &userv1.CreateAPIKeyRequest{
+			Name:      "batch",
+			ExpiresAt: timestamppb.New(time.Now().Add(-time.Hour)),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestListAPIKeys(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+
+	t.Run("success", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid})
+		keys := []*apikey.APIKey{{ID: primitive.NewObjectID(), Name: "batch", OwnerID: uid, OwnerType: apikey.OwnerTypeUser}}
+
+		keyrepo.On("FindByOwner", ctx, apikey.OwnerTypeUser, uid).Return(keys, nil).Once()
+
+		res, err := sv.ListAPIKeys(ctx, &userv1.ListAPIKeysRequest{})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Data, 1)
+		assert.Equal(t, "batch", res.Data[0].Name)
+	})
+
+	t.Run("success - called with a service account key", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{ServiceAccountID: "sa", APIKeyID: "kid"})
+
+		keyrepo.On("FindByOwner", ctx, apikey.OwnerTypeServiceAccount, "sa").Return([]*apikey.APIKey{}, nil).Once()
+
+		res, err := sv.ListAPIKeys(ctx, &userv1.ListAPIKeysRequest{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, res.Data)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.ListAPIKeys(context.Background(), &userv1.ListAPIKeysRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	kid := primitive.NewObjectID()
+
+	t.Run("success", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid})
+
+		keyrepo.On("FindByID", ctx, kid.Hex()).Return(&apikey.APIKey{ID: kid, OwnerID: uid, OwnerType: apikey.OwnerTypeUser}, nil).Once()
+		keyrepo.On("Revoke", ctx, kid).Return(nil).Once()
+
+		res, err := sv.RevokeAPIKey(ctx, &userv1.RevokeAPIKeyRequest{Id: kid.Hex()})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("success - service account key by admin", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"admin"}})
+
+		keyrepo.On("FindByID", ctx, kid.Hex()).Return(&apikey.APIKey{ID: kid, OwnerID: "sa", OwnerType: apikey.OwnerTypeServiceAccount}, nil).Once()
+		keyrepo.On("Revoke", ctx, kid).Return(nil).Once()
+
+		_, err := sv.RevokeAPIKey(ctx, &userv1.RevokeAPIKeyRequest{Id: kid.Hex()})
+
+		assert.NoError(t, err)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("someone else's key", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"admin"}})
+
+		keyrepo.On("FindByID", ctx, kid.Hex()).Return(&apikey.APIKey{ID: kid, OwnerID: "other", OwnerType: apikey.OwnerTypeUser}, nil).Once()
+
+		res, err := sv.RevokeAPIKey(ctx, &userv1.RevokeAPIKeyRequest{Id: kid.Hex()})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.NotFound, "API key not found."), err)
+		keyrepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+	})
+
+	t.Run("api key not found", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{keyrepo: keyrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid})
+
+		keyrepo.On("FindByID", ctx, kid.Hex()).Return(nil, apikey.ErrAPIKeyNotFound).Once()
+
+		res, err := sv.RevokeAPIKey(ctx, &userv1.RevokeAPIKeyRequest{Id: kid.Hex()})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("user key", func(t *testing.T) {
+		apikeysv := new(mockAPIKeyService)
+		sv := &grpcService{apikeysv: apikeysv}
+
+		apikeysv.On("Authenticate", ctx, "bgt_key").Return(&token.JwtToken{
+			UserID: "uid", Roles: []string{"user"}, APIKeyID: "kid", Scopes: []string{"users:read"},
+		}, nil).Once()
+
+		res, err := sv.VerifyAPIKey(ctx, &userv1.VerifyAPIKeyRequest{Key: "bgt_key"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "kid", res.ApiKeyId)
+		assert.Equal(t, "uid", res.OwnerId)
+		assert.Equal(t, apikey.OwnerTypeUser, res.OwnerType)
+		assert.Equal(t, []string{"users:read"}, res.Scopes)
+	})
+
+	t.Run("service account key", func(t *testing.T) {
+		apikeysv := new(mockAPIKeyService)
+		sv := &grpcService{apikeysv: apikeysv}
+
+		apikeysv.On("Authenticate", ctx, "bgt_key").Return(&token.JwtToken{ServiceAccountID: "sa", APIKeyID: "kid"}, nil).Once()
+
+		res, err := sv.VerifyAPIKey(ctx, &userv1.VerifyAPIKeyRequest{Key: "bgt_key"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "sa", res.OwnerId)
+		assert.Equal(t, apikey.OwnerTypeServiceAccount, res.OwnerType)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		apikeysv := new(mockAPIKeyService)
+		sv := &grpcService{apikeysv: apikeysv}
+
+		apikeysv.On("Authenticate", ctx, "bgt_bad").Return(nil, keysv.ErrInvalidAPIKey).Once()
+
+		res, err := sv.VerifyAPIKey(ctx, &userv1.VerifyAPIKeyRequest{Key: "bgt_bad"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, keysv.ErrInvalidAPIKey, err)
+	})
+}
+
+func TestCreateServiceAccount(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"admin"}})
+
+	t.Run("success", func(t *testing.T) {
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{sarepo: sarepo}
+
+		sarepo.On("InsertOne", ctx, mock.MatchedBy(func(sa *serviceaccount.ServiceAccount) bool {
+			return sa.Name == "batch" && sa.CreatedBy == uid
+		})).Return(nil).Once()
+
+		res, err := sv.CreateServiceAccount(ctx, &userv1.CreateServiceAccountRequest{Name: "batch", Roles: []string{"support"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "batch", res.ServiceAccount.Name)
+		assert.Equal(t, []string{"support"}, res.ServiceAccount.Roles)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{sarepo: sarepo}
+
+		res, err := sv.CreateServiceAccount(ctx, &userv1.CreateServiceAccountRequest{Name: "batch", Roles: []string{"root"}})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Role is invalid."), err)
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{sarepo: sarepo}
+
+		sarepo.On("InsertOne", ctx, mock.Anything).Return(serviceaccount.ErrServiceAccountExists).Once()
+
+		res, err := sv.CreateServiceAccount(ctx, &userv1.CreateServiceAccountRequest{Name: "batch"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+}
+
+func TestListServiceAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{sarepo: sarepo}
+
+		sarepo.On("Find", ctx).Return([]*serviceaccount.ServiceAccount{{ID: primitive.NewObjectID(), Name: "batch"}}, nil).Once()
+
+		res, err := sv.ListServiceAccounts(ctx, &userv1.ListServiceAccountsRequest{})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Data, 1)
+		assert.Equal(t, "batch", res.Data[0].Name)
+	})
+}
+
+func TestDeleteServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	said := primitive.NewObjectID().Hex()
+
+	t.Run("success", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{keyrepo: keyrepo, sarepo: sarepo}
+
+		sarepo.On("DeleteByID", ctx, said).Return(nil).Once()
+		keyrepo.On("RevokeByOwner", ctx, apikey.OwnerTypeServiceAccount, said).Return(nil).Once()
+
+		res, err := sv.DeleteServiceAccount(ctx, &userv1.DeleteServiceAccountRequest{Id: said})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("service account not found", func(t *testing.T) {
+		keyrepo := new(mockAPIKeyRepository)
+		sarepo := new(mockServiceAccountRepository)
+		sv := &grpcService{keyrepo: keyrepo, sarepo: sarepo}
+
+		sarepo.On("DeleteByID", ctx, said).Return(serviceaccount.ErrServiceAccountNotFound).Once()
+
+		res, err := sv.DeleteServiceAccount(ctx, &userv1.DeleteServiceAccountRequest{Id: said})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.NotFound, "Service account not found."), err)
+		keyrepo.AssertNotCalled(t, "RevokeByOwner", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,69 @@
+package apikey
+
+import (
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/oauthclient"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func mapGRPCAPIKey(k *apikey.APIKey) (*userv1.APIKey, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	response := &userv1.APIKey{
+		Id:        k.ID.Hex(),
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		OwnerId:   k.OwnerID,
+		OwnerType: k.OwnerType,
+		Scopes:    k.Scopes,
+		CreatedAt: timestamppb.New(k.CreatedAt),
+	}
+
+	if k.ExpiresAt != nil {
+		response.ExpiresAt = timestamppb.New(*k.ExpiresAt)
+	}
+
+	if k.LastUsedAt != nil {
+		response.LastUsedAt = timestamppb.New(*k.LastUsedAt)
+	}
+
+	if k.RevokedAt != nil {
+		response.RevokedAt = timestamppb.New(*k.RevokedAt)
+	}
+
+	return response, nil
+}
+
+func mapGRPCServiceAccount(sa *serviceaccount.ServiceAccount) (*userv1.ServiceAccount, error) {
+	if sa == nil {
+		return nil, nil
+	}
+
+	return &userv1.ServiceAccount{
+		Id:          sa.ID.Hex(),
+		Name:        sa.Name,
+		Description: sa.Description,
+		Roles:       sa.Roles,
+		CreatedBy:   sa.CreatedBy,
+		CreatedAt:   timestamppb.New(sa.CreatedAt),
+	}, nil
+}
+
+func mapGRPCOAuthClient(c *oauthclient.OAuthClient) (*userv1.OAuthClient, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	return &userv1.OAuthClient{
+		Id:        c.ID.Hex(),
+		ClientId:  c.ClientID,
+		Name:      c.Name,
+		Scopes:    c.Scopes,
+		CreatedBy: c.CreatedBy,
+		CreatedAt: timestamppb.New(c.CreatedAt),
+	}, nil
+}
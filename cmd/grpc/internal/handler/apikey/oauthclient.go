@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/repository/oauthclient"
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errInvalidClient = status.Error(codes.Unauthenticated, "Client authentication failed.")
+
+// CreateOAuthClient grants only scopes the caller holds, a client token is
+// not checked against any roles later on.
+func (g *grpcService) CreateOAuthClient(ctx context.Context, req *userv1.CreateOAuthClientRequest) (*userv1.CreateOAuthClientResponse, error) {
+	claims := identity.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "Name is required.")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Scopes are required.")
+	}
+
+	for _, scope := range req.Scopes {
+		perm, err := rbac.ParsePermission(scope)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Scope %s is invalid.", scope)
+		}
+		if !claims.HasPermission(perm) {
+			return nil, status.Errorf(codes.PermissionDenied, "Scope %s is not granted to the caller.", scope)
+		}
+	}
+
+	c, secret, err := oauthclient.NewOAuthClient(req.Name, req.Scopes, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.clientrepo.InsertOne(ctx, c); err != nil {
+		return nil, err
+	}
+
+	data, err := mapGRPCOAuthClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.CreateOAuthClientResponse{OauthClient: data, ClientSecret: secret}, nil
+}
+
+func (g *grpcService) ListOAuthClients(ctx context.Context, req *userv1.ListOAuthClientsRequest) (*userv1.ListOAuthClientsResponse, error) {
+	clients, err := g.clientrepo.Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	datas, err := util.MapToSlice(mapGRPCOAuthClient, clients)
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.ListOAuthClientsResponse{Data: datas}, nil
+}
+
+// DeleteOAuthClient stops the client from getting new tokens, the tokens
+// already issued stay valid until they expire.
+func (g *grpcService) DeleteOAuthClient(ctx context.Context, req *userv1.DeleteOAuthClientRequest) (*userv1.DeleteOAuthClientResponse, error) {
+	if err := g.clientrepo.DeleteByID(ctx, req.Id); err != nil {
+		if errors.Is(err, oauthclient.ErrOAuthClientNotFound) {
+			return nil, status.Error(codes.NotFound, "OAuth client not found.")
+		}
+		return nil, err
+	}
+	return &userv1.DeleteOAuthClientResponse{}, nil
+}
+
+// VerifyClientCredentials is public, the HTTP gateway calls it for the
+// client_credentials grant and issues the access token itself.
+func (g *grpcService) VerifyClientCredentials(ctx context.Context, req *userv1.VerifyClientCredentialsRequest) (*userv1.VerifyClientCredentialsResponse, error) {
+	if req.ClientId == "" || req.ClientSecret == "" {
+		return nil, errInvalidClient
+	}
+
+	c, err := g.clientrepo.FindByClientID(ctx, req.ClientId)
+	if err != nil {
+		if errors.Is(err, oauthclient.ErrOAuthClientNotFound) {
+			return nil, errInvalidClient
+		}
+		return nil, err
+	}
+	if !c.VerifySecret(req.ClientSecret) {
+		return nil, errInvalidClient
+	}
+
+	scopes := c.Scopes
+	if len(req.Scopes) > 0 {
+		for _, scope := range req.Scopes {
+			if !slices.Contains(c.Scopes, scope) {
+				return nil, status.Errorf(codes.PermissionDenied, "Scope %s is not allowed for this client.", scope)
+			}
+		}
+		scopes = req.Scopes
+	}
+
+	return &userv1.VerifyClientCredentialsResponse{
+		ClientId: c.ClientID,
+		Scopes:   scopes,
+	}, nil
+}
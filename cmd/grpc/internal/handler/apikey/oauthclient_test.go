@@ -0,0 +1,253 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/repository/oauthclient"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockOAuthClientRepository struct {
+	mock.Mock
+	oauthclient.OAuthClientRepository
+}
+
+func (m *mockOAuthClientRepository) InsertOne(ctx context.Context, c *oauthclient.OAuthClient) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *mockOAuthClientRepository) FindByClientID(ctx context.Context, clientID string) (*oauthclient.OAuthClient, error) {
+	args := m.Called(ctx, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oauthclient.OAuthClient), args.Error(1)
+}
+
+func (m *mockOAuthClientRepository) Find(ctx context.Context) ([]*oauthclient.OAuthClient, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*oauthclient.OAuthClient), args.Error(1)
+}
+
+func (m *mockOAuthClientRepository) DeleteByID(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateOAuthClient(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	admin := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"admin"}})
+
+	t.Run("success", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("InsertOne", admin, mock.MatchedBy(func(c *oauthclient.OAuthClient) bool {
+			return c.Name == "reporting" && c.CreatedBy == uid && c.ClientID != "" && c.SecretHash != ""
+		})).Return(nil).Once()
+
+		res, err := sv.CreateOAuthClient(admin, &userv1.CreateOAuthClientRequest{Name: "reporting", Scopes: []string{"users:read"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "reporting", res.OauthClient.Name)
+		assert.NotEmpty(t, res.OauthClient.ClientId)
+		assert.Equal(t, []string{"users:read"}, res.OauthClient.Scopes)
+		assert.True(t, strings.HasPrefix(res.ClientSecret, oauthclient.SecretPrefix))
+	})
+
+	t.Run("name is required", func(t *testing.T) {
+		sv := &grpcService{clientrepo: new(mockOAuthClientRepository)}
+
+		res, err := sv.CreateOAuthClient(admin, &userv1.CreateOAuthClientRequest{Scopes: []string{"users:read"}})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Name is required."), err)
+	})
+
+	t.Run("scopes are required", func(t *testing.T) {
+		sv := &grpcService{clientrepo: new(mockOAuthClientRepository)}
+
+		res, err := sv.CreateOAuthClient(admin, &userv1.CreateOAuthClientRequest{Name: "reporting"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Scopes are required."), err)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		sv := &grpcService{clientrepo: new(mockOAuthClientRepository)}
+
+		res, err := sv.CreateOAuthClient(admin, &userv1.CreateOAuthClientRequest{Name: "reporting", Scopes: []string{"users:all"}})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Scope users:all is invalid."), err)
+	})
+
+	t.Run("scope not granted to the caller", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+		ctx := withClaims(&token.JwtToken{UserID: uid, Roles: []string{"support"}})
+
+		res, err := sv.CreateOAuthClient(ctx, &userv1.CreateOAuthClientRequest{Name: "reporting", Scopes: []string{"users:read", "users:delete"}})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.PermissionDenied, "Scope users:delete is not granted to the caller."), err)
+		clientrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{clientrepo: new(mockOAuthClientRepository)}
+
+		res, err := sv.CreateOAuthClient(context.Background(), &userv1.CreateOAuthClientRequest{Name: "reporting", Scopes: []string{"users:read"}})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestListOAuthClients(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("Find", ctx).Return([]*oauthclient.OAuthClient{{ID: primitive.NewObjectID(), ClientID: "client", Name: "reporting"}}, nil).Once()
+
+		res, err := sv.ListOAuthClients(ctx, &userv1.ListOAuthClientsRequest{})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Data, 1)
+		assert.Equal(t, "client", res.Data[0].ClientId)
+	})
+}
+
+func TestDeleteOAuthClient(t *testing.T) {
+	ctx := context.Background()
+	id := primitive.NewObjectID().Hex()
+
+	t.Run("success", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("DeleteByID", ctx, id).Return(nil).Once()
+
+		res, err := sv.DeleteOAuthClient(ctx, &userv1.DeleteOAuthClientRequest{Id: id})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+	})
+
+	t.Run("oauth client not found", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("DeleteByID", ctx, id).Return(oauthclient.ErrOAuthClientNotFound).Once()
+
+		res, err := sv.DeleteOAuthClient(ctx, &userv1.DeleteOAuthClientRequest{Id: id})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.NotFound, "OAuth client not found."), err)
+	})
+}
+
+func TestVerifyClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	c, secret, _ := oauthclient.NewOAuthClient("reporting", []string{"users:read", "users:write"}, "admin")
+
+	t.Run("all scopes", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("FindByClientID", ctx, c.ClientID).Return(c, nil).Once()
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: c.ClientID, ClientSecret: secret})
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.ClientID, res.ClientId)
+		assert.Equal(t, []string{"users:read", "users:write"}, res.Scopes)
+	})
+
+	t.Run("requested scopes", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("FindByClientID", ctx, c.ClientID).Return(c, nil).Once()
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: c.ClientID, ClientSecret: secret, Scopes: []string{"users:read"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"users:read"}, res.Scopes)
+	})
+
+	t.Run("scope not allowed", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("FindByClientID", ctx, c.ClientID).Return(c, nil).Once()
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: c.ClientID, ClientSecret: secret, Scopes: []string{"users:delete"}})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.PermissionDenied, "Scope users:delete is not allowed for this client."), err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("FindByClientID", ctx, c.ClientID).Return(c, nil).Once()
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: c.ClientID, ClientSecret: "other"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidClient, err)
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("FindByClientID", ctx, "other").Return(nil, oauthclient.ErrOAuthClientNotFound).Once()
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: "other", ClientSecret: secret})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidClient, err)
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: c.ClientID})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidClient, err)
+		clientrepo.AssertNotCalled(t, "FindByClientID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		clientrepo := new(mockOAuthClientRepository)
+		sv := &grpcService{clientrepo: clientrepo}
+
+		clientrepo.On("FindByClientID", ctx, c.ClientID).Return(nil, errors.New("error")).Once()
+
+		res, err := sv.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{ClientId: c.ClientID, ClientSecret: secret})
+
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "error")
+	})
+}
@@ -2,42 +2,815 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/userstore"
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/client/mailer"
+	"github.com/nuea/backend-golang-test/internal/client/oidc"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
 	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/loginattempt"
+	"github.com/nuea/backend-golang-test/internal/repository/mfachallenge"
+	"github.com/nuea/backend-golang-test/internal/repository/oidcstate"
+	"github.com/nuea/backend-golang-test/internal/repository/passwordreset"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/revokedtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/service/password"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/nuea/backend-golang-test/internal/totp"
 	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 type grpcService struct {
 	userv1.UnimplementedAuthServiceServer
-	userrepo user.UserRepository
+	cfg         *config.AuthConfig
+	userrepo    user.UserRepository
+	rtrepo      refreshtoken.RefreshTokenRepository
+	revokedrepo revokedtoken.RevokedTokenRepository
+	resetrepo   passwordreset.PasswordResetRepository
+	attemptrepo loginattempt.LoginAttemptRepository
+	mfarepo     mfachallenge.MFAChallengeRepository
+	oidcrepo    oidcstate.OIDCStateRepository
+	sessionrepo session.SessionRepository
+	auditrepo   auditlog.AuditLogRepository
+	tokensv     token.TokenService
+	policy      password.PasswordPolicy
+	hasher      password.Hasher
+	mailer      mailer.Mailer
+	oidc        oidc.Provider
+	oidcCfg     *config.OIDCConfig
+	// trustedProxies may forward the IP of their client.
+	trustedProxies []*net.IPNet
+	// dummyHash is verified when a login has no password hash to verify, so
+	// that it takes as long as a wrong password.
+	dummyHash string
 }
 
-func ProvideAuthGRPCService(repo *repository.Repository) (userv1.AuthServiceServer, error) {
+func ProvideAuthGRPCService(cfg *config.AppConfig, repo *repository.Repository, c *client.Clients, tokensv token.TokenService, policy password.PasswordPolicy, hasher password.Hasher) (userv1.AuthServiceServer, error) {
+	proxies, err := parseTrustedProxies(cfg.Auth.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := util.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	dummyHash, err := hasher.Hash(context.Background(), secret)
+	if err != nil {
+		return nil, err
+	}
+
 	return &grpcService{
-		userrepo: repo.UserRepository,
+		cfg:         &cfg.Auth,
+		userrepo:    repo.UserRepository,
+		rtrepo:      repo.RefreshTokenRepository,
+		revokedrepo: repo.RevokedTokenRepository,
+		resetrepo:   repo.PasswordResetRepository,
+		attemptrepo: repo.LoginAttemptRepository,
+		mfarepo:     repo.MFAChallengeRepository,
+		oidcrepo:    repo.OIDCStateRepository,
+		sessionrepo: repo.SessionRepository,
+		auditrepo:   repo.AuditLogRepository,
+		tokensv:     tokensv,
+		policy:      policy,
+		hasher:      hasher,
+		mailer:      c.Mailer,
+		oidc:        c.OIDC,
+		oidcCfg:     &cfg.OIDC,
+
+		trustedProxies: proxies,
+		dummyHash:      dummyHash,
 	}, nil
 }
 
+var errInvalidCredentials = status.Error(codes.InvalidArgument, "Email or password is invalid.")
+
 func (g *grpcService) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.LoginResponse, error) {
 	email, err := types.NewEmail(req.Email)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	user, err := g.userrepo.FindByEmail(ctx, email)
+	ip := g.clientIP(ctx)
+	if ip != "" {
+		if _, err := g.checkLockout(ctx, loginattempt.IPKey(ip)); err != nil {
+			return nil, err
+		}
+	}
+
+	// An unknown email fails like a wrong password: with the same error,
+	// after as long, and locked as often, so it does not tell which
+	// accounts exist. A deleted user is unknown.
+	u, err := g.userrepo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+	if u != nil && u.DeletedAt != nil {
+		u = nil
+	}
+	userKey, hash := loginattempt.EmailKey(string(email)), g.dummyHash
+	if u != nil {
+		userKey = loginattempt.UserKey(u.ID.Hex())
+		if u.Password != "" {
+			hash = u.Password
+		}
+	}
+
+	attempt, err := g.checkLockout(ctx, userKey)
 	if err != nil {
 		return nil, err
 	}
 
-	if !types.NewHashString(user.Password).Equal(req.Password) {
-		return nil, status.Error(codes.InvalidArgument, "Password is invalid.")
+	ok, rehash, err := g.hasher.Verify(ctx, req.Password, hash)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil || u.Password == "" || !ok {
+		return nil, g.failLogin(ctx, ip, userKey, errInvalidCredentials)
+	}
+
+	if rehash {
+		g.rehashPassword(ctx, u, req.Password)
+	}
+
+	if g.cfg.RequireVerifiedEmail && u.EmailVerifiedAt == nil {
+		return nil, status.Error(codes.FailedPrecondition, "Email is not verified.")
+	}
+
+	// The failed attempts are kept until the second factor is verified too,
+	// otherwise the password alone would reset the lockout of VerifyMFA.
+	if u.MFAEnabled() {
+		raw, err := g.newMFAChallenge(ctx, u.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+
+		return &userv1.LoginResponse{
+			UserId:      u.ID.Hex(),
+			MfaRequired: true,
+			MfaToken:    raw,
+		}, nil
+	}
+
+	if attempt != nil {
+		if err := g.attemptrepo.DeleteByKey(ctx, userKey); err != nil {
+			return nil, err
+		}
+	}
+
+	sid, rt, err := g.startSession(ctx, u.ID.Hex())
+	if err != nil {
+		return nil, err
 	}
 
 	return &userv1.LoginResponse{
-		UserId: user.ID.Hex(),
+		UserId:       u.ID.Hex(),
+		RefreshToken: rt,
+		Roles:        u.Roles,
+		SessionId:    sid,
+	}, nil
+}
+
+func (g *grpcService) RefreshToken(ctx context.Context, req *userv1.RefreshTokenRequest) (*userv1.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "Refresh token is required.")
+	}
+
+	current, err := g.rtrepo.FindByToken(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, refreshtoken.ErrRefreshTokenNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "Refresh token is invalid.")
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, status.Error(codes.Unauthenticated, "Refresh token is revoked.")
+	}
+
+	if current.UsedAt != nil {
+		return nil, g.revokeFamily(ctx, current.FamilyID)
+	}
+
+	if current.IsExpired() {
+		return nil, status.Error(codes.Unauthenticated, "Refresh token is expired.")
+	}
+
+	if err := g.rtrepo.MarkUsed(ctx, current.ID); err != nil {
+		if errors.Is(err, refreshtoken.ErrRefreshTokenUsed) {
+			return nil, g.revokeFamily(ctx, current.FamilyID)
+		}
+		return nil, err
+	}
+
+	user, err := g.userrepo.FindByID(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletedAt != nil {
+		return nil, g.revokeFamily(ctx, current.FamilyID)
+	}
+
+	if err := g.extendSession(ctx, current.FamilyID, current.UserID); err != nil {
+		return nil, err
+	}
+
+	rt, err := g.issueRefreshToken(ctx, current.FamilyID, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.RefreshTokenResponse{
+		UserId:       current.UserID,
+		RefreshToken: rt,
+		Roles:        user.Roles,
+		SessionId:    current.FamilyID,
+	}, nil
+}
+
+func (g *grpcService) Logout(ctx context.Context, req *userv1.LogoutRequest) (*userv1.LogoutResponse, error) {
+	claims, err := g.tokensv.VerifyAccessToken(req.AccessToken)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if claims.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "Access token cannot be revoked.")
+	}
+
+	// The record is kept for as long as the token is accepted.
+	expiresAt := claims.ExpiresAtTime().Add(g.cfg.TokenClockSkew)
+	if err := g.revokedrepo.InsertOne(ctx, revokedtoken.NewRevokedToken(claims.Id, claims.UserID, expiresAt)); err != nil {
+		return nil, err
+	}
+
+	if id, err := primitive.ObjectIDFromHex(claims.SessionID); err == nil {
+		if err := g.revokeSession(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.RefreshToken != "" {
+		rt, err := g.rtrepo.FindByToken(ctx, req.RefreshToken)
+		if err != nil && !errors.Is(err, refreshtoken.ErrRefreshTokenNotFound) {
+			return nil, err
+		}
+		if rt != nil && rt.UserID == claims.UserID {
+			if err := g.rtrepo.RevokeFamily(ctx, rt.FamilyID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &userv1.LogoutResponse{}, nil
+}
+
+func (g *grpcService) IsTokenRevoked(ctx context.Context, req *userv1.IsTokenRevokedRequest) (*userv1.IsTokenRevokedResponse, error) {
+	if req.TokenId == "" && req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "Token id is required.")
+	}
+
+	revoked, err := g.isTokenRevoked(ctx, req.TokenId, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.IsTokenRevokedResponse{
+		Revoked: revoked,
 	}, nil
 }
+
+func (g *grpcService) ChangePassword(ctx context.Context, req *userv1.ChangePasswordRequest) (*userv1.ChangePasswordResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "New password is required.")
+	}
+
+	user, err := g.userrepo.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, _, err := g.hasher.Verify(ctx, req.CurrentPassword, user.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Password is invalid.")
+	}
+
+	if err := g.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	if err := userstore.Replace(ctx, g.userrepo, uid, user); err != nil {
+		return nil, err
+	}
+
+	return &userv1.ChangePasswordResponse{}, nil
+}
+
+// ForgotPassword always succeeds so that the response does not reveal which
+// emails are registered.
+func (g *grpcService) ForgotPassword(ctx context.Context, req *userv1.ForgotPasswordRequest) (*userv1.ForgotPasswordResponse, error) {
+	email, err := types.NewEmail(req.Email)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	user, err := g.userrepo.FindByEmail(ctx, email)
+	if err != nil || user.DeletedAt != nil {
+		return &userv1.ForgotPasswordResponse{}, nil
+	}
+
+	raw, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.resetrepo.InsertOne(ctx, passwordreset.NewPasswordResetToken(user.ID.Hex(), raw, g.cfg.PasswordResetTTL)); err != nil {
+		return nil, err
+	}
+
+	link := g.cfg.PasswordResetURL + "?token=" + url.QueryEscape(raw)
+	if err := g.mailer.Send(ctx, &mailer.Message{
+		To:      string(user.Email),
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nUse the link below to reset your password. It expires in %s and can only be used once.\r\n\r\n%s\r\n\r\nIf you did not ask for a password reset, you can ignore this email.",
+			user.Name, g.cfg.PasswordResetTTL, link),
+	}); err != nil {
+		log.Println("Unable to send the password reset email:", err)
+	}
+
+	return &userv1.ForgotPasswordResponse{}, nil
+}
+
+func (g *grpcService) ResetPassword(ctx context.Context, req *userv1.ResetPasswordRequest) (*userv1.ResetPasswordResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "Reset token is required.")
+	}
+
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "New password is required.")
+	}
+
+	prt, err := g.resetrepo.FindByToken(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, passwordreset.ErrPasswordResetTokenNotFound) {
+			return nil, status.Error(codes.InvalidArgument, "Reset token is invalid or expired.")
+		}
+		return nil, err
+	}
+
+	if prt.UsedAt != nil || prt.IsExpired() {
+		return nil, status.Error(codes.InvalidArgument, "Reset token is invalid or expired.")
+	}
+
+	user, err := g.userrepo.FindByID(ctx, prt.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletedAt != nil {
+		return nil, status.Error(codes.InvalidArgument, "Reset token is invalid or expired.")
+	}
+
+	// The password is checked before the token is used, so that a rejected
+	// password does not cost the user their reset link.
+	if err := g.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	if err := g.resetrepo.MarkUsed(ctx, prt.ID); err != nil {
+		if errors.Is(err, passwordreset.ErrPasswordResetTokenUsed) {
+			return nil, status.Error(codes.InvalidArgument, "Reset token is invalid or expired.")
+		}
+		return nil, err
+	}
+
+	if err := userstore.Replace(ctx, g.userrepo, prt.UserID, user); err != nil {
+		return nil, err
+	}
+
+	// Whoever asked for the reset may not be the only one holding a session,
+	// so every session of the user ends.
+	if err := g.revokeUserSessions(ctx, prt.UserID); err != nil {
+		return nil, err
+	}
+
+	return &userv1.ResetPasswordResponse{}, nil
+}
+
+// VerifyMFA completes a login of a user with MFA enabled. Wrong codes count
+// as failed logins, so guessing is stopped by the same lockout.
+func (g *grpcService) VerifyMFA(ctx context.Context, req *userv1.VerifyMFARequest) (*userv1.VerifyMFAResponse, error) {
+	if req.MfaToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "MFA token and code are required.")
+	}
+
+	challenge, err := g.mfarepo.FindByToken(ctx, req.MfaToken)
+	if err != nil {
+		if errors.Is(err, mfachallenge.ErrMFAChallengeNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "MFA token is invalid or expired.")
+		}
+		return nil, err
+	}
+
+	if challenge.UsedAt != nil || challenge.IsExpired() {
+		return nil, status.Error(codes.Unauthenticated, "MFA token is invalid or expired.")
+	}
+
+	ip := g.clientIP(ctx)
+	if ip != "" {
+		if _, err := g.checkLockout(ctx, loginattempt.IPKey(ip)); err != nil {
+			return nil, err
+		}
+	}
+
+	userKey := loginattempt.UserKey(challenge.UserID)
+	attempt, err := g.checkLockout(ctx, userKey)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := g.userrepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.DeletedAt != nil || !u.MFAEnabled() {
+		return nil, status.Error(codes.Unauthenticated, "MFA token is invalid or expired.")
+	}
+
+	if !u.MFA.Verify(req.Code, time.Now()) {
+		return nil, g.failLogin(ctx, ip, userKey, status.Error(codes.InvalidArgument, "Code is invalid."))
+	}
+
+	if err := g.mfarepo.MarkUsed(ctx, challenge.ID); err != nil {
+		if errors.Is(err, mfachallenge.ErrMFAChallengeUsed) {
+			return nil, status.Error(codes.Unauthenticated, "MFA token is invalid or expired.")
+		}
+		return nil, err
+	}
+
+	// Persist the consumed code so it cannot be replayed.
+	u.UpdatedAt = time.Now().UTC()
+	if err := userstore.Replace(ctx, g.userrepo, challenge.UserID, u); err != nil {
+		return nil, err
+	}
+
+	if attempt != nil {
+		if err := g.attemptrepo.DeleteByKey(ctx, userKey); err != nil {
+			return nil, err
+		}
+	}
+
+	sid, rt, err := g.startSession(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.VerifyMFAResponse{
+		UserId:       challenge.UserID,
+		RefreshToken: rt,
+		Roles:        u.Roles,
+		SessionId:    sid,
+	}, nil
+}
+
+// EnrollMFA starts a TOTP enrollment. MFA is enabled only once ConfirmMFA
+// receives a first code, so a lost enrollment never locks the user out.
+func (g *grpcService) EnrollMFA(ctx context.Context, req *userv1.EnrollMFARequest) (*userv1.EnrollMFAResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	u, err := g.userrepo.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.MFAEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "MFA is already enabled.")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	u.MFA = &user.MFA{Secret: secret}
+	u.UpdatedAt = time.Now().UTC()
+
+	if err := userstore.Replace(ctx, g.userrepo, uid, u); err != nil {
+		return nil, err
+	}
+
+	return &userv1.EnrollMFAResponse{
+		Secret:     secret,
+		OtpauthUri: totp.URI(g.cfg.MFAIssuer, string(u.Email), secret),
+	}, nil
+}
+
+// ConfirmMFA enables MFA and returns the recovery codes. Only their hashes
+// are stored, so this is the only time the codes are shown.
+func (g *grpcService) ConfirmMFA(ctx context.Context, req *userv1.ConfirmMFARequest) (*userv1.ConfirmMFAResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	u, err := g.userrepo.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.MFAEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "MFA is already enabled.")
+	}
+
+	if u.MFA == nil {
+		return nil, status.Error(codes.FailedPrecondition, "MFA enrollment is not started.")
+	}
+
+	now := time.Now().UTC()
+	step, ok := totp.Validate(u.MFA.Secret, req.Code, now)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Code is invalid.")
+	}
+
+	recoveryCodes, hashes, err := user.NewRecoveryCodes(g.cfg.MFARecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	u.MFA.EnabledAt = &now
+	u.MFA.LastUsedStep = step
+	u.MFA.RecoveryCodes = hashes
+	u.UpdatedAt = now
+
+	if err := userstore.Replace(ctx, g.userrepo, uid, u); err != nil {
+		return nil, err
+	}
+
+	return &userv1.ConfirmMFAResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (g *grpcService) DisableMFA(ctx context.Context, req *userv1.DisableMFARequest) (*userv1.DisableMFAResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	u, err := g.userrepo.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.MFAEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "MFA is not enabled.")
+	}
+
+	ok, _, err := g.hasher.Verify(ctx, req.Password, u.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "Password is invalid.")
+	}
+
+	if !u.MFA.Verify(req.Code, time.Now()) {
+		return nil, status.Error(codes.InvalidArgument, "Code is invalid.")
+	}
+
+	u.MFA = nil
+	u.UpdatedAt = time.Now().UTC()
+
+	if err := userstore.Replace(ctx, g.userrepo, uid, u); err != nil {
+		return nil, err
+	}
+
+	return &userv1.DisableMFAResponse{}, nil
+}
+
+// setPassword checks newPassword against the policy and the recent
+// passwords of u, then replaces the password hash.
+func (g *grpcService) setPassword(ctx context.Context, u *user.User, newPassword string) error {
+	if err := g.policy.Validate(ctx, newPassword, append([]string{u.Password}, u.PasswordHistory...)...); err != nil {
+		return err
+	}
+
+	hash, err := g.hasher.Hash(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHistory = g.policy.Remember(u.Password, u.PasswordHistory)
+	u.Password = hash
+	u.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// rehashPassword replaces a hash made with outdated parameters after a
+// successful login. A failure does not fail the login, the hash is replaced
+// on a later one.
+func (g *grpcService) rehashPassword(ctx context.Context, u *user.User, pwd string) {
+	hash, err := g.hasher.Hash(ctx, pwd)
+	if err == nil {
+		u.Password = hash
+		err = g.userrepo.ReplaceOne(ctx, u.ID.Hex(), u)
+	}
+	if err != nil {
+		log.Println("Unable to rehash the password:", err)
+	}
+}
+
+// newMFAChallenge returns the token to exchange with a second factor for
+// the token pair.
+func (g *grpcService) newMFAChallenge(ctx context.Context, userID string) (string, error) {
+	raw, err := util.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := g.mfarepo.InsertOne(ctx, mfachallenge.NewMFAChallenge(userID, raw, g.cfg.MFAChallengeTTL)); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (g *grpcService) issueRefreshToken(ctx context.Context, familyID, userID string) (string, error) {
+	raw, err := util.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := g.rtrepo.InsertOne(ctx, refreshtoken.NewRefreshToken(familyID, userID, raw, g.cfg.RefreshTokenExpireTTL)); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// revokeFamily is called when a refresh token is presented after it has
+// already been rotated. The token was most likely stolen, so every token
+// issued from the same login is revoked.
+func (g *grpcService) revokeFamily(ctx context.Context, familyID string) error {
+	if err := g.rtrepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return status.Error(codes.Unauthenticated, "Refresh token is revoked.")
+}
+
+// checkLockout fails with ResourceExhausted while key is locked. It returns
+// the failed attempts recorded for key, or nil when there are none.
+func (g *grpcService) checkLockout(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	attempt, err := g.attemptrepo.FindByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, loginattempt.ErrLoginAttemptNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The TTL index removes expired counters only periodically.
+	if attempt.IsExpired() {
+		return nil, g.attemptrepo.DeleteByKey(ctx, key)
+	}
+
+	if d := attempt.RetryAfter(); d > 0 {
+		secs := int64(math.Ceil(d.Seconds()))
+		return nil, util.ErrorWithRetryAfter(codes.ResourceExhausted,
+			fmt.Sprintf("Too many failed login attempts, try again in %d seconds.", secs), time.Duration(secs)*time.Second)
+	}
+	return attempt, nil
+}
+
+// failLogin counts a failed login against the client IP and the account, or
+// the email without one, then returns cause.
+func (g *grpcService) failLogin(ctx context.Context, ip, userKey string, cause error) error {
+	if ip != "" {
+		if err := g.recordFailedLogin(ctx, loginattempt.IPKey(ip), g.cfg.LoginLockoutIPThreshold); err != nil {
+			return err
+		}
+	}
+	if userKey != "" {
+		if err := g.recordFailedLogin(ctx, userKey, g.cfg.LoginLockoutThreshold); err != nil {
+			return err
+		}
+	}
+	return cause
+}
+
+// recordFailedLogin locks key once its failures reach threshold. Every
+// further failure doubles the lockout, up to LoginLockoutMaxDuration. A
+// threshold of zero disables the lockout.
+func (g *grpcService) recordFailedLogin(ctx context.Context, key string, threshold int64) error {
+	if threshold <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	attempt, err := g.attemptrepo.RecordFailure(ctx, key, now.Add(g.cfg.LoginAttemptWindow))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < threshold {
+		return nil
+	}
+
+	d := g.cfg.LoginLockoutDuration
+	for i := threshold; i < attempt.Failures && d < g.cfg.LoginLockoutMaxDuration; i++ {
+		d *= 2
+	}
+	lockedUntil := now.Add(min(d, g.cfg.LoginLockoutMaxDuration))
+
+	return g.attemptrepo.Lock(ctx, key, lockedUntil, lockedUntil.Add(g.cfg.LoginAttemptWindow))
+}
+
+// clientIP returns the client IP forwarded by the HTTP server, or the address
+// of the peer for direct gRPC calls. x-forwarded-for is only believed from a
+// trusted proxy, anyone else could set it to dodge the IP lockout or to lock
+// out another IP.
+func (g *grpcService) clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+
+	if g.isTrustedProxy(net.ParseIP(host)) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get("x-forwarded-for"); len(v) > 0 {
+				return v[0]
+			}
+		}
+	}
+	return host
+}
+
+func (g *grpcService) isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range g.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies accepts CIDRs and single addresses.
+func parseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if ip := net.ParseIP(v); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, n)
+	}
+	return proxies, nil
+}
@@ -3,16 +3,39 @@ package auth
 import (
 	"context"
 	"errors"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/userstore"
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/client/mailer"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
 	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/loginattempt"
+	"github.com/nuea/backend-golang-test/internal/repository/mfachallenge"
+	"github.com/nuea/backend-golang-test/internal/repository/passwordreset"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/revokedtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/service/password"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/nuea/backend-golang-test/internal/totp"
 	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -29,10 +52,286 @@ func (m *mockUserRepository) FindByEmail(ctx context.Context, email types.Email)
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *mockUserRepository) FindByID(ctx context.Context, id string) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserRepository) ReplaceOne(ctx context.Context, id string, u *user.User) error {
+	args := m.Called(ctx, id, u)
+	return args.Error(0)
+}
+
+type mockRefreshTokenRepository struct {
+	mock.Mock
+	refreshtoken.RefreshTokenRepository
+}
+
+func (m *mockRefreshTokenRepository) InsertOne(ctx context.Context, token *refreshtoken.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *mockRefreshTokenRepository) FindByToken(ctx context.Context, token string) (*refreshtoken.RefreshToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*refreshtoken.RefreshToken), args.Error(1)
+}
+
+func (m *mockRefreshTokenRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (m *mockRefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type mockLoginAttemptRepository struct {
+	mock.Mock
+	loginattempt.LoginAttemptRepository
+}
+
+func (m *mockLoginAttemptRepository) FindByKey(ctx context.Context, key string) (*loginattempt.LoginAttempt, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loginattempt.LoginAttempt), args.Error(1)
+}
+
+func (m *mockLoginAttemptRepository) RecordFailure(ctx context.Context, key string, expiresAt time.Time) (*loginattempt.LoginAttempt, error) {
+	args := m.Called(ctx, key, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*loginattempt.LoginAttempt), args.Error(1)
+}
+
+func (m *mockLoginAttemptRepository) Lock(ctx context.Context, key string, lockedUntil, expiresAt time.Time) error {
+	args := m.Called(ctx, key, lockedUntil, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mockLoginAttemptRepository) DeleteByKey(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+type mockMFAChallengeRepository struct {
+	mock.Mock
+	mfachallenge.MFAChallengeRepository
+}
+
+func (m *mockMFAChallengeRepository) InsertOne(ctx context.Context, challenge *mfachallenge.MFAChallenge) error {
+	args := m.Called(ctx, challenge)
+	return args.Error(0)
+}
+
+func (m *mockMFAChallengeRepository) FindByToken(ctx context.Context, token string) (*mfachallenge.MFAChallenge, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mfachallenge.MFAChallenge), args.Error(1)
+}
+
+func (m *mockMFAChallengeRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockHasher struct {
+	mock.Mock
+	password.Hasher
+}
+
+func (m *mockHasher) Verify(ctx context.Context, pwd, encoded string) (bool, bool, error) {
+	args := m.Called(ctx, pwd, encoded)
+	return args.Bool(0), args.Bool(1), args.Error(2)
+}
+
+type mockPasswordPolicy struct {
+	mock.Mock
+	password.PasswordPolicy
+}
+
+func (m *mockPasswordPolicy) Validate(ctx context.Context, pwd string, recentHashes ...string) error {
+	args := m.Called(ctx, pwd, recentHashes)
+	return args.Error(0)
+}
+
+func (m *mockPasswordPolicy) Remember(current string, history []string) []string {
+	args := m.Called(current, history)
+	h, _ := args.Get(0).([]string)
+	return h
+}
+
+type mockPasswordResetRepository struct {
+	mock.Mock
+	passwordreset.PasswordResetRepository
+}
+
+func (m *mockPasswordResetRepository) InsertOne(ctx context.Context, token *passwordreset.PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *mockPasswordResetRepository) FindByToken(ctx context.Context, token string) (*passwordreset.PasswordResetToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*passwordreset.PasswordResetToken), args.Error(1)
+}
+
+func (m *mockPasswordResetRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+type mockRevokedTokenRepository struct {
+	mock.Mock
+	revokedtoken.RevokedTokenRepository
+}
+
+func (m *mockRevokedTokenRepository) InsertOne(ctx context.Context, token *revokedtoken.RevokedToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *mockRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	args := m.Called(ctx, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockSessionRepository struct {
+	mock.Mock
+	session.SessionRepository
+}
+
+func (m *mockSessionRepository) InsertOne(ctx context.Context, s *session.Session) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *mockSessionRepository) FindByID(ctx context.Context, id string) (*session.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *mockSessionRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*session.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*session.Session), args.Error(1)
+}
+
+func (m *mockSessionRepository) Extend(ctx context.Context, id primitive.ObjectID, ip string, expiresAt time.Time) error {
+	args := m.Called(ctx, id, ip, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mockSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockSessionRepository) RevokeByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type mockAuditLogRepository struct {
+	mock.Mock
+	auditlog.AuditLogRepository
+}
+
+func (m *mockAuditLogRepository) InsertOne(ctx context.Context, l *auditlog.AuditLog) error {
+	args := m.Called(ctx, l)
+	return args.Error(0)
+}
+
+type mockTokenService struct {
+	mock.Mock
+	token.TokenService
+}
+
+func (m *mockTokenService) GenerateImpersonationToken(userID, actorID string, roles []string) (string, error) {
+	args := m.Called(userID, actorID, roles)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockTokenService) VerifyAccessToken(accessToken string) (*token.JwtToken, error) {
+	args := m.Called(accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*token.JwtToken), args.Error(1)
+}
+
+var testhasher, _, _ = password.ProvideHasher(&config.AppConfig{PasswordHash: config.PasswordHashConfig{
+	Algorithm:  password.AlgorithmBcrypt,
+	BcryptCost: bcrypt.MinCost,
+}})
+
+func verifyPassword(pwd, hash string) bool {
+	ok, _, _ := testhasher.Verify(context.Background(), pwd, hash)
+	return ok
+}
+
+var testproxies, _ = parseTrustedProxies([]string{"127.0.0.1"})
+
+// proxyContext is an incoming call from the HTTP server on a trusted proxy.
+func proxyContext(ctx context.Context, kv ...string) context.Context {
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50000}})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+}
+
+var testcfg = &config.AuthConfig{
+	TokenClockSkew:          30 * time.Second,
+	RefreshTokenExpireTTL:   time.Hour,
+	PasswordResetTTL:        time.Hour,
+	PasswordResetURL:        "http://localhost:8080/reset-password",
+	LoginLockoutThreshold:   3,
+	LoginLockoutIPThreshold: 10,
+	LoginLockoutDuration:    time.Minute,
+	LoginLockoutMaxDuration: 10 * time.Minute,
+	LoginAttemptWindow:      15 * time.Minute,
+	MFAIssuer:               "backend-golang-test",
+	MFAChallengeTTL:         5 * time.Minute,
+	MFARecoveryCodeCount:    4,
+}
+
 func TestProvideAuthGRPCService(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv, err := ProvideAuthGRPCService(&repository.Repository{UserRepository: repo})
+		rtrepo := new(mockRefreshTokenRepository)
+		sv, err := ProvideAuthGRPCService(&config.AppConfig{}, &repository.Repository{UserRepository: repo, RefreshTokenRepository: rtrepo}, &client.Clients{Mailer: new(mockMailer)}, new(mockTokenService), new(mockPasswordPolicy), testhasher)
 
 		assert.NotNil(t, sv)
 		assert.NoError(t, err)
@@ -43,7 +342,7 @@ func TestLogin(t *testing.T) {
 	ctx := context.Background()
 	email := "test@example.com"
 	pwd := "password"
-	hash, _ := types.NewHashString(pwd).Hash()
+	hash, _ := testhasher.Hash(context.Background(), pwd)
 	uid := primitive.NewObjectID()
 
 	muser := &user.User{
@@ -51,25 +350,175 @@ func TestLogin(t *testing.T) {
 		Email:    types.Email(pwd),
 		Password: hash,
 	}
+	userKey := loginattempt.UserKey(uid.Hex())
 
 	t.Run("success", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, hasher: testhasher}
 		req := &userv1.LoginRequest{Email: email, Password: pwd}
 
 		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.MatchedBy(func(rt *refreshtoken.RefreshToken) bool {
+			return rt.UserID == uid.Hex() && rt.FamilyID != ""
+		})).Return(nil).Once()
 
 		res, err := sv.Login(ctx, req)
 
 		assert.NotNil(t, res)
 		assert.NoError(t, err)
 		assert.Equal(t, uid.Hex(), res.UserId)
+		assert.NotEmpty(t, res.RefreshToken)
+		assert.NotEmpty(t, res.SessionId)
+		repo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("success - records the device", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, hasher: testhasher, trustedProxies: testproxies}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		mdctx := proxyContext(ctx,
+			"x-forwarded-for", "10.0.0.1",
+			"x-device-id", "device-1",
+			"x-forwarded-user-agent", "Mozilla/5.0",
+			"user-agent", "grpc-go/1.0",
+		)
+		var sid string
+
+		attemptrepo.On("FindByKey", mdctx, mock.Anything).Return(nil, loginattempt.ErrLoginAttemptNotFound).Twice()
+		repo.On("FindByEmail", mdctx, types.Email(email)).Return(muser, nil).Once()
+		sessionrepo.On("InsertOne", mdctx, mock.MatchedBy(func(s *session.Session) bool {
+			sid = s.ID.Hex()
+			return s.UserID == uid.Hex() && s.DeviceID == "device-1" && s.UserAgent == "Mozilla/5.0" && s.IP == "10.0.0.1"
+		})).Return(nil).Once()
+		rtrepo.On("InsertOne", mdctx, mock.MatchedBy(func(rt *refreshtoken.RefreshToken) bool {
+			return rt.FamilyID == sid
+		})).Return(nil).Once()
+
+		res, err := sv.Login(mdctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, sid, res.SessionId)
+		sessionrepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("success - rehash outdated hash", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		legacy, _ := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.MinCost)
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(&user.User{ID: uid, Password: string(legacy)}, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return strings.HasPrefix(u.Password, "$bcrypt$") && verifyPassword(pwd, u.Password)
+		})).Return(nil).Once()
+		sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success - rehash fails", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		legacy, _ := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.MinCost)
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(&user.User{ID: uid, Password: string(legacy)}, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.Anything).Return(errors.New("error")).Once()
+		sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("hasher busy", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		hasher := new(mockHasher)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: hasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		hasher.On("Verify", ctx, pwd, hash).Return(false, false, password.ErrHasherBusy).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		attemptrepo.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("email not verified", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		cfg := *testcfg
+		cfg.RequireVerifiedEmail = true
+		sv := &grpcService{cfg: &cfg, userrepo: repo, rtrepo: rtrepo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.FailedPrecondition, "Email is not verified."), err)
+		rtrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refresh token error", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		msgerr := errors.New("internal server error")
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.Anything).Return(msgerr).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, msgerr)
 		repo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
 	})
 
 	t.Run("invalid email", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		sv := &grpcService{userrepo: repo, hasher: testhasher}
 		req := &userv1.LoginRequest{Email: "invalid email", Password: pwd}
 		msgerr := "mail: no angle-addr"
 		res, err := sv.Login(ctx, req)
@@ -84,7 +533,7 @@ func TestLogin(t *testing.T) {
 
 	t.Run("user not found", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		sv := &grpcService{userrepo: repo, hasher: testhasher}
 		req := &userv1.LoginRequest{Email: email, Password: pwd}
 		msgerr := errors.New("user not found")
 
@@ -97,12 +546,93 @@ func TestLogin(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("user not found - counted against the client ip", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher, trustedProxies: testproxies}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		ipctx := proxyContext(ctx, "x-forwarded-for", "10.0.0.1")
+		ipKey := loginattempt.IPKey("10.0.0.1")
+
+		emailKey := loginattempt.EmailKey(email)
+
+		attemptrepo.On("FindByKey", ipctx, ipKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		repo.On("FindByEmail", ipctx, types.Email(email)).Return(nil, user.ErrUserNotFound).Once()
+		attemptrepo.On("FindByKey", ipctx, emailKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		attemptrepo.On("RecordFailure", ipctx, ipKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: ipKey, Failures: 1}, nil).Once()
+		attemptrepo.On("RecordFailure", ipctx, emailKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: emailKey, Failures: 1}, nil).Once()
+
+		res, err := sv.Login(ipctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidCredentials, err, "the same error as a wrong password")
+		repo.AssertExpectations(t)
+		attemptrepo.AssertExpectations(t)
+	})
+
+	t.Run("user not found - locked like an account", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		emailKey := loginattempt.EmailKey(email)
+		locked := &loginattempt.LoginAttempt{Key: emailKey, Failures: 5, LockedUntil: ptr.Time(time.Now().Add(time.Minute)), ExpiresAt: time.Now().Add(time.Hour)}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(nil, user.ErrUserNotFound).Once()
+		attemptrepo.On("FindByKey", ctx, emailKey).Return(locked, nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		attemptrepo.AssertExpectations(t)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		deleted := *muser
+		deleted.DeletedAt = ptr.Time(time.Now())
+		emailKey := loginattempt.EmailKey(email)
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(&deleted, nil).Once()
+		attemptrepo.On("FindByKey", ctx, emailKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		attemptrepo.On("RecordFailure", ctx, emailKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: emailKey, Failures: 1}, nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidCredentials, err)
+		attemptrepo.AssertExpectations(t)
+	})
+
+	t.Run("account without password", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: ""}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(&user.User{ID: muser.ID}, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		attemptrepo.On("RecordFailure", ctx, userKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 1}, nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidCredentials, err)
+	})
+
 	t.Run("invalid password", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher}
 		req := &userv1.LoginRequest{Email: email, Password: "wrong-password"}
 
 		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		attemptrepo.On("RecordFailure", ctx, userKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 1}, nil).Once()
 
 		res, err := sv.Login(ctx, req)
 
@@ -111,7 +641,1078 @@ func TestLogin(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.InvalidArgument, st.Code())
-		assert.Equal(t, "Password is invalid.", st.Message())
+		assert.Equal(t, "Email or password is invalid.", st.Message())
 		repo.AssertExpectations(t)
+		attemptrepo.AssertExpectations(t)
+		attemptrepo.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid password - locks the account with exponential backoff", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: "wrong-password"}
+		start := time.Now()
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 4, ExpiresAt: start.Add(time.Minute)}, nil).Once()
+		attemptrepo.On("RecordFailure", ctx, userKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 5}, nil).Once()
+		attemptrepo.On("Lock", ctx, userKey, mock.MatchedBy(func(until time.Time) bool {
+			// Two failures past the threshold: 1m doubled twice.
+			return until.Sub(start) >= 4*time.Minute && until.Sub(start) < 5*time.Minute
+		}), mock.Anything).Return(nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		attemptrepo.AssertExpectations(t)
 	})
+
+	t.Run("account locked", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		lockedUntil := time.Now().Add(90 * time.Second)
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(&loginattempt.LoginAttempt{
+			Key:         userKey,
+			Failures:    3,
+			LockedUntil: &lockedUntil,
+			ExpiresAt:   lockedUntil.Add(time.Hour),
+		}, nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		retryAfter, ok := util.RetryAfterFromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, 90*time.Second, retryAfter)
+		attemptrepo.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("client ip locked", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, hasher: testhasher, trustedProxies: testproxies}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		ipctx := proxyContext(ctx, "x-forwarded-for", "10.0.0.1")
+		lockedUntil := time.Now().Add(time.Minute)
+
+		attemptrepo.On("FindByKey", ipctx, loginattempt.IPKey("10.0.0.1")).Return(&loginattempt.LoginAttempt{
+			LockedUntil: &lockedUntil,
+			ExpiresAt:   lockedUntil.Add(time.Hour),
+		}, nil).Once()
+
+		res, err := sv.Login(ipctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		repo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("mfa required", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		mfarepo := new(mockMFAChallengeRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, attemptrepo: attemptrepo, mfarepo: mfarepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+		mfauser := *muser
+		mfauser.MFA = &user.MFA{Secret: "secret", EnabledAt: ptr.Time(time.Now())}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(&mfauser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+		mfarepo.On("InsertOne", ctx, mock.MatchedBy(func(c *mfachallenge.MFAChallenge) bool {
+			return c.UserID == uid.Hex()
+		})).Return(nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.NoError(t, err)
+		assert.True(t, res.MfaRequired)
+		assert.NotEmpty(t, res.MfaToken)
+		assert.Empty(t, res.RefreshToken)
+		mfarepo.AssertExpectations(t)
+		rtrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+		attemptrepo.AssertNotCalled(t, "DeleteByKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("success - clears failed attempts", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, hasher: testhasher}
+		req := &userv1.LoginRequest{Email: email, Password: pwd}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 2, ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+		attemptrepo.On("DeleteByKey", ctx, userKey).Return(nil).Once()
+		sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.Login(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		attemptrepo.AssertExpectations(t)
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+	token := "refresh-token"
+	sid := primitive.NewObjectID()
+	newToken := func() *refreshtoken.RefreshToken {
+		rt := refreshtoken.NewRefreshToken(sid.Hex(), uid.Hex(), token, time.Hour)
+		rt.ID = primitive.NewObjectID()
+		return rt
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo}
+		current := newToken()
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+		rtrepo.On("MarkUsed", ctx, current.ID).Return(nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(&session.Session{ID: sid, UserID: uid.Hex()}, nil).Once()
+		sessionrepo.On("Extend", ctx, sid, "", mock.MatchedBy(func(expiresAt time.Time) bool {
+			return expiresAt.After(time.Now().Add(testcfg.RefreshTokenExpireTTL - time.Minute))
+		})).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.MatchedBy(func(rt *refreshtoken.RefreshToken) bool {
+			return rt.FamilyID == current.FamilyID && rt.UserID == uid.Hex()
+		})).Return(nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uid.Hex(), res.UserId)
+		assert.Equal(t, sid.Hex(), res.SessionId)
+		assert.NotEmpty(t, res.RefreshToken)
+		assert.NotEqual(t, token, res.RefreshToken)
+		repo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+		sessionrepo.AssertExpectations(t)
+	})
+
+	t.Run("success - login from before sessions", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo}
+		current := newToken()
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+		rtrepo.On("MarkUsed", ctx, current.ID).Return(nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(nil, session.ErrSessionNotFound).Once()
+		sessionrepo.On("InsertOne", ctx, mock.MatchedBy(func(s *session.Session) bool {
+			return s.ID == sid && s.UserID == uid.Hex()
+		})).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.NoError(t, err)
+		assert.Equal(t, sid.Hex(), res.SessionId)
+		sessionrepo.AssertExpectations(t)
+	})
+
+	t.Run("revoked session revokes family", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo}
+		current := newToken()
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+		rtrepo.On("MarkUsed", ctx, current.ID).Return(nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(&session.Session{ID: sid, RevokedAt: ptr.Time(time.Now())}, nil).Once()
+		rtrepo.On("RevokeFamily", ctx, sid.Hex()).Return(nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		rtrepo.AssertExpectations(t)
+		rtrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing refresh token", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg}
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("unknown refresh token", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo}
+
+		rtrepo.On("FindByToken", ctx, token).Return(nil, refreshtoken.ErrRefreshTokenNotFound).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("revoked refresh token", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo}
+		current := newToken()
+		current.RevokedAt = ptr.Time(time.Now().UTC())
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("reused refresh token revokes family", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo}
+		current := newToken()
+		current.UsedAt = ptr.Time(time.Now().UTC())
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+		rtrepo.On("RevokeFamily", ctx, current.FamilyID).Return(nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("concurrent reuse revokes family", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo}
+		current := newToken()
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+		rtrepo.On("MarkUsed", ctx, current.ID).Return(refreshtoken.ErrRefreshTokenUsed).Once()
+		rtrepo.On("RevokeFamily", ctx, current.FamilyID).Return(nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("expired refresh token", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo}
+		current := newToken()
+		current.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo}
+		current := newToken()
+
+		rtrepo.On("FindByToken", ctx, token).Return(current, nil).Once()
+		rtrepo.On("MarkUsed", ctx, current.ID).Return(nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, DeletedAt: ptr.Time(time.Now().UTC())}, nil).Once()
+		rtrepo.On("RevokeFamily", ctx, current.FamilyID).Return(nil).Once()
+
+		res, err := sv.RefreshToken(ctx, &userv1.RefreshTokenRequest{RefreshToken: token})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		repo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID().Hex()
+	ac := "access-token"
+	claims := &token.JwtToken{UserID: uid}
+	claims.Id = "jti"
+	claims.ExpiresAt = time.Now().Add(time.Minute).Unix()
+
+	t.Run("success", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		revokedrepo := new(mockRevokedTokenRepository)
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo, revokedrepo: revokedrepo, tokensv: tokensv}
+		rt := refreshtoken.NewRefreshToken("family", uid, "refresh-token", time.Hour)
+
+		tokensv.On("VerifyAccessToken", ac).Return(claims, nil).Once()
+		revokedrepo.On("InsertOne", ctx, mock.MatchedBy(func(r *revokedtoken.RevokedToken) bool {
+			return r.TokenID == claims.Id && r.UserID == uid && r.ExpiresAt.Equal(claims.ExpiresAtTime().Add(30*time.Second))
+		})).Return(nil).Once()
+		rtrepo.On("FindByToken", ctx, "refresh-token").Return(rt, nil).Once()
+		rtrepo.On("RevokeFamily", ctx, rt.FamilyID).Return(nil).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac, RefreshToken: "refresh-token"})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		tokensv.AssertExpectations(t)
+		revokedrepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("success without refresh token", func(t *testing.T) {
+		revokedrepo := new(mockRevokedTokenRepository)
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, revokedrepo: revokedrepo, tokensv: tokensv}
+
+		tokensv.On("VerifyAccessToken", ac).Return(claims, nil).Once()
+		revokedrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		tokensv.AssertExpectations(t)
+		revokedrepo.AssertExpectations(t)
+	})
+
+	t.Run("revokes the session of the access token", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		revokedrepo := new(mockRevokedTokenRepository)
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo, sessionrepo: sessionrepo, revokedrepo: revokedrepo, tokensv: tokensv}
+		sid := primitive.NewObjectID()
+		sclaims := *claims
+		sclaims.SessionID = sid.Hex()
+
+		tokensv.On("VerifyAccessToken", ac).Return(&sclaims, nil).Once()
+		revokedrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		sessionrepo.On("Revoke", ctx, sid).Return(nil).Once()
+		rtrepo.On("RevokeFamily", ctx, sid.Hex()).Return(nil).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		sessionrepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("refresh token of another user is ignored", func(t *testing.T) {
+		rtrepo := new(mockRefreshTokenRepository)
+		revokedrepo := new(mockRevokedTokenRepository)
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, rtrepo: rtrepo, revokedrepo: revokedrepo, tokensv: tokensv}
+		rt := refreshtoken.NewRefreshToken("family", primitive.NewObjectID().Hex(), "refresh-token", time.Hour)
+
+		tokensv.On("VerifyAccessToken", ac).Return(claims, nil).Once()
+		revokedrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("FindByToken", ctx, "refresh-token").Return(rt, nil).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac, RefreshToken: "refresh-token"})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		rtrepo.AssertNotCalled(t, "RevokeFamily", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid access token", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, tokensv: tokensv}
+
+		tokensv.On("VerifyAccessToken", ac).Return(nil, errors.New("signature is invalid")).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		tokensv.AssertExpectations(t)
+	})
+
+	t.Run("access token without id", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, tokensv: tokensv}
+
+		tokensv.On("VerifyAccessToken", ac).Return(&token.JwtToken{UserID: uid}, nil).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		tokensv.AssertExpectations(t)
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		revokedrepo := new(mockRevokedTokenRepository)
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: testcfg, revokedrepo: revokedrepo, tokensv: tokensv}
+		msgerr := errors.New("internal server error")
+
+		tokensv.On("VerifyAccessToken", ac).Return(claims, nil).Once()
+		revokedrepo.On("InsertOne", ctx, mock.Anything).Return(msgerr).Once()
+
+		res, err := sv.Logout(ctx, &userv1.LogoutRequest{AccessToken: ac})
+
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, msgerr)
+		revokedrepo.AssertExpectations(t)
+	})
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		revokedrepo := new(mockRevokedTokenRepository)
+		sv := &grpcService{revokedrepo: revokedrepo}
+
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(true, nil).Once()
+
+		res, err := sv.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{TokenId: "jti"})
+
+		assert.NoError(t, err)
+		assert.True(t, res.Revoked)
+		revokedrepo.AssertExpectations(t)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		revokedrepo := new(mockRevokedTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{revokedrepo: revokedrepo, sessionrepo: sessionrepo}
+		sid := primitive.NewObjectID()
+
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(&session.Session{ID: sid, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: ptr.Time(time.Now())}, nil).Once()
+
+		res, err := sv.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{TokenId: "jti", SessionId: sid.Hex()})
+
+		assert.NoError(t, err)
+		assert.True(t, res.Revoked)
+		sessionrepo.AssertExpectations(t)
+	})
+
+	t.Run("active session", func(t *testing.T) {
+		revokedrepo := new(mockRevokedTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{revokedrepo: revokedrepo, sessionrepo: sessionrepo}
+		sid := primitive.NewObjectID()
+
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(&session.Session{ID: sid, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+
+		res, err := sv.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{TokenId: "jti", SessionId: sid.Hex()})
+
+		assert.NoError(t, err)
+		assert.False(t, res.Revoked)
+	})
+
+	t.Run("missing session", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{sessionrepo: sessionrepo}
+
+		sessionrepo.On("FindByID", ctx, "sid").Return(nil, session.ErrSessionNotFound).Once()
+
+		res, err := sv.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{SessionId: "sid"})
+
+		assert.NoError(t, err)
+		assert.True(t, res.Revoked)
+	})
+
+	t.Run("missing token id", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		revokedrepo := new(mockRevokedTokenRepository)
+		sv := &grpcService{revokedrepo: revokedrepo}
+		msgerr := errors.New("internal server error")
+
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, msgerr).Once()
+
+		res, err := sv.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{TokenId: "jti"})
+
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, msgerr)
+		revokedrepo.AssertExpectations(t)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+	hash, _ := testhasher.Hash(context.Background(), "password")
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, policy: policy, hasher: testhasher}
+		muser := &user.User{ID: uid, Password: hash, PasswordHistory: []string{"old-hash"}}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		policy.On("Validate", ctx, "new-password", []string{hash, "old-hash"}).Return(nil).Once()
+		policy.On("Remember", hash, []string{"old-hash"}).Return([]string{hash, "old-hash"}).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return verifyPassword("new-password", u.Password) && len(u.PasswordHistory) == 2
+		})).Return(nil).Once()
+
+		res, err := sv.ChangePassword(ctx, &userv1.ChangePasswordRequest{CurrentPassword: "password", NewPassword: "new-password"})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		policy.AssertExpectations(t)
+	})
+
+	t.Run("user modified by another request", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, policy: policy, hasher: testhasher}
+		muser := &user.User{ID: uid, Password: hash}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		policy.On("Validate", ctx, "new-password", mock.Anything).Return(nil).Once()
+		policy.On("Remember", hash, mock.Anything).Return([]string{hash}).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.Anything).Return(user.ErrVersionConflict).Once()
+
+		res, err := sv.ChangePassword(ctx, &userv1.ChangePasswordRequest{CurrentPassword: "password", NewPassword: "new-password"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, userstore.ErrUserModified, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("password policy violation", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, policy: policy, hasher: testhasher}
+		muser := &user.User{ID: uid, Password: hash}
+		msgerr := &password.PolicyError{Violations: []password.Violation{{Reason: "reused", Message: "Password must differ from the last 3 passwords."}}}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		policy.On("Validate", ctx, "password", mock.Anything).Return(msgerr).Once()
+
+		res, err := sv.ChangePassword(ctx, &userv1.ChangePasswordRequest{CurrentPassword: "password", NewPassword: "password"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		repo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, hasher: testhasher}
+		muser := &user.User{ID: uid, Password: hash}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+
+		res, err := sv.ChangePassword(ctx, &userv1.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-password"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Password is invalid."), err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("new password is required", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg, userrepo: new(mockUserRepository), hasher: testhasher}
+
+		res, err := sv.ChangePassword(ctx, &userv1.ChangePasswordRequest{CurrentPassword: "password"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg, userrepo: new(mockUserRepository), hasher: testhasher}
+
+		res, err := sv.ChangePassword(context.Background(), &userv1.ChangePasswordRequest{CurrentPassword: "password", NewPassword: "new-password"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+	email := "test@example.com"
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		resetrepo := new(mockPasswordResetRepository)
+		ml := new(mockMailer)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, resetrepo: resetrepo, mailer: ml}
+		muser := &user.User{ID: uid, Name: "test", Email: types.Email(email)}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(muser, nil).Once()
+		resetrepo.On("InsertOne", ctx, mock.MatchedBy(func(prt *passwordreset.PasswordResetToken) bool {
+			return prt.UserID == uid.Hex() && prt.TokenHash != ""
+		})).Return(nil).Once()
+		ml.On("Send", ctx, mock.MatchedBy(func(msg *mailer.Message) bool {
+			return msg.To == email && strings.Contains(msg.Body, testcfg.PasswordResetURL+"?token=")
+		})).Return(nil).Once()
+
+		res, err := sv.ForgotPassword(ctx, &userv1.ForgotPasswordRequest{Email: email})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		resetrepo.AssertExpectations(t)
+		ml.AssertExpectations(t)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		resetrepo := new(mockPasswordResetRepository)
+		ml := new(mockMailer)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, resetrepo: resetrepo, mailer: ml}
+
+		repo.On("FindByEmail", ctx, types.Email(email)).Return(nil, errors.New("user not found")).Once()
+
+		res, err := sv.ForgotPassword(ctx, &userv1.ForgotPasswordRequest{Email: email})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		resetrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+		ml.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg, userrepo: new(mockUserRepository)}
+
+		res, err := sv.ForgotPassword(ctx, &userv1.ForgotPasswordRequest{Email: "invalid"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+	req := &userv1.ResetPasswordRequest{Token: "reset-token", NewPassword: "new-password"}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		resetrepo := new(mockPasswordResetRepository)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, resetrepo: resetrepo, policy: policy, hasher: testhasher}
+		prt := passwordreset.NewPasswordResetToken(uid.Hex(), req.Token, time.Hour)
+		prt.ID = primitive.NewObjectID()
+
+		resetrepo.On("FindByToken", ctx, req.Token).Return(prt, nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		policy.On("Validate", ctx, req.NewPassword, mock.Anything).Return(nil).Once()
+		policy.On("Remember", mock.Anything, mock.Anything).Return(nil).Once()
+		resetrepo.On("MarkUsed", ctx, prt.ID).Return(nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return verifyPassword(req.NewPassword, u.Password)
+		})).Return(nil).Once()
+		sessionrepo.On("RevokeByUserID", ctx, uid.Hex()).Return(nil).Once()
+		rtrepo.On("RevokeByUserID", ctx, uid.Hex()).Return(nil).Once()
+
+		res, err := sv.ResetPassword(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+		sessionrepo.AssertExpectations(t)
+		resetrepo.AssertExpectations(t)
+	})
+
+	t.Run("token not found", func(t *testing.T) {
+		resetrepo := new(mockPasswordResetRepository)
+		sv := &grpcService{cfg: testcfg, resetrepo: resetrepo, hasher: testhasher}
+
+		resetrepo.On("FindByToken", ctx, req.Token).Return(nil, passwordreset.ErrPasswordResetTokenNotFound).Once()
+
+		res, err := sv.ResetPassword(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Reset token is invalid or expired."), err)
+	})
+
+	t.Run("token expired", func(t *testing.T) {
+		resetrepo := new(mockPasswordResetRepository)
+		sv := &grpcService{cfg: testcfg, resetrepo: resetrepo, hasher: testhasher}
+		prt := passwordreset.NewPasswordResetToken(uid.Hex(), req.Token, -time.Minute)
+
+		resetrepo.On("FindByToken", ctx, req.Token).Return(prt, nil).Once()
+
+		res, err := sv.ResetPassword(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("password policy violation", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		resetrepo := new(mockPasswordResetRepository)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, resetrepo: resetrepo, policy: policy, hasher: testhasher}
+		prt := passwordreset.NewPasswordResetToken(uid.Hex(), req.Token, time.Hour)
+		prt.ID = primitive.NewObjectID()
+		msgerr := &password.PolicyError{Violations: []password.Violation{{Reason: "breached", Message: "Password is too common."}}}
+
+		resetrepo.On("FindByToken", ctx, req.Token).Return(prt, nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		policy.On("Validate", ctx, req.NewPassword, mock.Anything).Return(msgerr).Once()
+
+		res, err := sv.ResetPassword(ctx, req)
+
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, msgerr)
+		resetrepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("token already used", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		resetrepo := new(mockPasswordResetRepository)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, resetrepo: resetrepo, policy: policy, hasher: testhasher}
+		prt := passwordreset.NewPasswordResetToken(uid.Hex(), req.Token, time.Hour)
+		prt.ID = primitive.NewObjectID()
+
+		resetrepo.On("FindByToken", ctx, req.Token).Return(prt, nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		policy.On("Validate", ctx, req.NewPassword, mock.Anything).Return(nil).Once()
+		policy.On("Remember", mock.Anything, mock.Anything).Return(nil).Once()
+		resetrepo.On("MarkUsed", ctx, prt.ID).Return(passwordreset.ErrPasswordResetTokenUsed).Once()
+
+		res, err := sv.ResetPassword(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		repo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg, hasher: testhasher}
+
+		_, err := sv.ResetPassword(ctx, &userv1.ResetPasswordRequest{NewPassword: "new-password"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = sv.ResetPassword(ctx, &userv1.ResetPasswordRequest{Token: "reset-token"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestVerifyMFA(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+	userKey := loginattempt.UserKey(uid.Hex())
+	secret, _ := totp.GenerateSecret()
+	newChallenge := func() *mfachallenge.MFAChallenge {
+		c := mfachallenge.NewMFAChallenge(uid.Hex(), "mfa-token", time.Minute)
+		c.ID = primitive.NewObjectID()
+		return c
+	}
+	newUser := func() *user.User {
+		return &user.User{ID: uid, Roles: []string{"user"}, MFA: &user.MFA{Secret: secret, EnabledAt: ptr.Time(time.Now())}}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sessionrepo := new(mockSessionRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		mfarepo := new(mockMFAChallengeRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, rtrepo: rtrepo, sessionrepo: sessionrepo, attemptrepo: attemptrepo, mfarepo: mfarepo}
+		challenge := newChallenge()
+		code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+		mfarepo.On("FindByToken", ctx, "mfa-token").Return(challenge, nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(newUser(), nil).Once()
+		mfarepo.On("MarkUsed", ctx, challenge.ID).Return(nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.MFA.LastUsedStep > 0
+		})).Return(nil).Once()
+		sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.VerifyMFA(ctx, &userv1.VerifyMFARequest{MfaToken: "mfa-token", Code: code})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uid.Hex(), res.UserId)
+		assert.NotEmpty(t, res.RefreshToken)
+		assert.Equal(t, []string{"user"}, res.Roles)
+		repo.AssertExpectations(t)
+		mfarepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid code - counted as a failed login", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		mfarepo := new(mockMFAChallengeRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, attemptrepo: attemptrepo, mfarepo: mfarepo}
+
+		mfarepo.On("FindByToken", ctx, "mfa-token").Return(newChallenge(), nil).Once()
+		attemptrepo.On("FindByKey", ctx, userKey).Return(nil, loginattempt.ErrLoginAttemptNotFound).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(newUser(), nil).Once()
+		attemptrepo.On("RecordFailure", ctx, userKey, mock.Anything).Return(&loginattempt.LoginAttempt{Key: userKey, Failures: 1}, nil).Once()
+
+		res, err := sv.VerifyMFA(ctx, &userv1.VerifyMFARequest{MfaToken: "mfa-token", Code: "abcdef"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Code is invalid."), err)
+		attemptrepo.AssertExpectations(t)
+		mfarepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("challenge not found", func(t *testing.T) {
+		mfarepo := new(mockMFAChallengeRepository)
+		sv := &grpcService{cfg: testcfg, mfarepo: mfarepo}
+
+		mfarepo.On("FindByToken", ctx, "mfa-token").Return(nil, mfachallenge.ErrMFAChallengeNotFound).Once()
+
+		res, err := sv.VerifyMFA(ctx, &userv1.VerifyMFARequest{MfaToken: "mfa-token", Code: "123456"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("challenge already used", func(t *testing.T) {
+		mfarepo := new(mockMFAChallengeRepository)
+		sv := &grpcService{cfg: testcfg, mfarepo: mfarepo}
+		challenge := newChallenge()
+		challenge.UsedAt = ptr.Time(time.Now())
+
+		mfarepo.On("FindByToken", ctx, "mfa-token").Return(challenge, nil).Once()
+
+		res, err := sv.VerifyMFA(ctx, &userv1.VerifyMFARequest{MfaToken: "mfa-token", Code: "123456"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("missing code", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg}
+
+		res, err := sv.VerifyMFA(ctx, &userv1.VerifyMFARequest{MfaToken: "mfa-token"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestEnrollMFA(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, Email: "test@example.com"}, nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.MFA != nil && u.MFA.Secret != "" && u.MFA.EnabledAt == nil
+		})).Return(nil).Once()
+
+		res, err := sv.EnrollMFA(ctx, &userv1.EnrollMFARequest{})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Secret)
+		assert.True(t, strings.HasPrefix(res.OtpauthUri, "otpauth://totp/backend-golang-test:test@example.com?"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, MFA: &user.MFA{Secret: "secret", EnabledAt: ptr.Time(time.Now())}}, nil).Once()
+
+		res, err := sv.EnrollMFA(ctx, &userv1.EnrollMFARequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg}
+
+		res, err := sv.EnrollMFA(context.Background(), &userv1.EnrollMFARequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestConfirmMFA(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+	secret, _ := totp.GenerateSecret()
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo}
+		code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, MFA: &user.MFA{Secret: secret}}, nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.MFAEnabled() && len(u.MFA.RecoveryCodes) == 4
+		})).Return(nil).Once()
+
+		res, err := sv.ConfirmMFA(ctx, &userv1.ConfirmMFARequest{Code: code})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.RecoveryCodes, 4)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, MFA: &user.MFA{Secret: secret}}, nil).Once()
+
+		res, err := sv.ConfirmMFA(ctx, &userv1.ConfirmMFARequest{Code: "abcdef"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Code is invalid."), err)
+	})
+
+	t.Run("enrollment not started", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+
+		res, err := sv.ConfirmMFA(ctx, &userv1.ConfirmMFARequest{Code: "123456"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestDisableMFA(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+	hash, _ := testhasher.Hash(context.Background(), "password")
+	secret, _ := totp.GenerateSecret()
+	newUser := func() *user.User {
+		return &user.User{ID: uid, Password: hash, MFA: &user.MFA{Secret: secret, EnabledAt: ptr.Time(time.Now())}}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, hasher: testhasher}
+		code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(newUser(), nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.MFA == nil
+		})).Return(nil).Once()
+
+		res, err := sv.DisableMFA(ctx, &userv1.DisableMFARequest{Password: "password", Code: code})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, hasher: testhasher}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(newUser(), nil).Once()
+
+		res, err := sv.DisableMFA(ctx, &userv1.DisableMFARequest{Password: "password", Code: "abcdef"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Code is invalid."), err)
+		repo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user modified by another request", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, hasher: testhasher}
+		code, _ := totp.Code(secret, totp.Step(time.Now()))
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(newUser(), nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.Anything).Return(user.ErrVersionConflict).Once()
+
+		res, err := sv.DisableMFA(ctx, &userv1.DisableMFARequest{Password: "password", Code: code})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, hasher: testhasher}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, Password: hash}, nil).Once()
+
+		res, err := sv.DisableMFA(ctx, &userv1.DisableMFARequest{Password: "password", Code: "123456"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	sv := &grpcService{trustedProxies: testproxies}
+	forwarded := metadata.Pairs("x-forwarded-for", "10.0.0.1")
+
+	t.Run("forwarded by a trusted proxy", func(t *testing.T) {
+		assert.Equal(t, "10.0.0.1", sv.clientIP(proxyContext(context.Background(), "x-forwarded-for", "10.0.0.1")))
+	})
+
+	t.Run("forwarded by an untrusted peer", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 7), Port: 50000}})
+		ctx = metadata.NewIncomingContext(ctx, forwarded)
+
+		assert.Equal(t, "192.0.2.7", sv.clientIP(ctx))
+	})
+
+	t.Run("without peer", func(t *testing.T) {
+		assert.Empty(t, sv.clientIP(metadata.NewIncomingContext(context.Background(), forwarded)))
+	})
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := parseTrustedProxies([]string{"10.0.0.0/8", " ::1 ", "192.0.2.1", ""})
+
+	assert.NoError(t, err)
+	assert.Len(t, proxies, 3)
+	assert.True(t, proxies[2].Contains(net.ParseIP("192.0.2.1")))
+	assert.False(t, proxies[2].Contains(net.ParseIP("192.0.2.2")))
+
+	_, err = parseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
 }
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ImpersonateUser lets an admin act as another user to reproduce a problem.
+// The start is recorded in the audit trail with the reason, and so is every
+// call made with the token.
+func (g *grpcService) ImpersonateUser(ctx context.Context, req *userv1.ImpersonateUserRequest) (*userv1.ImpersonateUserResponse, error) {
+	claims := identity.ClaimsFromContext(ctx)
+	if claims == nil || claims.UserID == "" {
+		return nil, status.Error(codes.PermissionDenied, "Permission denied.")
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "User id is required.")
+	}
+	if req.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "Reason is required.")
+	}
+	if req.UserId == claims.UserID {
+		return nil, status.Error(codes.InvalidArgument, "You cannot impersonate yourself.")
+	}
+
+	u, err := g.userrepo.FindByID(ctx, req.UserId)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+	if u == nil || u.DeletedAt != nil {
+		return nil, status.Error(codes.NotFound, "User not found.")
+	}
+
+	// Acting as another admin would hide who did what.
+	if rbac.HasPermission(u.Roles, rbac.PermissionUsersImpersonate) {
+		return nil, status.Error(codes.PermissionDenied, "This user cannot be impersonated.")
+	}
+
+	l := auditlog.NewAuditLog(auditlog.ActionImpersonationStart, claims.UserID, req.UserId)
+	l.Reason = req.Reason
+	l.IP = g.clientIP(ctx)
+	if err := g.auditrepo.InsertOne(ctx, l); err != nil {
+		return nil, err
+	}
+
+	ac, err := g.tokensv.GenerateImpersonationToken(req.UserId, claims.UserID, u.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.ImpersonateUserResponse{
+		AccessToken: ac,
+		ExpiresAt:   timestamppb.New(time.Now().Add(g.cfg.ImpersonationTTL)),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestImpersonateUser(t *testing.T) {
+	cfg := *testcfg
+	cfg.ImpersonationTTL = 15 * time.Minute
+	adminID := primitive.NewObjectID().Hex()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: adminID, Roles: []string{"admin"}})
+	target := &user.User{ID: primitive.NewObjectID(), Roles: []string{"user"}}
+
+	t.Run("success", func(t *testing.T) {
+		userrepo := new(mockUserRepository)
+		auditrepo := new(mockAuditLogRepository)
+		tokensv := new(mockTokenService)
+		sv := &grpcService{cfg: &cfg, userrepo: userrepo, auditrepo: auditrepo, tokensv: tokensv}
+
+		userrepo.On("FindByID", ctx, target.ID.Hex()).Return(target, nil).Once()
+		auditrepo.On("InsertOne", ctx, mock.MatchedBy(func(l *auditlog.AuditLog) bool {
+			return l.Action == auditlog.ActionImpersonationStart && l.ActorID == adminID && l.UserID == target.ID.Hex() && l.Reason == "ticket 42"
+		})).Return(nil).Once()
+		tokensv.On("GenerateImpersonationToken", target.ID.Hex(), adminID, []string{"user"}).Return("at", nil).Once()
+
+		res, err := sv.ImpersonateUser(ctx, &userv1.ImpersonateUserRequest{UserId: target.ID.Hex(), Reason: "ticket 42"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "at", res.AccessToken)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), res.ExpiresAt.AsTime(), time.Second)
+		auditrepo.AssertExpectations(t)
+	})
+
+	t.Run("admin cannot be impersonated", func(t *testing.T) {
+		userrepo := new(mockUserRepository)
+		auditrepo := new(mockAuditLogRepository)
+		sv := &grpcService{cfg: &cfg, userrepo: userrepo, auditrepo: auditrepo}
+		admin := &user.User{ID: primitive.NewObjectID(), Roles: []string{"admin"}}
+
+		userrepo.On("FindByID", ctx, admin.ID.Hex()).Return(admin, nil).Once()
+
+		res, err := sv.ImpersonateUser(ctx, &userv1.ImpersonateUserRequest{UserId: admin.ID.Hex(), Reason: "ticket 42"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		auditrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		userrepo := new(mockUserRepository)
+		sv := &grpcService{cfg: &cfg, userrepo: userrepo}
+
+		userrepo.On("FindByID", ctx, target.ID.Hex()).Return(nil, user.ErrUserNotFound).Once()
+
+		res, err := sv.ImpersonateUser(ctx, &userv1.ImpersonateUserRequest{UserId: target.ID.Hex(), Reason: "ticket 42"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.NotFound, "User not found."), err)
+	})
+
+	t.Run("yourself", func(t *testing.T) {
+		sv := &grpcService{cfg: &cfg}
+
+		res, err := sv.ImpersonateUser(ctx, &userv1.ImpersonateUserRequest{UserId: adminID, Reason: "ticket 42"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		sv := &grpcService{cfg: &cfg}
+
+		res, err := sv.ImpersonateUser(ctx, &userv1.ImpersonateUserRequest{UserId: target.ID.Hex()})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Reason is required."), err)
+	})
+
+	t.Run("client token", func(t *testing.T) {
+		sv := &grpcService{cfg: &cfg}
+		clientctx := identity.WithClaims(context.Background(), &token.JwtToken{ClientID: "cid", Scopes: []string{"users:impersonate"}})
+
+		res, err := sv.ImpersonateUser(clientctx, &userv1.ImpersonateUserRequest{UserId: target.ID.Hex(), Reason: "ticket 42"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
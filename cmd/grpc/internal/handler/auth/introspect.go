@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	tokenStatusActive  = "active"
+	tokenStatusExpired = "expired"
+	tokenStatusRevoked = "revoked"
+	tokenStatusInvalid = "invalid"
+)
+
+// IntrospectToken tells other services whether an access token is still
+// accepted, as in RFC 7662. The claims of a token that is not active are not
+// returned.
+func (g *grpcService) IntrospectToken(ctx context.Context, req *userv1.IntrospectTokenRequest) (*userv1.IntrospectTokenResponse, error) {
+	if req.AccessToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "Access token is required.")
+	}
+
+	claims, err := g.tokensv.VerifyAccessToken(req.AccessToken)
+	if errors.Is(err, token.ErrTokenExpired) {
+		return &userv1.IntrospectTokenResponse{Status: tokenStatusExpired}, nil
+	}
+	if err != nil {
+		return &userv1.IntrospectTokenResponse{Status: tokenStatusInvalid}, nil
+	}
+
+	revoked, err := g.isTokenRevoked(ctx, claims.Id, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return &userv1.IntrospectTokenResponse{Status: tokenStatusRevoked}, nil
+	}
+
+	return mapGRPCIntrospection(claims), nil
+}
+
+// isTokenRevoked reports whether the token id was revoked on logout, or the
+// session the token was issued for was revoked.
+func (g *grpcService) isTokenRevoked(ctx context.Context, tokenID, sessionID string) (bool, error) {
+	if tokenID != "" {
+		revoked, err := g.revokedrepo.IsRevoked(ctx, tokenID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if sessionID != "" {
+		active, err := g.isSessionActive(ctx, sessionID)
+		return !active, err
+	}
+	return false, nil
+}
+
+func mapGRPCIntrospection(claims *token.JwtToken) *userv1.IntrospectTokenResponse {
+	subject := claims.UserID
+	if claims.ClientID != "" {
+		subject = claims.ClientID
+	}
+
+	res := &userv1.IntrospectTokenResponse{
+		Active:    true,
+		Status:    tokenStatusActive,
+		Subject:   subject,
+		ClientId:  claims.ClientID,
+		SessionId: claims.SessionID,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+		TokenId:   claims.Id,
+		ExpiresAt: timestamppb.New(claims.ExpiresAtTime()),
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+	}
+	if claims.IsImpersonated() {
+		res.Actor = claims.Actor.Subject
+	}
+	if claims.IssuedAt != 0 {
+		res.IssuedAt = timestamppb.New(time.Unix(claims.IssuedAt, 0))
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntrospectToken(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	t.Run("active user token", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		revokedrepo := new(mockRevokedTokenRepository)
+		sv := &grpcService{tokensv: tokensv, revokedrepo: revokedrepo}
+		claims := &token.JwtToken{
+			StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: expiresAt},
+			UserID:         "uid",
+			Roles:          []string{"user"},
+		}
+
+		tokensv.On("VerifyAccessToken", "at").Return(claims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{AccessToken: "at"})
+
+		assert.NoError(t, err)
+		assert.True(t, res.Active)
+		assert.Equal(t, "active", res.Status)
+		assert.Equal(t, "uid", res.Subject)
+		assert.Equal(t, []string{"user"}, res.Roles)
+		assert.Equal(t, "jti", res.TokenId)
+		assert.Equal(t, expiresAt, res.ExpiresAt.AsTime().Unix())
+	})
+
+	t.Run("active client token", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		revokedrepo := new(mockRevokedTokenRepository)
+		sv := &grpcService{tokensv: tokensv, revokedrepo: revokedrepo}
+		claims := &token.JwtToken{
+			StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: expiresAt},
+			ClientID:       "cid",
+			Scopes:         []string{"users:read"},
+		}
+
+		tokensv.On("VerifyAccessToken", "at").Return(claims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{AccessToken: "at"})
+
+		assert.NoError(t, err)
+		assert.True(t, res.Active)
+		assert.Equal(t, "cid", res.Subject)
+		assert.Equal(t, "cid", res.ClientId)
+		assert.Equal(t, []string{"users:read"}, res.Scopes)
+	})
+
+	t.Run("active impersonation token", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		revokedrepo := new(mockRevokedTokenRepository)
+		sv := &grpcService{tokensv: tokensv, revokedrepo: revokedrepo}
+		issuedAt := time.Now().Unix()
+		claims := &token.JwtToken{
+			StandardClaims: jwt.StandardClaims{
+				Id:        "jti",
+				ExpiresAt: expiresAt,
+				IssuedAt:  issuedAt,
+				Issuer:    "iss",
+				Audience:  "aud",
+			},
+			UserID: "uid",
+			Roles:  []string{"user"},
+			Actor:  &token.Actor{Subject: "admin-id"},
+		}
+
+		tokensv.On("VerifyAccessToken", "at").Return(claims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{AccessToken: "at"})
+
+		assert.NoError(t, err)
+		assert.True(t, res.Active)
+		assert.Equal(t, "uid", res.Subject)
+		assert.Equal(t, "admin-id", res.Actor)
+		assert.Equal(t, "iss", res.Issuer)
+		assert.Equal(t, "aud", res.Audience)
+		assert.Equal(t, issuedAt, res.IssuedAt.AsTime().Unix())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		sv := &grpcService{tokensv: tokensv}
+
+		tokensv.On("VerifyAccessToken", "at").Return(nil, token.ErrTokenExpired).Once()
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{AccessToken: "at"})
+
+		assert.NoError(t, err)
+		assert.False(t, res.Active)
+		assert.Equal(t, "expired", res.Status)
+		assert.Empty(t, res.Subject)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		revokedrepo := new(mockRevokedTokenRepository)
+		sv := &grpcService{tokensv: tokensv, revokedrepo: revokedrepo}
+		claims := &token.JwtToken{
+			StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: expiresAt},
+			UserID:         "uid",
+		}
+
+		tokensv.On("VerifyAccessToken", "at").Return(claims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(true, nil).Once()
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{AccessToken: "at"})
+
+		assert.NoError(t, err)
+		assert.False(t, res.Active)
+		assert.Equal(t, "revoked", res.Status)
+		assert.Empty(t, res.Subject)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tokensv := new(mockTokenService)
+		sv := &grpcService{tokensv: tokensv}
+
+		tokensv.On("VerifyAccessToken", "at").Return(nil, errors.New("signature is invalid")).Once()
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{AccessToken: "at"})
+
+		assert.NoError(t, err)
+		assert.False(t, res.Active)
+		assert.Equal(t, "invalid", res.Status)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Access token is required."), err)
+	})
+}
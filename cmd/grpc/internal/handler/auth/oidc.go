@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/userstore"
+	"github.com/nuea/backend-golang-test/internal/client/oidc"
+	"github.com/nuea/backend-golang-test/internal/repository/oidcstate"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errInvalidOIDCState = status.Error(codes.Unauthenticated, "Login state is invalid or expired.")
+	errDeletedAccount   = status.Error(codes.PermissionDenied, "Account has been deleted.")
+)
+
+// BeginOIDCLogin starts the authorization code flow with PKCE. The nonce and
+// the code verifier are kept with the state until CompleteOIDCLogin.
+func (g *grpcService) BeginOIDCLogin(ctx context.Context, req *userv1.BeginOIDCLoginRequest) (*userv1.BeginOIDCLoginResponse, error) {
+	state, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := util.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := g.oidc.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, oidcError(err)
+	}
+
+	if err := g.oidcrepo.InsertOne(ctx, oidcstate.NewOIDCState(state, nonce, verifier, g.oidcCfg.StateTTL)); err != nil {
+		return nil, err
+	}
+
+	return &userv1.BeginOIDCLoginResponse{
+		AuthorizationUrl: authURL,
+		State:            state,
+	}, nil
+}
+
+// CompleteOIDCLogin redeems the authorization code and signs in the user
+// linked to the external identity, who is linked or created on the first
+// login.
+func (g *grpcService) CompleteOIDCLogin(ctx context.Context, req *userv1.CompleteOIDCLoginRequest) (*userv1.CompleteOIDCLoginResponse, error) {
+	if req.State == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "State and code are required.")
+	}
+
+	st, err := g.oidcrepo.FindByState(ctx, req.State)
+	if err != nil {
+		if errors.Is(err, oidcstate.ErrOIDCStateNotFound) {
+			return nil, errInvalidOIDCState
+		}
+		return nil, err
+	}
+
+	if st.UsedAt != nil || st.IsExpired() {
+		return nil, errInvalidOIDCState
+	}
+
+	if err := g.oidcrepo.MarkUsed(ctx, st.ID); err != nil {
+		if errors.Is(err, oidcstate.ErrOIDCStateUsed) {
+			return nil, errInvalidOIDCState
+		}
+		return nil, err
+	}
+
+	claims, err := g.oidc.Exchange(ctx, req.Code, st.CodeVerifier, st.Nonce)
+	if err != nil {
+		return nil, oidcError(err)
+	}
+
+	u, err := g.userFromIdentity(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.cfg.RequireVerifiedEmail && u.EmailVerifiedAt == nil {
+		return nil, status.Error(codes.FailedPrecondition, "Email is not verified.")
+	}
+
+	// The issuer may not ask for a second factor, so MFA enabled here is
+	// still required.
+	if u.MFAEnabled() {
+		raw, err := g.newMFAChallenge(ctx, u.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+
+		return &userv1.CompleteOIDCLoginResponse{
+			UserId:      u.ID.Hex(),
+			MfaRequired: true,
+			MfaToken:    raw,
+		}, nil
+	}
+
+	sid, rt, err := g.startSession(ctx, u.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.CompleteOIDCLoginResponse{
+		UserId:       u.ID.Hex(),
+		RefreshToken: rt,
+		Roles:        u.Roles,
+		SessionId:    sid,
+	}, nil
+}
+
+// userFromIdentity returns the user linked to the external identity. On the
+// first login the identity is linked to the user with the same email, only
+// when both the issuer and the user verified it, otherwise a new user is
+// created.
+func (g *grpcService) userFromIdentity(ctx context.Context, claims *oidc.Claims) (*user.User, error) {
+	u, err := g.userrepo.FindByIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		if u.DeletedAt != nil {
+			return nil, errDeletedAccount
+		}
+		return u, nil
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	email, err := types.NewEmail(claims.Email)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "Identity provider did not return a valid email.")
+	}
+
+	now := time.Now().UTC()
+	identity := user.Identity{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		LinkedAt: now,
+	}
+
+	u, err = g.userrepo.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		// Anyone can claim an email at some issuers, linking it unverified
+		// would hand over the account.
+		if !claims.EmailVerified {
+			return nil, status.Error(codes.FailedPrecondition, "Email is not verified by the identity provider.")
+		}
+		if u.DeletedAt != nil {
+			return nil, errDeletedAccount
+		}
+		// Nor is an account that never proved the email: whoever registered
+		// it could be someone else, and their password would keep working.
+		if u.EmailVerifiedAt == nil {
+			return nil, status.Error(codes.FailedPrecondition, "Email of the existing account is not verified, verify it or reset the password first.")
+		}
+
+		u.Identities = append(u.Identities, identity)
+		u.UpdatedAt = now
+
+		if err := userstore.Replace(ctx, g.userrepo, u.ID.Hex(), u); err != nil {
+			return nil, err
+		}
+		return u, nil
+	case errors.Is(err, user.ErrUserNotFound):
+		u = user.NewUser()
+		u.ID = primitive.NewObjectID()
+		u.Name = claims.Name
+		if u.Name == "" {
+			u.Name, _, _ = strings.Cut(string(email), "@")
+		}
+		u.Email = email
+		u.Identities = []user.Identity{identity}
+		if claims.EmailVerified {
+			u.EmailVerifiedAt = &now
+		}
+
+		if err := g.userrepo.InsertOne(ctx, u); err != nil {
+			return nil, err
+		}
+		return u, nil
+	default:
+		return nil, err
+	}
+}
+
+// oidcError maps the errors of the provider. Failures to reach the issuer
+// are logged, as they are not caused by the user.
+func oidcError(err error) error {
+	switch {
+	case errors.Is(err, oidc.ErrNotConfigured):
+		return status.Error(codes.FailedPrecondition, "OIDC login is not configured.")
+	case errors.Is(err, oidc.ErrInvalidGrant):
+		return status.Error(codes.Unauthenticated, "Authorization code is invalid or expired.")
+	case errors.Is(err, oidc.ErrInvalidIDToken):
+		log.Println("Unable to verify the ID token:", err)
+		return status.Error(codes.Unauthenticated, "ID token is invalid.")
+	default:
+		log.Println("Unable to reach the identity provider:", err)
+		return status.Error(codes.Unavailable, "Identity provider is unavailable.")
+	}
+}
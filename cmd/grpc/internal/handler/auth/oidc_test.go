@@ -0,0 +1,384 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/internal/client/oidc"
+	"github.com/nuea/backend-golang-test/internal/client/oidc/oidctest"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/repository/mfachallenge"
+	"github.com/nuea/backend-golang-test/internal/repository/oidcstate"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (m *mockUserRepository) InsertOne(ctx context.Context, u *user.User) error {
+	args := m.Called(ctx, u)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) FindByIdentity(ctx context.Context, issuer, subject string) (*user.User, error) {
+	args := m.Called(ctx, issuer, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+type mockOIDCStateRepository struct {
+	mock.Mock
+	oidcstate.OIDCStateRepository
+}
+
+func (m *mockOIDCStateRepository) InsertOne(ctx context.Context, state *oidcstate.OIDCState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *mockOIDCStateRepository) FindByState(ctx context.Context, state string) (*oidcstate.OIDCState, error) {
+	args := m.Called(ctx, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidcstate.OIDCState), args.Error(1)
+}
+
+func (m *mockOIDCStateRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newOIDCProvider(discoveryURL string) oidc.Provider {
+	return oidc.ProvideOIDCProvider(&config.AppConfig{OIDC: config.OIDCConfig{
+		DiscoveryURL:   discoveryURL,
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		RedirectURL:    "http://localhost:8080/api/v1/oidc/callback",
+		RequestTimeout: 5 * time.Second,
+	}})
+}
+
+func TestBeginOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+	oidcCfg := &config.OIDCConfig{StateTTL: 10 * time.Minute}
+
+	t.Run("success", func(t *testing.T) {
+		staterepo := new(mockOIDCStateRepository)
+		sv := &grpcService{cfg: testcfg, oidcCfg: oidcCfg, oidcrepo: staterepo, oidc: newOIDCProvider(idp.DiscoveryURL())}
+
+		var stored *oidcstate.OIDCState
+		staterepo.On("InsertOne", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*oidcstate.OIDCState)
+		}).Return(nil).Once()
+
+		res, err := sv.BeginOIDCLogin(ctx, &userv1.BeginOIDCLoginRequest{})
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(res.AuthorizationUrl, idp.URL+"/authorize?"))
+		assert.Contains(t, res.AuthorizationUrl, "state="+res.State)
+		assert.Contains(t, res.AuthorizationUrl, "nonce="+stored.Nonce)
+		assert.Contains(t, res.AuthorizationUrl, "code_challenge="+oidc.CodeChallenge(stored.CodeVerifier))
+		assert.Equal(t, util.HashToken(res.State), stored.StateHash)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		staterepo := new(mockOIDCStateRepository)
+		sv := &grpcService{cfg: testcfg, oidcCfg: oidcCfg, oidcrepo: staterepo, oidc: newOIDCProvider("")}
+
+		res, err := sv.BeginOIDCLogin(ctx, &userv1.BeginOIDCLoginRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.FailedPrecondition, "OIDC login is not configured."), err)
+		staterepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("identity provider unavailable", func(t *testing.T) {
+		staterepo := new(mockOIDCStateRepository)
+		sv := &grpcService{cfg: testcfg, oidcCfg: oidcCfg, oidcrepo: staterepo, oidc: newOIDCProvider(idp.URL + "/missing")}
+
+		res, err := sv.BeginOIDCLogin(ctx, &userv1.BeginOIDCLoginRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+	oidcCfg := &config.OIDCConfig{StateTTL: 10 * time.Minute}
+	uid := primitive.NewObjectID()
+
+	type mocks struct {
+		userrepo    *mockUserRepository
+		rtrepo      *mockRefreshTokenRepository
+		sessionrepo *mockSessionRepository
+		mfarepo     *mockMFAChallengeRepository
+		staterepo   *mockOIDCStateRepository
+	}
+	newService := func(cfg *config.AuthConfig) (*grpcService, *mocks) {
+		m := &mocks{
+			userrepo:    new(mockUserRepository),
+			rtrepo:      new(mockRefreshTokenRepository),
+			sessionrepo: new(mockSessionRepository),
+			mfarepo:     new(mockMFAChallengeRepository),
+			staterepo:   new(mockOIDCStateRepository),
+		}
+		return &grpcService{
+			cfg:         cfg,
+			oidcCfg:     oidcCfg,
+			userrepo:    m.userrepo,
+			rtrepo:      m.rtrepo,
+			sessionrepo: m.sessionrepo,
+			mfarepo:     m.mfarepo,
+			oidcrepo:    m.staterepo,
+			oidc:        newOIDCProvider(idp.DiscoveryURL()),
+		}, m
+	}
+
+	// authorize starts a login of the stand-in user and returns the request
+	// the callback would send.
+	authorize := func(t *testing.T, sv *grpcService, m *mocks) *userv1.CompleteOIDCLoginRequest {
+		var stored *oidcstate.OIDCState
+		m.staterepo.On("InsertOne", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*oidcstate.OIDCState)
+			stored.ID = primitive.NewObjectID()
+		}).Return(nil).Once()
+
+		res, err := sv.BeginOIDCLogin(ctx, &userv1.BeginOIDCLoginRequest{})
+		assert.NoError(t, err)
+
+		code, state, err := idp.Authorize(res.AuthorizationUrl)
+		assert.NoError(t, err)
+		assert.Equal(t, res.State, state)
+
+		m.staterepo.On("FindByState", ctx, state).Return(stored, nil).Once()
+		m.staterepo.On("MarkUsed", ctx, stored.ID).Return(nil).Once()
+		return &userv1.CompleteOIDCLoginRequest{State: state, Code: code}
+	}
+
+	t.Run("linked user", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(&user.User{ID: uid, Roles: []string{"user"}}, nil).Once()
+		m.sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		m.rtrepo.On("InsertOne", ctx, mock.MatchedBy(func(rt *refreshtoken.RefreshToken) bool {
+			return rt.UserID == uid.Hex()
+		})).Return(nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uid.Hex(), res.UserId)
+		assert.NotEmpty(t, res.RefreshToken)
+		assert.NotEmpty(t, res.SessionId)
+		assert.Equal(t, []string{"user"}, res.Roles)
+		m.userrepo.AssertNotCalled(t, "FindByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("link by verified email", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		existing := &user.User{ID: uid, Email: "oidctest@example.com", EmailVerifiedAt: ptr.Time(time.Now()), Password: "hash", Roles: []string{"support"}}
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("FindByEmail", ctx, types.Email("oidctest@example.com")).Return(existing, nil).Once()
+		m.userrepo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return len(u.Identities) == 1 &&
+				u.Identities[0].Issuer == idp.Issuer() &&
+				u.Identities[0].Subject == "oidctest-user" &&
+				u.EmailVerifiedAt != nil &&
+				u.Password == "hash"
+		})).Return(nil).Once()
+		m.sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		m.rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uid.Hex(), res.UserId)
+		assert.Equal(t, []string{"support"}, res.Roles)
+		m.userrepo.AssertExpectations(t)
+	})
+
+	t.Run("existing email not verified by the user", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		squatted := &user.User{ID: uid, Email: "oidctest@example.com", Password: "squatter-hash"}
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("FindByEmail", ctx, types.Email("oidctest@example.com")).Return(squatted, nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		m.userrepo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+		m.sessionrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("existing email not verified by the issuer", func(t *testing.T) {
+		idp.User.EmailVerified = false
+		defer func() { idp.User.EmailVerified = true }()
+
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("FindByEmail", ctx, types.Email("oidctest@example.com")).Return(&user.User{ID: uid}, nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.FailedPrecondition, "Email is not verified by the identity provider."), err)
+		m.userrepo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("new user", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		var created *user.User
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("FindByEmail", ctx, types.Email("oidctest@example.com")).Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("InsertOne", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*user.User)
+		}).Return(nil).Once()
+		m.sessionrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		m.rtrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, created.ID.Hex(), res.UserId)
+		assert.Equal(t, []string{"user"}, res.Roles)
+		assert.Equal(t, "OIDC Test", created.Name)
+		assert.Equal(t, types.Email("oidctest@example.com"), created.Email)
+		assert.Empty(t, created.Password)
+		assert.NotNil(t, created.EmailVerifiedAt)
+		assert.Equal(t, "oidctest-user", created.Identities[0].Subject)
+	})
+
+	t.Run("new user without verified email", func(t *testing.T) {
+		idp.User.EmailVerified = false
+		defer func() { idp.User.EmailVerified = true }()
+
+		sv, m := newService(&config.AuthConfig{RequireVerifiedEmail: true})
+		req := authorize(t, sv, m)
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("FindByEmail", ctx, types.Email("oidctest@example.com")).Return(nil, user.ErrUserNotFound).Once()
+		m.userrepo.On("InsertOne", ctx, mock.MatchedBy(func(u *user.User) bool {
+			return u.EmailVerifiedAt == nil
+		})).Return(nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.FailedPrecondition, "Email is not verified."), err)
+	})
+
+	t.Run("mfa enabled", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		u := &user.User{ID: uid, MFA: &user.MFA{Secret: "secret", EnabledAt: ptr.Time(time.Now())}}
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(u, nil).Once()
+		m.mfarepo.On("InsertOne", ctx, mock.MatchedBy(func(c *mfachallenge.MFAChallenge) bool {
+			return c.UserID == uid.Hex()
+		})).Return(nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.NoError(t, err)
+		assert.True(t, res.MfaRequired)
+		assert.NotEmpty(t, res.MfaToken)
+		assert.Empty(t, res.RefreshToken)
+		m.rtrepo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted account", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		m.userrepo.On("FindByIdentity", ctx, idp.Issuer(), "oidctest-user").Return(&user.User{ID: uid, DeletedAt: ptr.Time(time.Now())}, nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, errDeletedAccount, err)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		req := authorize(t, sv, m)
+		req.Code = "other"
+
+		res, err := sv.CompleteOIDCLogin(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.Unauthenticated, "Authorization code is invalid or expired."), err)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		m.staterepo.On("FindByState", ctx, "state").Return(nil, oidcstate.ErrOIDCStateNotFound).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, &userv1.CompleteOIDCLoginRequest{State: "state", Code: "code"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidOIDCState, err)
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		m.staterepo.On("FindByState", ctx, "state").Return(&oidcstate.OIDCState{ExpiresAt: time.Now().Add(-time.Minute)}, nil).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, &userv1.CompleteOIDCLoginRequest{State: "state", Code: "code"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidOIDCState, err)
+		m.staterepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("state used concurrently", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		id := primitive.NewObjectID()
+		m.staterepo.On("FindByState", ctx, "state").Return(&oidcstate.OIDCState{ID: id, ExpiresAt: time.Now().Add(time.Minute)}, nil).Once()
+		m.staterepo.On("MarkUsed", ctx, id).Return(oidcstate.ErrOIDCStateUsed).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, &userv1.CompleteOIDCLoginRequest{State: "state", Code: "code"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidOIDCState, err)
+	})
+
+	t.Run("missing state or code", func(t *testing.T) {
+		sv, _ := newService(testcfg)
+
+		res, err := sv.CompleteOIDCLogin(ctx, &userv1.CompleteOIDCLoginRequest{State: "state"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "State and code are required."), err)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		sv, m := newService(testcfg)
+		m.staterepo.On("FindByState", ctx, "state").Return(nil, errors.New("internal server error")).Once()
+
+		res, err := sv.CompleteOIDCLogin(ctx, &userv1.CompleteOIDCLoginRequest{State: "state", Code: "code"})
+
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "internal server error")
+	})
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var errSessionNotFound = status.Error(codes.NotFound, "Session not found.")
+
+func (g *grpcService) ListSessions(ctx context.Context, req *userv1.ListSessionsRequest) (*userv1.ListSessionsResponse, error) {
+	claims := identity.ClaimsFromContext(ctx)
+	uid, err := sessionOwner(claims, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := g.sessionrepo.FindActiveByUserID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*userv1.Session, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, mapGRPCSession(s, claims.SessionID))
+	}
+	return &userv1.ListSessionsResponse{Sessions: res}, nil
+}
+
+func (g *grpcService) RevokeSession(ctx context.Context, req *userv1.RevokeSessionRequest) (*userv1.RevokeSessionResponse, error) {
+	claims := identity.ClaimsFromContext(ctx)
+	if claims == nil || claims.UserID == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	s, err := g.sessionrepo.FindByID(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			return nil, errSessionNotFound
+		}
+		return nil, err
+	}
+
+	// Sessions of other users are reported as missing, not forbidden, so ids
+	// cannot be probed.
+	if s.UserID != claims.UserID && !claims.HasPermission(rbac.PermissionSessionsManage) {
+		return nil, errSessionNotFound
+	}
+
+	if err := g.revokeSession(ctx, s.ID); err != nil {
+		return nil, err
+	}
+	return &userv1.RevokeSessionResponse{}, nil
+}
+
+func (g *grpcService) RevokeAllSessions(ctx context.Context, req *userv1.RevokeAllSessionsRequest) (*userv1.RevokeAllSessionsResponse, error) {
+	uid, err := sessionOwner(identity.ClaimsFromContext(ctx), req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.revokeUserSessions(ctx, uid); err != nil {
+		return nil, err
+	}
+	return &userv1.RevokeAllSessionsResponse{}, nil
+}
+
+// sessionOwner returns the user whose sessions are managed, the caller
+// unless userID names another user, which requires the sessions:manage
+// permission.
+func sessionOwner(claims *token.JwtToken, userID *string) (string, error) {
+	if claims == nil || claims.UserID == "" {
+		return "", status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	if userID == nil || *userID == claims.UserID {
+		return claims.UserID, nil
+	}
+	if !claims.HasPermission(rbac.PermissionSessionsManage) {
+		return "", status.Error(codes.PermissionDenied, "Permission denied.")
+	}
+	return *userID, nil
+}
+
+// startSession records a login of the user on the calling device and issues
+// the first refresh token of the session.
+func (g *grpcService) startSession(ctx context.Context, userID string) (string, string, error) {
+	deviceID, userAgent := clientDevice(ctx)
+	s := session.NewSession(userID, deviceID, userAgent, g.clientIP(ctx), g.cfg.RefreshTokenExpireTTL)
+	if err := g.sessionrepo.InsertOne(ctx, s); err != nil {
+		return "", "", err
+	}
+
+	rt, err := g.issueRefreshToken(ctx, s.ID.Hex(), userID)
+	if err != nil {
+		return "", "", err
+	}
+	return s.ID.Hex(), rt, nil
+}
+
+// extendSession keeps the session of a refresh token family alive for as
+// long as its next refresh token. Logins from before sessions were recorded
+// get one on their first refresh.
+func (g *grpcService) extendSession(ctx context.Context, familyID, userID string) error {
+	expiresAt := time.Now().UTC().Add(g.cfg.RefreshTokenExpireTTL)
+
+	s, err := g.sessionrepo.FindByID(ctx, familyID)
+	if err != nil {
+		if !errors.Is(err, session.ErrSessionNotFound) {
+			return err
+		}
+
+		id, err := primitive.ObjectIDFromHex(familyID)
+		if err != nil {
+			return err
+		}
+		deviceID, userAgent := clientDevice(ctx)
+		s = session.NewSession(userID, deviceID, userAgent, g.clientIP(ctx), g.cfg.RefreshTokenExpireTTL)
+		s.ID = id
+		return g.sessionrepo.InsertOne(ctx, s)
+	}
+
+	if s.IsRevoked() {
+		return g.revokeFamily(ctx, familyID)
+	}
+	return g.sessionrepo.Extend(ctx, s.ID, g.clientIP(ctx), expiresAt)
+}
+
+// isSessionActive reports whether the session of an access token is neither
+// revoked nor expired. A session that no longer exists is not active.
+func (g *grpcService) isSessionActive(ctx context.Context, id string) (bool, error) {
+	s, err := g.sessionrepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !s.IsRevoked() && !s.IsExpired(), nil
+}
+
+// revokeSession ends the session, its refresh tokens stop working and its
+// access tokens are rejected on their next use.
+func (g *grpcService) revokeSession(ctx context.Context, id primitive.ObjectID) error {
+	if err := g.sessionrepo.Revoke(ctx, id); err != nil {
+		return err
+	}
+	return g.rtrepo.RevokeFamily(ctx, id.Hex())
+}
+
+func (g *grpcService) revokeUserSessions(ctx context.Context, userID string) error {
+	if err := g.sessionrepo.RevokeByUserID(ctx, userID); err != nil {
+		return err
+	}
+	return g.rtrepo.RevokeByUserID(ctx, userID)
+}
+
+// clientDevice returns the device id and the user agent forwarded by the
+// HTTP server, or the user agent of the gRPC client for direct calls.
+func clientDevice(ctx context.Context) (deviceID, userAgent string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	if v := md.Get("x-device-id"); len(v) > 0 {
+		deviceID = v[0]
+	}
+	if v := md.Get("x-forwarded-user-agent"); len(v) > 0 {
+		userAgent = v[0]
+	} else if v := md.Get("user-agent"); len(v) > 0 {
+		userAgent = v[0]
+	}
+	return deviceID, userAgent
+}
+
+func mapGRPCSession(s *session.Session, currentID string) *userv1.Session {
+	return &userv1.Session{
+		Id:           s.ID.Hex(),
+		UserId:       s.UserID,
+		DeviceId:     s.DeviceID,
+		UserAgent:    s.UserAgent,
+		Ip:           s.IP,
+		CreatedAt:    timestamppb.New(s.CreatedAt),
+		LastActiveAt: timestamppb.New(s.LastActiveAt),
+		ExpiresAt:    timestamppb.New(s.ExpiresAt),
+		Current:      s.ID.Hex() == currentID,
+	}
+}
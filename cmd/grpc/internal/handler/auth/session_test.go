@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListSessions(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	current := session.NewSession(uid, "phone", "Mozilla/5.0", "10.0.0.1", time.Hour)
+	other := session.NewSession(uid, "laptop", "", "", time.Hour)
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid, SessionID: current.ID.Hex(), Roles: []string{"user"}})
+
+	t.Run("own sessions", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{sessionrepo: sessionrepo}
+
+		sessionrepo.On("FindActiveByUserID", ctx, uid).Return([]*session.Session{current, other}, nil).Once()
+
+		res, err := sv.ListSessions(ctx, &userv1.ListSessionsRequest{})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Sessions, 2)
+		assert.Equal(t, "phone", res.Sessions[0].DeviceId)
+		assert.Equal(t, "10.0.0.1", res.Sessions[0].Ip)
+		assert.True(t, res.Sessions[0].Current)
+		assert.False(t, res.Sessions[1].Current)
+		sessionrepo.AssertExpectations(t)
+	})
+
+	t.Run("sessions of another user as admin", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{sessionrepo: sessionrepo}
+		adminctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
+
+		sessionrepo.On("FindActiveByUserID", adminctx, uid).Return([]*session.Session{other}, nil).Once()
+
+		res, err := sv.ListSessions(adminctx, &userv1.ListSessionsRequest{UserId: ptr.Of(uid)})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Sessions, 1)
+		assert.False(t, res.Sessions[0].Current)
+	})
+
+	t.Run("sessions of another user without permission", func(t *testing.T) {
+		sv := &grpcService{sessionrepo: new(mockSessionRepository)}
+
+		res, err := sv.ListSessions(ctx, &userv1.ListSessionsRequest{UserId: ptr.Of("other")})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.ListSessions(context.Background(), &userv1.ListSessionsRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid, Roles: []string{"user"}})
+
+	t.Run("success", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{sessionrepo: sessionrepo, rtrepo: rtrepo}
+		s := session.NewSession(uid, "phone", "", "", time.Hour)
+
+		sessionrepo.On("FindByID", ctx, s.ID.Hex()).Return(s, nil).Once()
+		sessionrepo.On("Revoke", ctx, s.ID).Return(nil).Once()
+		rtrepo.On("RevokeFamily", ctx, s.ID.Hex()).Return(nil).Once()
+
+		res, err := sv.RevokeSession(ctx, &userv1.RevokeSessionRequest{Id: s.ID.Hex()})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		sessionrepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("session of another user", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{sessionrepo: sessionrepo}
+		s := session.NewSession("other", "phone", "", "", time.Hour)
+
+		sessionrepo.On("FindByID", ctx, s.ID.Hex()).Return(s, nil).Once()
+
+		res, err := sv.RevokeSession(ctx, &userv1.RevokeSessionRequest{Id: s.ID.Hex()})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errSessionNotFound, err)
+		sessionrepo.AssertNotCalled(t, "Revoke")
+	})
+
+	t.Run("session of another user as admin", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{sessionrepo: sessionrepo, rtrepo: rtrepo}
+		adminctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
+		s := session.NewSession(uid, "phone", "", "", time.Hour)
+
+		sessionrepo.On("FindByID", adminctx, s.ID.Hex()).Return(s, nil).Once()
+		sessionrepo.On("Revoke", adminctx, s.ID).Return(nil).Once()
+		rtrepo.On("RevokeFamily", adminctx, s.ID.Hex()).Return(nil).Once()
+
+		res, err := sv.RevokeSession(adminctx, &userv1.RevokeSessionRequest{Id: s.ID.Hex()})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		sv := &grpcService{sessionrepo: sessionrepo}
+
+		sessionrepo.On("FindByID", ctx, "sid").Return(nil, session.ErrSessionNotFound).Once()
+
+		res, err := sv.RevokeSession(ctx, &userv1.RevokeSessionRequest{Id: "sid"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errSessionNotFound, err)
+	})
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	uid := primitive.NewObjectID().Hex()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid, Roles: []string{"user"}})
+
+	t.Run("own sessions", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{sessionrepo: sessionrepo, rtrepo: rtrepo}
+
+		sessionrepo.On("RevokeByUserID", ctx, uid).Return(nil).Once()
+		rtrepo.On("RevokeByUserID", ctx, uid).Return(nil).Once()
+
+		res, err := sv.RevokeAllSessions(ctx, &userv1.RevokeAllSessionsRequest{})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		sessionrepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+	})
+
+	t.Run("sessions of another user as admin", func(t *testing.T) {
+		sessionrepo := new(mockSessionRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		sv := &grpcService{sessionrepo: sessionrepo, rtrepo: rtrepo}
+		adminctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
+
+		sessionrepo.On("RevokeByUserID", adminctx, uid).Return(nil).Once()
+		rtrepo.On("RevokeByUserID", adminctx, uid).Return(nil).Once()
+
+		res, err := sv.RevokeAllSessions(adminctx, &userv1.RevokeAllSessionsRequest{UserId: ptr.Of(uid)})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("sessions of another user without permission", func(t *testing.T) {
+		sv := &grpcService{}
+
+		res, err := sv.RevokeAllSessions(ctx, &userv1.RevokeAllSessionsRequest{UserId: ptr.Of("other")})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
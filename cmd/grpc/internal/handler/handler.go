@@ -2,25 +2,129 @@ package handler
 
 import (
 	"github.com/google/wire"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/apikey"
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/auth"
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/user"
+	"github.com/nuea/backend-golang-test/internal/rbac"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
 	"google.golang.org/grpc"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type GrpcServices struct {
 	userv1.UserServiceServer
 	userv1.AuthServiceServer
+	userv1.APIKeyServiceServer
+}
+
+// PublicMethods lists the RPCs that can be called without a bearer token.
+// Every other method requires a valid access token in the "authorization"
+// metadata, or an API key in the "x-api-key" metadata.
+var PublicMethods = map[string]bool{
+	userv1.UserService_CreateUser_FullMethodName:                true,
+	userv1.UserService_VerifyEmail_FullMethodName:               true,
+	userv1.UserService_ResendVerificationEmail_FullMethodName:   true,
+	userv1.AuthService_Login_FullMethodName:                     true,
+	userv1.AuthService_RefreshToken_FullMethodName:              true,
+	userv1.AuthService_Logout_FullMethodName:                    true,
+	userv1.AuthService_IsTokenRevoked_FullMethodName:            true,
+	userv1.AuthService_ForgotPassword_FullMethodName:            true,
+	userv1.AuthService_ResetPassword_FullMethodName:             true,
+	userv1.AuthService_VerifyMFA_FullMethodName:                 true,
+	userv1.AuthService_BeginOIDCLogin_FullMethodName:            true,
+	userv1.AuthService_CompleteOIDCLogin_FullMethodName:         true,
+	userv1.APIKeyService_VerifyAPIKey_FullMethodName:            true,
+	userv1.APIKeyService_VerifyClientCredentials_FullMethodName: true,
+	healthgrpc.Health_Check_FullMethodName:                      true,
+}
+
+// MethodPermissions declares the permission each protected RPC requires.
+// Methods missing from both maps only require a valid access token, and
+// cannot be called with a client token, which acts for no user.
+var MethodPermissions = map[string]rbac.Permission{
+	userv1.UserService_GetUser_FullMethodName:    rbac.PermissionUsersRead,
+	userv1.UserService_GetUsers_FullMethodName:   rbac.PermissionUsersRead,
+	userv1.UserService_UpdateUser_FullMethodName: rbac.PermissionUsersWrite,
+	userv1.UserService_DeleteUser_FullMethodName: rbac.PermissionUsersDelete,
+	userv1.UserService_GrantRole_FullMethodName:  rbac.PermissionRolesManage,
+	userv1.UserService_RevokeRole_FullMethodName: rbac.PermissionRolesManage,
+	userv1.UserService_UnlockUser_FullMethodName: rbac.PermissionUsersUnlock,
+
+	userv1.UserService_GetCurrentUser_FullMethodName:    rbac.PermissionUsersRead,
+	userv1.UserService_UpdateCurrentUser_FullMethodName: rbac.PermissionUsersWrite,
+	userv1.UserService_DeleteCurrentUser_FullMethodName: rbac.PermissionUsersDelete,
+
+	userv1.AuthService_IntrospectToken_FullMethodName: rbac.PermissionTokensIntrospect,
+	userv1.AuthService_ImpersonateUser_FullMethodName: rbac.PermissionUsersImpersonate,
+
+	userv1.APIKeyService_CreateServiceAccount_FullMethodName: rbac.PermissionServiceAccountsManage,
+	userv1.APIKeyService_ListServiceAccounts_FullMethodName:  rbac.PermissionServiceAccountsManage,
+	userv1.APIKeyService_DeleteServiceAccount_FullMethodName: rbac.PermissionServiceAccountsManage,
+	userv1.APIKeyService_CreateOAuthClient_FullMethodName:    rbac.PermissionOAuthClientsManage,
+	userv1.APIKeyService_ListOAuthClients_FullMethodName:     rbac.PermissionOAuthClientsManage,
+	userv1.APIKeyService_DeleteOAuthClient_FullMethodName:    rbac.PermissionOAuthClientsManage,
+}
+
+// OwnerMethods may also be called without the declared permission when the
+// request id is the caller's own user id.
+var OwnerMethods = map[string]bool{
+	userv1.UserService_GetUser_FullMethodName:    true,
+	userv1.UserService_UpdateUser_FullMethodName: true,
+	userv1.UserService_DeleteUser_FullMethodName: true,
+}
+
+// CurrentUserMethods act on the caller's own account. Users call them
+// without the declared permission, an API key only within its scopes.
+var CurrentUserMethods = map[string]bool{
+	userv1.UserService_GetCurrentUser_FullMethodName:    true,
+	userv1.UserService_UpdateCurrentUser_FullMethodName: true,
+	userv1.UserService_DeleteCurrentUser_FullMethodName: true,
+}
+
+// SessionOnlyMethods cannot be called with an API key, they manage the
+// credentials of the account and need the user's own access token.
+var SessionOnlyMethods = map[string]bool{
+	userv1.AuthService_ChangePassword_FullMethodName:    true,
+	userv1.AuthService_EnrollMFA_FullMethodName:         true,
+	userv1.AuthService_ConfirmMFA_FullMethodName:        true,
+	userv1.AuthService_DisableMFA_FullMethodName:        true,
+	userv1.AuthService_ListSessions_FullMethodName:      true,
+	userv1.AuthService_RevokeSession_FullMethodName:     true,
+	userv1.AuthService_RevokeAllSessions_FullMethodName: true,
+	userv1.AuthService_ImpersonateUser_FullMethodName:   true,
+	userv1.APIKeyService_CreateAPIKey_FullMethodName:    true,
+}
+
+// ImpersonationBlockedMethods cannot be called with an impersonation token,
+// the actor must not take over or delete the account it acts as. Updates are
+// blocked too, a changed email would let the actor reset the password.
+var ImpersonationBlockedMethods = map[string]bool{
+	userv1.UserService_UpdateUser_FullMethodName:        true,
+	userv1.UserService_DeleteUser_FullMethodName:        true,
+	userv1.UserService_UpdateCurrentUser_FullMethodName: true,
+	userv1.UserService_DeleteCurrentUser_FullMethodName: true,
+	userv1.AuthService_ChangePassword_FullMethodName:    true,
+	userv1.AuthService_EnrollMFA_FullMethodName:         true,
+	userv1.AuthService_ConfirmMFA_FullMethodName:        true,
+	userv1.AuthService_DisableMFA_FullMethodName:        true,
+	userv1.AuthService_ListSessions_FullMethodName:      true,
+	userv1.AuthService_RevokeSession_FullMethodName:     true,
+	userv1.AuthService_RevokeAllSessions_FullMethodName: true,
+	userv1.AuthService_ImpersonateUser_FullMethodName:   true,
+	userv1.APIKeyService_CreateAPIKey_FullMethodName:    true,
+	userv1.APIKeyService_RevokeAPIKey_FullMethodName:    true,
 }
 
 func RegisterGrpcServices(sv *grpc.Server, h *GrpcServices) {
 	userv1.RegisterUserServiceServer(sv, h)
 	userv1.RegisterAuthServiceServer(sv, h)
+	userv1.RegisterAPIKeyServiceServer(sv, h)
 }
 
 var HandlerSet = wire.NewSet(
 	user.ProvideUserGRPCService,
 	auth.ProvideAuthGRPCService,
+	apikey.ProvideAPIKeyGRPCService,
 
 	wire.Struct(new(GrpcServices), "*"),
 )
@@ -0,0 +1,21 @@
+package user
+
+import (
+	"strconv"
+
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/userstore"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+)
+
+func userETag(u *user.User) string {
+	return strconv.FormatInt(u.Version, 10)
+}
+
+// checkETag refuses to change a user the caller read at another version.
+// Without an etag, the change is unconditional.
+func checkETag(u *user.User, etag string) error {
+	if etag != "" && etag != userETag(u) {
+		return userstore.ErrUserModified
+	}
+	return nil
+}
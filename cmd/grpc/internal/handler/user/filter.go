@@ -0,0 +1,72 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/types"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newUserFilter(req *userv1.GetUsersRequest) (*user.UserFilter, error) {
+	f := &user.UserFilter{
+		NamePrefix:    req.GetNamePrefix(),
+		NameContains:  req.GetNameContains(),
+		EmailPrefix:   req.GetEmailPrefix(),
+		EmailContains: req.GetEmailContains(),
+		Query:         req.GetQ(),
+	}
+	f.CreatedBy = req.CreatedBy
+	if req.Name != nil {
+		f.Name = *req.Name
+	}
+	if req.Email != nil {
+		email, err := types.NewEmail(*req.Email)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		f.Email = email
+	}
+
+	var err error
+	if f.CreatedAfter, f.CreatedBefore, err = timeRange("created", req.CreatedAfter, req.CreatedBefore); err != nil {
+		return nil, err
+	}
+	if f.UpdatedAfter, f.UpdatedBefore, err = timeRange("updated", req.UpdatedAfter, req.UpdatedBefore); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// timeRange returns the bounds of the <field>_after and <field>_before
+// range, which must not be empty.
+func timeRange(field string, after, before *timestamppb.Timestamp) (*time.Time, *time.Time, error) {
+	a, err := optionalTime(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := optionalTime(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if a != nil && b != nil && !a.Before(*b) {
+		return nil, nil, status.Error(codes.InvalidArgument, fmt.Sprintf("%s_after must be before %s_before.", field, field))
+	}
+	return a, b, nil
+}
+
+func optionalTime(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
@@ -12,12 +12,19 @@ func mapGRPCUser(user *user.User) (*userv1.User, error) {
 	}
 
 	response := &userv1.User{
-		Id:        user.ID.Hex(),
-		Name:      user.Name,
-		Email:     string(user.Email),
-		CreatedBy: user.CreatedBy,
-		CreatedAt: timestamppb.New(user.CreatedAt),
-		UpdatedAt: timestamppb.New(user.UpdatedAt),
+		Id:         user.ID.Hex(),
+		Name:       user.Name,
+		Email:      string(user.Email),
+		CreatedBy:  user.CreatedBy,
+		CreatedAt:  timestamppb.New(user.CreatedAt),
+		UpdatedAt:  timestamppb.New(user.UpdatedAt),
+		Roles:      user.Roles,
+		MfaEnabled: user.MFAEnabled(),
+		Etag:       userETag(user),
+	}
+
+	if user.EmailVerifiedAt != nil {
+		response.EmailVerifiedAt = timestamppb.New(*user.EmailVerifiedAt)
 	}
 
 	if user.DeletedAt != nil {
@@ -0,0 +1,121 @@
+package user
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+var (
+	errInvalidPageToken = status.Error(codes.InvalidArgument, "Page token is invalid.")
+	errInvalidOrderBy   = status.Error(codes.InvalidArgument, "Order by must be created_at or id, optionally followed by asc or desc.")
+)
+
+// pageToken is the cursor handed to clients. It is opaque to them, and
+// remembers the order it was issued for so it cannot be reused with another.
+type pageToken struct {
+	OrderBy   string    `json:"o"`
+	ID        string    `json:"i"`
+	CreatedAt time.Time `json:"c"`
+}
+
+// newPage reads the page of a GetUsers request. The page is one user larger
+// than requested, the extra user only tells whether a next page exists.
+func newPage(req *userv1.GetUsersRequest) (*user.Page, string, error) {
+	if req.PageSize < 0 {
+		return nil, "", status.Error(codes.InvalidArgument, "Page size must not be negative.")
+	}
+	size := int64(req.PageSize)
+	if size == 0 {
+		size = defaultPageSize
+	}
+	size = min(size, maxPageSize)
+
+	orderBy, page, err := parseOrderBy(req.OrderBy)
+	if err != nil {
+		return nil, "", err
+	}
+	page.Size = size + 1
+
+	if req.PageToken != "" {
+		cursor, err := decodePageToken(req.PageToken, orderBy)
+		if err != nil {
+			return nil, "", err
+		}
+		page.After = cursor
+	}
+	return page, orderBy, nil
+}
+
+// parseOrderBy returns the order in its canonical form, so that "created_at"
+// and "created_at asc" share page tokens.
+func parseOrderBy(orderBy string) (string, *user.Page, error) {
+	fields := strings.Fields(strings.ToLower(orderBy))
+	if len(fields) == 0 {
+		return string(user.SortByCreatedAt), &user.Page{SortBy: user.SortByCreatedAt}, nil
+	}
+	if len(fields) > 2 {
+		return "", nil, errInvalidOrderBy
+	}
+
+	page := &user.Page{}
+	switch fields[0] {
+	case "created_at":
+		page.SortBy = user.SortByCreatedAt
+	case "id":
+		page.SortBy = user.SortByID
+	default:
+		return "", nil, errInvalidOrderBy
+	}
+	if len(fields) == 2 {
+		switch fields[1] {
+		case "asc":
+		case "desc":
+			page.Desc = true
+		default:
+			return "", nil, errInvalidOrderBy
+		}
+	}
+
+	if page.Desc {
+		return fields[0] + " desc", page, nil
+	}
+	return fields[0], page, nil
+}
+
+func encodePageToken(orderBy string, c *user.Cursor) (string, error) {
+	b, err := json.Marshal(&pageToken{OrderBy: orderBy, ID: c.ID.Hex(), CreatedAt: c.CreatedAt})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodePageToken(s, orderBy string) (*user.Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+
+	var t pageToken
+	if err := json.Unmarshal(b, &t); err != nil || t.OrderBy != orderBy {
+		return nil, errInvalidPageToken
+	}
+	id, err := primitive.ObjectIDFromHex(t.ID)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+	return &user.Cursor{ID: id, CreatedAt: t.CreatedAt}, nil
+}
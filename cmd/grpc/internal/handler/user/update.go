@@ -0,0 +1,97 @@
+package user
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pathName  = "name"
+	pathEmail = "email"
+)
+
+var (
+	// updatablePaths are the fields UpdateUser changes, in the order "*"
+	// expands to.
+	updatablePaths = []string{pathName, pathEmail}
+	// immutablePaths are the other fields of a User. They are set by the
+	// server or by their own RPCs, like GrantRole.
+	immutablePaths = []string{"id", "created_by", "created_at", "updated_at", "deleted_at", "email_verified_at", "roles", "mfa_enabled"}
+)
+
+// updatePaths returns the fields an UpdateUserRequest changes. Every path
+// that cannot be updated, and every required field the request would clear,
+// is reported at once.
+func updatePaths(req *userv1.UpdateUserRequest) ([]string, error) {
+	mask := req.GetUpdateMask().GetPaths()
+	if len(mask) == 0 {
+		var paths []string
+		if req.Name != nil {
+			paths = append(paths, pathName)
+		}
+		if req.Email != nil {
+			paths = append(paths, pathEmail)
+		}
+		return paths, nil
+	}
+	if slices.Equal(mask, []string{"*"}) {
+		mask = updatablePaths
+	}
+
+	var paths []string
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, p := range mask {
+		switch {
+		case slices.Contains(updatablePaths, p):
+			if !slices.Contains(paths, p) {
+				paths = append(paths, p)
+			}
+		case slices.Contains(immutablePaths, p):
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       p,
+				Reason:      "immutable_path",
+				Description: fmt.Sprintf("%s cannot be updated.", p),
+			})
+		default:
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       p,
+				Reason:      "unknown_path",
+				Description: fmt.Sprintf("%s is not a field of the user.", p),
+			})
+		}
+	}
+
+	for _, p := range paths {
+		if (p == pathName && req.GetName() == "") || (p == pathEmail && req.GetEmail() == "") {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       p,
+				Reason:      "required",
+				Description: fmt.Sprintf("%s cannot be cleared.", p),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, invalidPathsError(violations)
+	}
+	return paths, nil
+}
+
+func invalidPathsError(violations []*errdetails.BadRequest_FieldViolation) error {
+	fields := make([]string, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, v.Field)
+	}
+
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("Update mask has invalid paths: %s.", strings.Join(fields, ", ")))
+	if withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -2,11 +2,28 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
 	"time"
 
 	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/userstore"
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/client/mailer"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
 	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/emailverification"
+	"github.com/nuea/backend-golang-test/internal/repository/loginattempt"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/service/password"
 	"github.com/nuea/backend-golang-test/internal/types"
 	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
@@ -16,12 +33,30 @@ import (
 
 type grpcService struct {
 	userv1.UnimplementedUserServiceServer
-	userrepo user.UserRepository
+	cfg         *config.AuthConfig
+	userrepo    user.UserRepository
+	evrepo      emailverification.EmailVerificationRepository
+	attemptrepo loginattempt.LoginAttemptRepository
+	sessionrepo session.SessionRepository
+	rtrepo      refreshtoken.RefreshTokenRepository
+	keyrepo     apikey.APIKeyRepository
+	mailer      mailer.Mailer
+	policy      password.PasswordPolicy
+	hasher      password.Hasher
 }
 
-func ProvideUserGRPCService(repo *repository.Repository) (userv1.UserServiceServer, error) {
+func ProvideUserGRPCService(cfg *config.AppConfig, repo *repository.Repository, c *client.Clients, policy password.PasswordPolicy, hasher password.Hasher) (userv1.UserServiceServer, error) {
 	return &grpcService{
-		userrepo: repo.UserRepository,
+		cfg:         &cfg.Auth,
+		userrepo:    repo.UserRepository,
+		evrepo:      repo.EmailVerificationRepository,
+		attemptrepo: repo.LoginAttemptRepository,
+		sessionrepo: repo.SessionRepository,
+		rtrepo:      repo.RefreshTokenRepository,
+		keyrepo:     repo.APIKeyRepository,
+		mailer:      c.Mailer,
+		policy:      policy,
+		hasher:      hasher,
 	}, nil
 }
 
@@ -35,13 +70,17 @@ func (g *grpcService) CreateUser(ctx context.Context, req *userv1.CreateUserRequ
 		}
 	}
 
+	if err := g.policy.Validate(ctx, req.Password); err != nil {
+		return nil, err
+	}
+
 	newuser := user.NewUser()
 	newuser.Name = req.Name
 	newuser.Email = email
 
-	newuser.Password, err = types.NewHashString(req.Password).Hash()
+	newuser.Password, err = g.hasher.Hash(ctx, req.Password)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, err
 	}
 
 	if req.CreatedBy != nil {
@@ -52,6 +91,16 @@ func (g *grpcService) CreateUser(ctx context.Context, req *userv1.CreateUserRequ
 	if err := g.userrepo.InsertOne(ctx, newuser); err != nil {
 		return nil, err
 	}
+
+	// InsertOne does not return the generated id, so the user is loaded
+	// again to address the token.
+	if email != "" {
+		if created, err := g.userrepo.FindByEmail(ctx, email); err != nil {
+			log.Println("Unable to send the verification email:", err)
+		} else if err := g.sendVerificationEmail(ctx, created); err != nil {
+			log.Println("Unable to send the verification email:", err)
+		}
+	}
 	return &userv1.CreateUserResponse{}, nil
 }
 
@@ -69,54 +118,80 @@ func (g *grpcService) GetUser(ctx context.Context, req *userv1.GetUserRequest) (
 }
 
 func (g *grpcService) GetUsers(ctx context.Context, req *userv1.GetUsersRequest) (*userv1.GetUsersResponse, error) {
-	f := &user.UserFilter{}
-	if req.Name != nil {
-		f.Name = *req.Name
-	}
-	if req.Email != nil {
-		email, err := types.NewEmail(*req.Email)
-		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		}
-		f.Email = email
+	f, err := newUserFilter(req)
+	if err != nil {
+		return nil, err
 	}
-	users, err := g.userrepo.Find(ctx, f)
+	page, orderBy, err := newPage(req)
 	if err != nil {
 		return nil, err
 	}
-	datas, err := util.MapToSlice(mapGRPCUser, users)
+
+	users, err := g.userrepo.Find(ctx, f, page)
 	if err != nil {
 		return nil, err
 	}
-	return &userv1.GetUsersResponse{
-		Data: datas,
-	}, nil
+
+	res := &userv1.GetUsersResponse{}
+	if int64(len(users)) == page.Size {
+		users = users[:len(users)-1]
+		if res.NextPageToken, err = encodePageToken(orderBy, user.CursorOf(users[len(users)-1])); err != nil {
+			return nil, err
+		}
+	}
+	if res.Data, err = util.MapToSlice(mapGRPCUser, users); err != nil {
+		return nil, err
+	}
+
+	if req.IncludeTotal {
+		total, err := g.userrepo.CountByFilter(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		res.Total = &total
+	}
+	return res, nil
 }
 
 func (g *grpcService) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.UpdateUserResponse, error) {
-	user, err := g.userrepo.FindByID(ctx, req.Id)
+	paths, err := updatePaths(req)
 	if err != nil {
 		return nil, err
 	}
 
-	if req.Name != nil {
-		user.Name = *req.Name
+	user, err := g.userrepo.FindByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canManage(ctx, req.Id, user); err != nil {
+		return nil, err
+	}
+	if err := checkETag(user, req.Etag); err != nil {
+		return nil, err
 	}
 
-	if req.Email != nil {
-		email, err := types.NewEmail(*req.Email)
-		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
+	for _, p := range paths {
+		switch p {
+		case pathName:
+			user.Name = req.GetName()
+		case pathEmail:
+			email, err := types.NewEmail(req.GetEmail())
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			if email != user.Email {
+				user.EmailVerifiedAt = nil
+			}
+			user.Email = email
 		}
-		user.Email = email
 	}
 	user.UpdatedAt = time.Now().UTC()
 
-	if err := g.userrepo.ReplaceOne(ctx, req.Id, user); err != nil {
+	if err := userstore.Replace(ctx, g.userrepo, req.Id, user); err != nil {
 		return nil, err
 	}
 
-	return &userv1.UpdateUserResponse{}, nil
+	return &userv1.UpdateUserResponse{Etag: userETag(user)}, nil
 }
 
 func (g *grpcService) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
@@ -124,13 +199,252 @@ func (g *grpcService) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequ
 	if err != nil {
 		return nil, err
 	}
+	if err := canManage(ctx, req.Id, user); err != nil {
+		return nil, err
+	}
+	if err := checkETag(user, req.Etag); err != nil {
+		return nil, err
+	}
 
 	user.UpdatedAt = time.Now().UTC()
 	user.DeletedAt = ptr.Time(time.Now().UTC())
 
-	if err := g.userrepo.ReplaceOne(ctx, req.Id, user); err != nil {
+	if err := userstore.Replace(ctx, g.userrepo, req.Id, user); err != nil {
+		return nil, err
+	}
+	if err := g.revokeCredentials(ctx, req.Id); err != nil {
 		return nil, err
 	}
 
 	return &userv1.DeleteUserResponse{}, nil
 }
+
+// revokeCredentials ends the sessions, the refresh tokens and the API keys of
+// a deleted user, its access tokens are rejected on their next use.
+func (g *grpcService) revokeCredentials(ctx context.Context, id string) error {
+	if err := g.sessionrepo.RevokeByUserID(ctx, id); err != nil {
+		return err
+	}
+	if err := g.rtrepo.RevokeByUserID(ctx, id); err != nil {
+		return err
+	}
+	return g.keyrepo.RevokeByOwner(ctx, apikey.OwnerTypeUser, id)
+}
+
+// canManage refuses to change a user who holds a permission the caller
+// lacks, e.g. support changing the email of an admin. Users may always
+// change their own account.
+func canManage(ctx context.Context, id string, u *user.User) error {
+	claims := identity.ClaimsFromContext(ctx)
+	if claims == nil {
+		return status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+	if claims.UserID == id || claims.CanManage(u.Roles) {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "Permission denied.")
+}
+
+func (g *grpcService) VerifyEmail(ctx context.Context, req *userv1.VerifyEmailRequest) (*userv1.VerifyEmailResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "Verification token is required.")
+	}
+
+	invalid := status.Error(codes.InvalidArgument, "Verification token is invalid or expired.")
+	evt, err := g.evrepo.FindByToken(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, emailverification.ErrEmailVerificationTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	if evt.UsedAt != nil || evt.IsExpired() {
+		return nil, invalid
+	}
+
+	user, err := g.userrepo.FindByID(ctx, evt.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletedAt != nil || user.Email != evt.Email {
+		return nil, invalid
+	}
+
+	if err := g.evrepo.MarkUsed(ctx, evt.ID); err != nil {
+		if errors.Is(err, emailverification.ErrEmailVerificationTokenUsed) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = ptr.Time(time.Now().UTC())
+		user.UpdatedAt = time.Now().UTC()
+
+		if err := userstore.Replace(ctx, g.userrepo, evt.UserID, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return &userv1.VerifyEmailResponse{}, nil
+}
+
+// ResendVerificationEmail succeeds without sending anything for unknown or
+// already verified addresses, so it cannot be used to probe for accounts.
+func (g *grpcService) ResendVerificationEmail(ctx context.Context, req *userv1.ResendVerificationEmailRequest) (*userv1.ResendVerificationEmailResponse, error) {
+	email, err := types.NewEmail(req.Email)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	user, err := g.userrepo.FindByEmail(ctx, email)
+	if err != nil || user.DeletedAt != nil || user.EmailVerifiedAt != nil {
+		return &userv1.ResendVerificationEmailResponse{}, nil
+	}
+
+	sent, err := g.evrepo.CountSince(ctx, user.ID.Hex(), time.Now().UTC().Add(-g.cfg.EmailVerificationResendWindow))
+	if err != nil {
+		return nil, err
+	}
+	if sent >= g.cfg.EmailVerificationResendLimit {
+		return nil, status.Error(codes.ResourceExhausted, "Too many verification emails, try again later.")
+	}
+
+	if err := g.sendVerificationEmail(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &userv1.ResendVerificationEmailResponse{}, nil
+}
+
+func (g *grpcService) GetCurrentUser(ctx context.Context, req *userv1.GetCurrentUserRequest) (*userv1.GetCurrentUserResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	res, err := g.GetUser(ctx, &userv1.GetUserRequest{Id: uid})
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.GetCurrentUserResponse{User: res.User}, nil
+}
+
+func (g *grpcService) UpdateCurrentUser(ctx context.Context, req *userv1.UpdateCurrentUserRequest) (*userv1.UpdateCurrentUserResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	res, err := g.UpdateUser(ctx, &userv1.UpdateUserRequest{
+		Id:         uid,
+		Name:       req.Name,
+		Email:      req.Email,
+		UpdateMask: req.UpdateMask,
+		Etag:       req.Etag,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &userv1.UpdateCurrentUserResponse{Etag: res.Etag}, nil
+}
+
+func (g *grpcService) DeleteCurrentUser(ctx context.Context, req *userv1.DeleteCurrentUserRequest) (*userv1.DeleteCurrentUserResponse, error) {
+	uid := identity.UserIDFromContext(ctx)
+	if uid == "" {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	if _, err := g.DeleteUser(ctx, &userv1.DeleteUserRequest{Id: uid, Etag: req.Etag}); err != nil {
+		return nil, err
+	}
+	return &userv1.DeleteCurrentUserResponse{}, nil
+}
+
+func (g *grpcService) GrantRole(ctx context.Context, req *userv1.GrantRoleRequest) (*userv1.GrantRoleResponse, error) {
+	role, err := rbac.ParseRole(req.Role)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	user, err := g.userrepo.FindByID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(user.Roles, string(role)) {
+		user.Roles = append(user.Roles, string(role))
+		user.UpdatedAt = time.Now().UTC()
+
+		if err := userstore.Replace(ctx, g.userrepo, req.UserId, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return &userv1.GrantRoleResponse{Roles: user.Roles}, nil
+}
+
+func (g *grpcService) RevokeRole(ctx context.Context, req *userv1.RevokeRoleRequest) (*userv1.RevokeRoleResponse, error) {
+	role, err := rbac.ParseRole(req.Role)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// An admin removing their own admin role could leave nobody able to
+	// manage roles.
+	if claims := identity.ClaimsFromContext(ctx); claims != nil && claims.UserID == req.UserId && role == rbac.RoleAdmin {
+		return nil, status.Error(codes.FailedPrecondition, "Cannot revoke your own admin role.")
+	}
+
+	user, err := g.userrepo.FindByID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if i := slices.Index(user.Roles, string(role)); i >= 0 {
+		user.Roles = slices.Delete(user.Roles, i, i+1)
+		user.UpdatedAt = time.Now().UTC()
+
+		if err := userstore.Replace(ctx, g.userrepo, req.UserId, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return &userv1.RevokeRoleResponse{Roles: user.Roles}, nil
+}
+
+// UnlockUser clears the failed login counter of the account, lifting a
+// lockout. Lockouts of the client IP are left to expire.
+func (g *grpcService) UnlockUser(ctx context.Context, req *userv1.UnlockUserRequest) (*userv1.UnlockUserResponse, error) {
+	user, err := g.userrepo.FindByID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.attemptrepo.DeleteByKey(ctx, loginattempt.UserKey(user.ID.Hex())); err != nil {
+		return nil, err
+	}
+
+	return &userv1.UnlockUserResponse{}, nil
+}
+
+func (g *grpcService) sendVerificationEmail(ctx context.Context, user *user.User) error {
+	raw, err := util.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := g.evrepo.InsertOne(ctx, emailverification.NewEmailVerificationToken(user.ID.Hex(), user.Email, raw, g.cfg.EmailVerificationTTL)); err != nil {
+		return err
+	}
+
+	link := g.cfg.EmailVerificationURL + "?token=" + url.QueryEscape(raw)
+	return g.mailer.Send(ctx, &mailer.Message{
+		To:      string(user.Email),
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\r\n\r\nUse the link below to verify your email. It expires in %s.\r\n\r\n%s",
+			user.Name, g.cfg.EmailVerificationTTL, link),
+	})
+}
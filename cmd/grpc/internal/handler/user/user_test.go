@@ -3,18 +3,36 @@ package user
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler/userstore"
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/client/mailer"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
 	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/emailverification"
+	"github.com/nuea/backend-golang-test/internal/repository/loginattempt"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/service/password"
+	"github.com/nuea/backend-golang-test/internal/service/token"
 	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type mockUserRepository struct {
@@ -35,23 +53,135 @@ func (m *mockUserRepository) FindByID(ctx context.Context, id string) (*user.Use
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
-func (m *mockUserRepository) Find(ctx context.Context, filter *user.UserFilter) ([]*user.User, error) {
-	args := m.Called(ctx, filter)
+func (m *mockUserRepository) FindByEmail(ctx context.Context, email types.Email) (*user.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *mockUserRepository) Find(ctx context.Context, filter *user.UserFilter, page *user.Page) ([]*user.User, error) {
+	args := m.Called(ctx, filter, page)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*user.User), args.Error(1)
 }
 
+func (m *mockUserRepository) CountByFilter(ctx context.Context, filter *user.UserFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockUserRepository) ReplaceOne(ctx context.Context, id string, u *user.User) error {
 	args := m.Called(ctx, id, u)
 	return args.Error(0)
 }
 
+type mockEmailVerificationRepository struct {
+	mock.Mock
+	emailverification.EmailVerificationRepository
+}
+
+func (m *mockEmailVerificationRepository) InsertOne(ctx context.Context, token *emailverification.EmailVerificationToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *mockEmailVerificationRepository) FindByToken(ctx context.Context, token string) (*emailverification.EmailVerificationToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emailverification.EmailVerificationToken), args.Error(1)
+}
+
+func (m *mockEmailVerificationRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockEmailVerificationRepository) CountSince(ctx context.Context, userID string, since time.Time) (int64, error) {
+	args := m.Called(ctx, userID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockLoginAttemptRepository struct {
+	mock.Mock
+	loginattempt.LoginAttemptRepository
+}
+
+func (m *mockLoginAttemptRepository) DeleteByKey(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+type mockSessionRepository struct {
+	mock.Mock
+	session.SessionRepository
+}
+
+func (m *mockSessionRepository) RevokeByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type mockRefreshTokenRepository struct {
+	mock.Mock
+	refreshtoken.RefreshTokenRepository
+}
+
+func (m *mockRefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type mockAPIKeyRepository struct {
+	mock.Mock
+	apikey.APIKeyRepository
+}
+
+func (m *mockAPIKeyRepository) RevokeByOwner(ctx context.Context, ownerType, ownerID string) error {
+	args := m.Called(ctx, ownerType, ownerID)
+	return args.Error(0)
+}
+
+type mockPasswordPolicy struct {
+	mock.Mock
+	password.PasswordPolicy
+}
+
+func (m *mockPasswordPolicy) Validate(ctx context.Context, pwd string, recentHashes ...string) error {
+	args := m.Called(ctx, pwd, recentHashes)
+	return args.Error(0)
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+var testhasher, _, _ = password.ProvideHasher(&config.AppConfig{PasswordHash: config.PasswordHashConfig{
+	Algorithm:  password.AlgorithmBcrypt,
+	BcryptCost: bcrypt.MinCost,
+}})
+
+var testcfg = &config.AuthConfig{
+	EmailVerificationTTL:          time.Hour,
+	EmailVerificationURL:          "http://localhost:8080/verify-email",
+	EmailVerificationResendLimit:  3,
+	EmailVerificationResendWindow: time.Hour,
+}
+
 func TestProvideUserGRPCService(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv, err := ProvideUserGRPCService(&repository.Repository{UserRepository: repo})
+		sv, err := ProvideUserGRPCService(&config.AppConfig{}, &repository.Repository{UserRepository: repo}, &client.Clients{Mailer: new(mockMailer)}, new(mockPasswordPolicy), testhasher)
 
 		assert.NotNil(t, sv)
 		assert.NoError(t, err)
@@ -63,26 +193,67 @@ func TestCreateUser(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		evrepo := new(mockEmailVerificationRepository)
+		ml := new(mockMailer)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo, mailer: ml, policy: policy, hasher: testhasher}
 		req := &userv1.CreateUserRequest{
 			Name:      "test",
 			Email:     "test@example.com",
 			Password:  "password",
 			CreatedBy: ptr.String("service"),
 		}
+		uid := primitive.NewObjectID()
 
-		repo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		policy.On("Validate", ctx, req.Password, []string(nil)).Return(nil).Once()
+
+		repo.On("InsertOne", ctx, mock.MatchedBy(func(u *user.User) bool {
+			return u.EmailVerifiedAt == nil
+		})).Return(nil).Once()
+		repo.On("FindByEmail", ctx, types.Email(req.Email)).Return(&user.User{ID: uid, Email: types.Email(req.Email)}, nil).Once()
+		evrepo.On("InsertOne", ctx, mock.MatchedBy(func(evt *emailverification.EmailVerificationToken) bool {
+			return evt.UserID == uid.Hex() && evt.Email == types.Email(req.Email)
+		})).Return(nil).Once()
+		ml.On("Send", ctx, mock.MatchedBy(func(msg *mailer.Message) bool {
+			return msg.To == req.Email && strings.Contains(msg.Body, testcfg.EmailVerificationURL+"?token=")
+		})).Return(nil).Once()
 
 		res, err := sv.CreateUser(ctx, req)
 
 		assert.NotNil(t, res)
 		assert.NoError(t, err)
 		repo.AssertExpectations(t)
+		evrepo.AssertExpectations(t)
+		ml.AssertExpectations(t)
+	})
+
+	t.Run("success - mailer error", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		evrepo := new(mockEmailVerificationRepository)
+		ml := new(mockMailer)
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo, mailer: ml, policy: policy, hasher: testhasher}
+		req := &userv1.CreateUserRequest{
+			Name:     "test",
+			Email:    "test@example.com",
+			Password: "password",
+		}
+
+		policy.On("Validate", ctx, req.Password, mock.Anything).Return(nil).Once()
+		repo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		repo.On("FindByEmail", ctx, types.Email(req.Email)).Return(&user.User{ID: primitive.NewObjectID(), Email: types.Email(req.Email)}, nil).Once()
+		evrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		ml.On("Send", ctx, mock.Anything).Return(errors.New("mailer is down")).Once()
+
+		res, err := sv.CreateUser(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
 	})
 
 	t.Run("invalid email", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		sv := &grpcService{userrepo: repo, hasher: testhasher}
 		req := &userv1.CreateUserRequest{
 			Name:     "test",
 			Email:    "invalid email",
@@ -101,32 +272,37 @@ func TestCreateUser(t *testing.T) {
 
 	t.Run("invalid password", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{userrepo: repo, policy: policy, hasher: testhasher}
 		req := &userv1.CreateUserRequest{
 			Name:     "test",
 			Email:    "test@example.com",
-			Password: "0123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789",
+			Password: "",
 		}
+		msgerr := &password.PolicyError{Violations: []password.Violation{{Reason: "min_length", Message: "Password must be at least 8 characters."}}}
+
+		policy.On("Validate", ctx, req.Password, mock.Anything).Return(msgerr).Once()
 
 		res, err := sv.CreateUser(ctx, req)
 
 		assert.Nil(t, res)
-		assert.Error(t, err)
-		st, ok := status.FromError(err)
-		assert.True(t, ok)
-		assert.Equal(t, codes.InvalidArgument, st.Code())
-		assert.Equal(t, "bcrypt: password length exceeds 72 bytes", st.Message())
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.ErrorIs(t, err, msgerr)
+		repo.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
 	})
 
 	t.Run("other error", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		policy := new(mockPasswordPolicy)
+		sv := &grpcService{userrepo: repo, policy: policy, hasher: testhasher}
 		req := &userv1.CreateUserRequest{
 			Name:     "test",
 			Email:    "test@example.com",
 			Password: "password",
 		}
 
+		policy.On("Validate", ctx, req.Password, mock.Anything).Return(nil).Once()
+
 		msgerr := errors.New("internal server error")
 		repo.On("InsertOne", ctx, mock.Anything).Return(msgerr).Once()
 
@@ -200,7 +376,9 @@ func TestGetUsers(t *testing.T) {
 		f.Name = *req.Name
 		f.Email = types.Email(*req.Email)
 
-		repo.On("Find", ctx, f).Return(expuser, nil).Once()
+		page := &user.Page{Size: defaultPageSize + 1, SortBy: user.SortByCreatedAt}
+
+		repo.On("Find", ctx, f, page).Return(expuser, nil).Once()
 
 		res, err := sv.GetUsers(ctx, req)
 
@@ -209,6 +387,8 @@ func TestGetUsers(t *testing.T) {
 		assert.Equal(t, len(expuser), len(res.Data))
 		assert.Equal(t, expuser[0].ID.Hex(), res.Data[0].Id)
 		assert.Equal(t, expuser[1].ID.Hex(), res.Data[1].Id)
+		assert.Empty(t, res.NextPageToken)
+		assert.Nil(t, res.Total)
 		repo.AssertExpectations(t)
 	})
 
@@ -234,7 +414,7 @@ func TestGetUsers(t *testing.T) {
 		req.Email = ptr.String("testtest@example.com")
 
 		msgerr := errors.New("internal server error")
-		repo.On("Find", ctx, mock.Anything).Return(nil, msgerr).Once()
+		repo.On("Find", ctx, mock.Anything, mock.Anything).Return(nil, msgerr).Once()
 
 		res, err := sv.GetUsers(ctx, req)
 
@@ -245,8 +425,165 @@ func TestGetUsers(t *testing.T) {
 	})
 }
 
-func TestUpdateUser(t *testing.T) {
+func TestGetUsersPagination(t *testing.T) {
 	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	expuser := []*user.User{
+		{ID: primitive.NewObjectID(), Name: "first", CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: primitive.NewObjectID(), Name: "second", CreatedAt: now.Add(-time.Minute)},
+		{ID: primitive.NewObjectID(), Name: "third", CreatedAt: now},
+	}
+
+	t.Run("next page token and total", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		req := &userv1.GetUsersRequest{PageSize: 2, OrderBy: "created_at DESC", IncludeTotal: true}
+
+		repo.On("Find", ctx, &user.UserFilter{}, &user.Page{Size: 3, SortBy: user.SortByCreatedAt, Desc: true}).Return(expuser, nil).Once()
+		repo.On("CountByFilter", ctx, &user.UserFilter{}).Return(int64(5), nil).Once()
+
+		res, err := sv.GetUsers(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Data, 2)
+		assert.NotEmpty(t, res.NextPageToken)
+		assert.Equal(t, int64(5), res.GetTotal())
+
+		cursor, err := decodePageToken(res.NextPageToken, "created_at desc")
+		assert.NoError(t, err)
+		assert.Equal(t, user.CursorOf(expuser[1]), cursor)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("following page", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		token, _ := encodePageToken("id", user.CursorOf(expuser[0]))
+		page := &user.Page{Size: 3, SortBy: user.SortByID, After: user.CursorOf(expuser[0])}
+
+		repo.On("Find", ctx, &user.UserFilter{}, page).Return(expuser[1:], nil).Once()
+
+		res, err := sv.GetUsers(ctx, &userv1.GetUsersRequest{PageSize: 2, PageToken: token, OrderBy: "id asc"})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Data, 2)
+		assert.Empty(t, res.NextPageToken)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("page size above the maximum", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("Find", ctx, &user.UserFilter{}, &user.Page{Size: maxPageSize + 1, SortBy: user.SortByCreatedAt}).Return(expuser, nil).Once()
+
+		_, err := sv.GetUsers(ctx, &userv1.GetUsersRequest{PageSize: 1000})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("negative page size", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+
+		res, err := sv.GetUsers(ctx, &userv1.GetUsersRequest{PageSize: -1})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid order by", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+
+		res, err := sv.GetUsers(ctx, &userv1.GetUsersRequest{OrderBy: "email"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidOrderBy, err)
+	})
+
+	t.Run("invalid page token", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+
+		res, err := sv.GetUsers(ctx, &userv1.GetUsersRequest{PageToken: "invalid"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidPageToken, err)
+	})
+
+	t.Run("page token of another order", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+		token, _ := encodePageToken("id", user.CursorOf(expuser[0]))
+
+		res, err := sv.GetUsers(ctx, &userv1.GetUsersRequest{PageToken: token, OrderBy: "created_at"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, errInvalidPageToken, err)
+	})
+}
+
+func TestGetUsersSearch(t *testing.T) {
+	ctx := context.Background()
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 1, 0)
+
+	t.Run("filters", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		req := &userv1.GetUsersRequest{
+			NamePrefix:    ptr.String("Jo"),
+			EmailContains: ptr.String("example"),
+			CreatedAfter:  timestamppb.New(after),
+			UpdatedBefore: timestamppb.New(before),
+			CreatedBy:     ptr.String("admin"),
+			Q:             ptr.String("john"),
+		}
+		f := &user.UserFilter{
+			NamePrefix:    "Jo",
+			EmailContains: "example",
+			CreatedAfter:  &after,
+			UpdatedBefore: &before,
+			Query:         "john",
+		}
+		f.CreatedBy = ptr.String("admin")
+
+		repo.On("Find", ctx, f, mock.Anything).Return([]*user.User{}, nil).Once()
+
+		res, err := sv.GetUsers(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Empty(t, res.Data)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+		req := &userv1.GetUsersRequest{
+			CreatedAfter:  timestamppb.New(before),
+			CreatedBefore: timestamppb.New(after),
+		}
+
+		res, err := sv.GetUsers(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, err.Error(), "created_after must be before created_before.")
+	})
+
+	t.Run("invalid timestamp", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+		req := &userv1.GetUsersRequest{
+			UpdatedAfter: &timestamppb.Timestamp{Nanos: -1},
+		}
+
+		res, err := sv.GetUsers(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
 	req := &userv1.UpdateUserRequest{
 		Id:    primitive.NewObjectID().Hex(),
 		Name:  ptr.String("test"),
@@ -323,8 +660,210 @@ func TestUpdateUser(t *testing.T) {
 	})
 }
 
+func TestUpdateUserOfHigherRole(t *testing.T) {
+	supportctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "support", Roles: []string{"support"}})
+	id := primitive.NewObjectID().Hex()
+
+	t.Run("support cannot update an admin", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("FindByID", supportctx, id).Return(&user.User{Name: "admin", Roles: []string{"admin"}}, nil).Once()
+
+		res, err := sv.UpdateUser(supportctx, &userv1.UpdateUserRequest{Id: id, Email: ptr.String("support@example.com")})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		repo.AssertNotCalled(t, "ReplaceOne")
+	})
+
+	t.Run("support cannot delete an admin", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("FindByID", supportctx, id).Return(&user.User{Name: "admin", Roles: []string{"user", "admin"}}, nil).Once()
+
+		res, err := sv.DeleteUser(supportctx, &userv1.DeleteUserRequest{Id: id})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		repo.AssertNotCalled(t, "ReplaceOne")
+	})
+
+	t.Run("support can update a user", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		u := &user.User{Name: "test", Email: "test@example.com", Roles: []string{"user"}}
+
+		repo.On("FindByID", supportctx, id).Return(u, nil).Once()
+		repo.On("ReplaceOne", supportctx, id, u).Return(nil).Once()
+
+		_, err := sv.UpdateUser(supportctx, &userv1.UpdateUserRequest{Id: id, Name: ptr.String("renamed")})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("users can update themselves", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		adminctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: id, Roles: []string{"admin"}})
+		u := &user.User{Name: "admin", Email: "admin@example.com", Roles: []string{"admin"}}
+
+		repo.On("FindByID", adminctx, id).Return(u, nil).Once()
+		repo.On("ReplaceOne", adminctx, id, u).Return(nil).Once()
+
+		_, err := sv.UpdateUser(adminctx, &userv1.UpdateUserRequest{Id: id, Name: ptr.String("renamed")})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestUpdateUserMask(t *testing.T) {
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
+	id := primitive.NewObjectID().Hex()
+	newUser := func() *user.User {
+		return &user.User{Name: "test", Email: "test@example.com", EmailVerifiedAt: ptr.Time(time.Now())}
+	}
+
+	t.Run("only masked fields", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		u := newUser()
+
+		repo.On("FindByID", ctx, id).Return(u, nil).Once()
+		repo.On("ReplaceOne", ctx, id, u).Return(nil).Once()
+
+		_, err := sv.UpdateUser(ctx, &userv1.UpdateUserRequest{
+			Id:         id,
+			Name:       ptr.String("renamed"),
+			Email:      ptr.String("other@example.com"),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", u.Name)
+		assert.Equal(t, types.Email("test@example.com"), u.Email)
+		assert.NotNil(t, u.EmailVerifiedAt)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		u := newUser()
+
+		repo.On("FindByID", ctx, id).Return(u, nil).Once()
+		repo.On("ReplaceOne", ctx, id, u).Return(nil).Once()
+
+		_, err := sv.UpdateUser(ctx, &userv1.UpdateUserRequest{
+			Id:         id,
+			Name:       ptr.String("renamed"),
+			Email:      ptr.String("other@example.com"),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"*"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", u.Name)
+		assert.Equal(t, types.Email("other@example.com"), u.Email)
+		assert.Nil(t, u.EmailVerifiedAt)
+	})
+
+	t.Run("invalid paths", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		res, err := sv.UpdateUser(ctx, &userv1.UpdateUserRequest{
+			Id:         id,
+			Name:       ptr.String("renamed"),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "roles", "nickname", "email"}},
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "Update mask has invalid paths: roles, nickname, email.", status.Convert(err).Message())
+		violations := util.FieldViolationsFromError(err)
+		assert.Len(t, violations, 3)
+		assert.Equal(t, "immutable_path", violations[0].Reason)
+		assert.Equal(t, "unknown_path", violations[1].Reason)
+		assert.Equal(t, "required", violations[2].Reason)
+		repo.AssertNotCalled(t, "FindByID")
+	})
+}
+
+func TestUserETag(t *testing.T) {
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
+	id := primitive.NewObjectID().Hex()
+	newUser := func() *user.User {
+		return &user.User{Name: "test", Email: "test@example.com", Version: 3}
+	}
+
+	t.Run("update with current etag", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		u := newUser()
+
+		repo.On("FindByID", ctx, id).Return(u, nil).Once()
+		repo.On("ReplaceOne", ctx, id, u).Run(func(args mock.Arguments) {
+			args.Get(2).(*user.User).Version++
+		}).Return(nil).Once()
+
+		res, err := sv.UpdateUser(ctx, &userv1.UpdateUserRequest{Id: id, Name: ptr.String("renamed"), Etag: "3"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "4", res.Etag)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("update with stale etag", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("FindByID", ctx, id).Return(newUser(), nil).Once()
+
+		res, err := sv.UpdateUser(ctx, &userv1.UpdateUserRequest{Id: id, Name: ptr.String("renamed"), Etag: "2"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		repo.AssertNotCalled(t, "ReplaceOne")
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("FindByID", ctx, id).Return(newUser(), nil).Once()
+		repo.On("ReplaceOne", ctx, id, mock.Anything).Return(user.ErrVersionConflict).Once()
+
+		res, err := sv.UpdateUser(ctx, &userv1.UpdateUserRequest{Id: id, Name: ptr.String("renamed")})
+
+		assert.Nil(t, res)
+		assert.Equal(t, userstore.ErrUserModified, err)
+	})
+
+	t.Run("delete with stale etag", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("FindByID", ctx, id).Return(newUser(), nil).Once()
+
+		res, err := sv.DeleteUser(ctx, &userv1.DeleteUserRequest{Id: id, Etag: "2"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		repo.AssertNotCalled(t, "ReplaceOne")
+	})
+
+	t.Run("etag of a user", func(t *testing.T) {
+		res, err := mapGRPCUser(newUser())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "3", res.Etag)
+	})
+}
+
 func TestDeleteUser(t *testing.T) {
-	ctx := context.Background()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "admin", Roles: []string{"admin"}})
 	uid := primitive.NewObjectID()
 	req := &userv1.DeleteUserRequest{
 		Id: uid.Hex(),
@@ -336,18 +875,27 @@ func TestDeleteUser(t *testing.T) {
 		Password: "password",
 	}
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("success - revokes the credentials", func(t *testing.T) {
 		repo := new(mockUserRepository)
-		sv := &grpcService{userrepo: repo}
+		sessionrepo := new(mockSessionRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{userrepo: repo, sessionrepo: sessionrepo, rtrepo: rtrepo, keyrepo: keyrepo}
 
 		repo.On("FindByID", ctx, req.Id).Return(muser, nil).Once()
 		repo.On("ReplaceOne", ctx, req.Id, muser).Return(nil).Once()
+		sessionrepo.On("RevokeByUserID", ctx, req.Id).Return(nil).Once()
+		rtrepo.On("RevokeByUserID", ctx, req.Id).Return(nil).Once()
+		keyrepo.On("RevokeByOwner", ctx, apikey.OwnerTypeUser, req.Id).Return(nil).Once()
 
 		res, err := sv.DeleteUser(ctx, req)
 
 		assert.NotNil(t, res)
 		assert.NoError(t, err)
 		repo.AssertExpectations(t)
+		sessionrepo.AssertExpectations(t)
+		rtrepo.AssertExpectations(t)
+		keyrepo.AssertExpectations(t)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
@@ -381,3 +929,386 @@ func TestDeleteUser(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 }
+
+func TestGrantRole(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		muser := &user.User{ID: uid, Roles: []string{"user"}}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return assert.ObjectsAreEqual([]string{"user", "support"}, u.Roles)
+		})).Return(nil).Once()
+
+		res, err := sv.GrantRole(ctx, &userv1.GrantRoleRequest{UserId: uid.Hex(), Role: "support"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user", "support"}, res.Roles)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("role already granted", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		muser := &user.User{ID: uid, Roles: []string{"user", "support"}}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+
+		res, err := sv.GrantRole(ctx, &userv1.GrantRoleRequest{UserId: uid.Hex(), Role: "support"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user", "support"}, res.Roles)
+		repo.AssertNotCalled(t, "ReplaceOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		res, err := sv.GrantRole(ctx, &userv1.GrantRoleRequest{UserId: uid.Hex(), Role: "root"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Role is invalid."), err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		msgerr := errors.New("user not found")
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(nil, msgerr).Once()
+
+		res, err := sv.GrantRole(ctx, &userv1.GrantRoleRequest{UserId: uid.Hex(), Role: "admin"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, msgerr, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestRevokeRole(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		muser := &user.User{ID: uid, Roles: []string{"user", "support"}}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.Anything).Return(nil).Once()
+
+		res, err := sv.RevokeRole(ctx, &userv1.RevokeRoleRequest{UserId: uid.Hex(), Role: "support"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user"}, res.Roles)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("cannot revoke own admin role", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		actx := identity.WithClaims(ctx, &token.JwtToken{UserID: uid.Hex(), Roles: []string{"admin"}})
+
+		res, err := sv.RevokeRole(actx, &userv1.RevokeRoleRequest{UserId: uid.Hex(), Role: "admin"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		res, err := sv.RevokeRole(ctx, &userv1.RevokeRoleRequest{UserId: uid.Hex(), Role: ""})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUnlockUser(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{userrepo: repo, attemptrepo: attemptrepo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid}, nil).Once()
+		attemptrepo.On("DeleteByKey", ctx, loginattempt.UserKey(uid.Hex())).Return(nil).Once()
+
+		res, err := sv.UnlockUser(ctx, &userv1.UnlockUserRequest{UserId: uid.Hex()})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		attemptrepo.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		attemptrepo := new(mockLoginAttemptRepository)
+		sv := &grpcService{userrepo: repo, attemptrepo: attemptrepo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(nil, user.ErrUserNotFound).Once()
+
+		res, err := sv.UnlockUser(ctx, &userv1.UnlockUserRequest{UserId: uid.Hex()})
+
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+		attemptrepo.AssertNotCalled(t, "DeleteByKey", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+	muser := &user.User{ID: uid, Name: "test", Email: "test@example.com"}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+
+		res, err := sv.GetCurrentUser(ctx, &userv1.GetCurrentUserRequest{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uid.Hex(), res.User.Id)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+
+		res, err := sv.GetCurrentUser(context.Background(), &userv1.GetCurrentUserRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUpdateCurrentUser(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{userrepo: repo}
+		muser := &user.User{ID: uid, Name: "test", Email: "test@example.com"}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.Name == "new name"
+		})).Return(nil).Once()
+
+		res, err := sv.UpdateCurrentUser(ctx, &userv1.UpdateCurrentUserRequest{Name: ptr.String("new name")})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+
+		res, err := sv.UpdateCurrentUser(context.Background(), &userv1.UpdateCurrentUserRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestDeleteCurrentUser(t *testing.T) {
+	uid := primitive.NewObjectID()
+	ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: uid.Hex()})
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sessionrepo := new(mockSessionRepository)
+		rtrepo := new(mockRefreshTokenRepository)
+		keyrepo := new(mockAPIKeyRepository)
+		sv := &grpcService{userrepo: repo, sessionrepo: sessionrepo, rtrepo: rtrepo, keyrepo: keyrepo}
+		muser := &user.User{ID: uid, Name: "test", Email: "test@example.com"}
+
+		repo.On("FindByID", ctx, uid.Hex()).Return(muser, nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.DeletedAt != nil
+		})).Return(nil).Once()
+		sessionrepo.On("RevokeByUserID", ctx, uid.Hex()).Return(nil).Once()
+		rtrepo.On("RevokeByUserID", ctx, uid.Hex()).Return(nil).Once()
+		keyrepo.On("RevokeByOwner", ctx, apikey.OwnerTypeUser, uid.Hex()).Return(nil).Once()
+
+		res, err := sv.DeleteCurrentUser(ctx, &userv1.DeleteCurrentUserRequest{})
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		sv := &grpcService{userrepo: new(mockUserRepository)}
+
+		res, err := sv.DeleteCurrentUser(context.Background(), &userv1.DeleteCurrentUserRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+	email := types.Email("test@example.com")
+	req := &userv1.VerifyEmailRequest{Token: "verify-token"}
+
+	newToken := func(ttl time.Duration) *emailverification.EmailVerificationToken {
+		evt := emailverification.NewEmailVerificationToken(uid.Hex(), email, req.Token, ttl)
+		evt.ID = primitive.NewObjectID()
+		return evt
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		evrepo := new(mockEmailVerificationRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo}
+		evt := newToken(time.Hour)
+
+		evrepo.On("FindByToken", ctx, req.Token).Return(evt, nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, Email: email}, nil).Once()
+		evrepo.On("MarkUsed", ctx, evt.ID).Return(nil).Once()
+		repo.On("ReplaceOne", ctx, uid.Hex(), mock.MatchedBy(func(u *user.User) bool {
+			return u.EmailVerifiedAt != nil
+		})).Return(nil).Once()
+
+		res, err := sv.VerifyEmail(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		evrepo.AssertExpectations(t)
+	})
+
+	t.Run("token not found", func(t *testing.T) {
+		evrepo := new(mockEmailVerificationRepository)
+		sv := &grpcService{cfg: testcfg, evrepo: evrepo}
+
+		evrepo.On("FindByToken", ctx, req.Token).Return(nil, emailverification.ErrEmailVerificationTokenNotFound).Once()
+
+		res, err := sv.VerifyEmail(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.InvalidArgument, "Verification token is invalid or expired."), err)
+	})
+
+	t.Run("token expired", func(t *testing.T) {
+		evrepo := new(mockEmailVerificationRepository)
+		sv := &grpcService{cfg: testcfg, evrepo: evrepo}
+
+		evrepo.On("FindByToken", ctx, req.Token).Return(newToken(-time.Minute), nil).Once()
+
+		res, err := sv.VerifyEmail(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("email changed since the token was sent", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		evrepo := new(mockEmailVerificationRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo}
+
+		evrepo.On("FindByToken", ctx, req.Token).Return(newToken(time.Hour), nil).Once()
+		repo.On("FindByID", ctx, uid.Hex()).Return(&user.User{ID: uid, Email: "new@example.com"}, nil).Once()
+
+		res, err := sv.VerifyEmail(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		evrepo.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("token is required", func(t *testing.T) {
+		sv := &grpcService{cfg: testcfg}
+
+		res, err := sv.VerifyEmail(ctx, &userv1.VerifyEmailRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	ctx := context.Background()
+	uid := primitive.NewObjectID()
+	req := &userv1.ResendVerificationEmailRequest{Email: "test@example.com"}
+
+	t.Run("success", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		evrepo := new(mockEmailVerificationRepository)
+		ml := new(mockMailer)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo, mailer: ml}
+
+		repo.On("FindByEmail", ctx, types.Email(req.Email)).Return(&user.User{ID: uid, Email: types.Email(req.Email)}, nil).Once()
+		evrepo.On("CountSince", ctx, uid.Hex(), mock.Anything).Return(int64(1), nil).Once()
+		evrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+		ml.On("Send", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := sv.ResendVerificationEmail(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		evrepo.AssertExpectations(t)
+		ml.AssertExpectations(t)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		evrepo := new(mockEmailVerificationRepository)
+		ml := new(mockMailer)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo, mailer: ml}
+
+		repo.On("FindByEmail", ctx, types.Email(req.Email)).Return(&user.User{ID: uid, Email: types.Email(req.Email)}, nil).Once()
+		evrepo.On("CountSince", ctx, uid.Hex(), mock.Anything).Return(testcfg.EmailVerificationResendLimit, nil).Once()
+
+		res, err := sv.ResendVerificationEmail(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		ml.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already verified", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		evrepo := new(mockEmailVerificationRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo, evrepo: evrepo}
+
+		repo.On("FindByEmail", ctx, types.Email(req.Email)).Return(&user.User{ID: uid, EmailVerifiedAt: ptr.Time(time.Now())}, nil).Once()
+
+		res, err := sv.ResendVerificationEmail(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+		evrepo.AssertNotCalled(t, "CountSince", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		repo := new(mockUserRepository)
+		sv := &grpcService{cfg: testcfg, userrepo: repo}
+
+		repo.On("FindByEmail", ctx, types.Email(req.Email)).Return(nil, errors.New("user not found")).Once()
+
+		res, err := sv.ResendVerificationEmail(ctx, req)
+
+		assert.NotNil(t, res)
+		assert.NoError(t, err)
+	})
+}
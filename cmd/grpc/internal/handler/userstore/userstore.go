@@ -0,0 +1,26 @@
+// Package userstore holds the user writes shared by the gRPC handlers, so
+// that every RPC reports a lost update the same way.
+package userstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUserModified is returned when the user changed since the caller read
+// it, either by its etag or by another request writing it first.
+var ErrUserModified = status.Error(codes.Aborted, "User was modified by another request, get it again and retry.")
+
+// Replace stores the user, unless another request changed it since it was
+// read.
+func Replace(ctx context.Context, repo user.UserRepository, id string, u *user.User) error {
+	err := repo.ReplaceOne(ctx, id, u)
+	if errors.Is(err, user.ErrVersionConflict) {
+		return ErrUserModified
+	}
+	return err
+}
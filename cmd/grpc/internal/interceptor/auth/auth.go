@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/revokedtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
+	"github.com/nuea/backend-golang-test/internal/service/apikey"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type AuthInterceptor interface {
+	Unary() grpc.UnaryServerInterceptor
+}
+
+type authInterceptor struct {
+	cfg                         *config.AuthConfig
+	tokensv                     token.TokenService
+	apikeysv                    apikey.APIKeyService
+	revokedrepo                 revokedtoken.RevokedTokenRepository
+	sessionrepo                 session.SessionRepository
+	auditrepo                   auditlog.AuditLogRepository
+	publicMethods               map[string]bool
+	sessionOnlyMethods          map[string]bool
+	impersonationBlockedMethods map[string]bool
+}
+
+func ProvideAuthInterceptor(cfg *config.AppConfig, repo *repository.Repository, tokensv token.TokenService, apikeysv apikey.APIKeyService) AuthInterceptor {
+	return &authInterceptor{
+		cfg:                         &cfg.Auth,
+		tokensv:                     tokensv,
+		apikeysv:                    apikeysv,
+		revokedrepo:                 repo.RevokedTokenRepository,
+		sessionrepo:                 repo.SessionRepository,
+		auditrepo:                   repo.AuditLogRepository,
+		publicMethods:               handler.PublicMethods,
+		sessionOnlyMethods:          handler.SessionOnlyMethods,
+		impersonationBlockedMethods: handler.ImpersonationBlockedMethods,
+	}
+}
+
+func (i *authInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+		if i.publicMethods[info.FullMethod] {
+			return h(ctx, req)
+		}
+
+		claims, err := i.authentication(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if claims.APIKeyID != "" && i.sessionOnlyMethods[info.FullMethod] {
+			return nil, status.Error(codes.PermissionDenied, "This method cannot be called with an API key.")
+		}
+
+		if claims.IsImpersonated() {
+			if err := i.auditImpersonation(ctx, claims, info.FullMethod); err != nil {
+				return nil, err
+			}
+			if i.impersonationBlockedMethods[info.FullMethod] {
+				return nil, status.Error(codes.PermissionDenied, "This method cannot be called while impersonating a user.")
+			}
+		}
+		return h(identity.WithClaims(ctx, claims), req)
+	}
+}
+
+// authentication accepts a bearer access token, or else an API key in the
+// "x-api-key" metadata.
+func (i *authInterceptor) authentication(ctx context.Context) (*token.JwtToken, error) {
+	var ac string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if acs := strings.Fields(strings.Join(md.Get("authorization"), "")); len(acs) > 1 && acs[0] == "Bearer" {
+		ac = acs[1]
+	} else if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		return i.apikeysv.Authenticate(ctx, keys[0])
+	} else {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+	}
+
+	claims, err := i.tokensv.VerifyAccessToken(ac)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if claims.Id != "" {
+		revoked, err := i.revokedrepo.IsRevoked(ctx, claims.Id)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, status.Error(codes.Unauthenticated, "Access token has been revoked.")
+		}
+	}
+
+	if claims.SessionID != "" {
+		if err := i.checkSession(ctx, claims.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// checkSession rejects the access tokens of a revoked session before they
+// expire, and records the activity of the session.
+func (i *authInterceptor) checkSession(ctx context.Context, id string) error {
+	s, err := i.sessionrepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			return status.Error(codes.Unauthenticated, "Session has been revoked.")
+		}
+		return err
+	}
+	if s.IsRevoked() || s.IsExpired() {
+		return status.Error(codes.Unauthenticated, "Session has been revoked.")
+	}
+
+	if err := i.sessionrepo.TouchLastActive(ctx, s.ID, i.cfg.SessionLastActiveInterval); err != nil {
+		log.Println("Unable to record the session activity:", err)
+	}
+	return nil
+}
+
+// auditImpersonation flags every call made with an impersonation token, the
+// blocked ones included. The call fails when it cannot be recorded.
+func (i *authInterceptor) auditImpersonation(ctx context.Context, claims *token.JwtToken, method string) error {
+	log.Printf("Impersonated call to %s by %s as %s", method, claims.Actor.Subject, claims.UserID)
+
+	l := auditlog.NewAuditLog(auditlog.ActionImpersonationRequest, claims.Actor.Subject, claims.UserID)
+	l.Method = method
+	return i.auditrepo.InsertOne(ctx, l)
+}
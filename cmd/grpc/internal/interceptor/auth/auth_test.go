@@ -0,0 +1,344 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/revokedtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
+	"github.com/nuea/backend-golang-test/internal/service/apikey"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type mockRevokedTokenRepository struct {
+	mock.Mock
+	revokedtoken.RevokedTokenRepository
+}
+
+func (m *mockRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	args := m.Called(ctx, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockSessionRepository struct {
+	mock.Mock
+	session.SessionRepository
+}
+
+func (m *mockSessionRepository) FindByID(ctx context.Context, id string) (*session.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*session.Session), args.Error(1)
+}
+
+func (m *mockSessionRepository) TouchLastActive(ctx context.Context, id primitive.ObjectID, interval time.Duration) error {
+	args := m.Called(ctx, id, interval)
+	return args.Error(0)
+}
+
+type mockTokenService struct {
+	mock.Mock
+	token.TokenService
+}
+
+func (m *mockTokenService) VerifyAccessToken(accessToken string) (*token.JwtToken, error) {
+	args := m.Called(accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*token.JwtToken), args.Error(1)
+}
+
+type mockAuditLogRepository struct {
+	mock.Mock
+	auditlog.AuditLogRepository
+}
+
+func (m *mockAuditLogRepository) InsertOne(ctx context.Context, l *auditlog.AuditLog) error {
+	args := m.Called(ctx, l)
+	return args.Error(0)
+}
+
+type mockAPIKeyService struct {
+	mock.Mock
+	apikey.APIKeyService
+}
+
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, key string) (*token.JwtToken, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*token.JwtToken), args.Error(1)
+}
+
+func TestUnary(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	claims := &token.JwtToken{
+		StandardClaims: jwt.StandardClaims{
+			Id:        "jti",
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		},
+		UserID: "uid",
+	}
+	withToken := func(ac string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+ac))
+	}
+	protected := &grpc.UnaryServerInfo{FullMethod: userv1.UserService_GetUsers_FullMethodName}
+
+	newInterceptor := func() (*authInterceptor, *mockTokenService, *mockRevokedTokenRepository) {
+		tokensv := new(mockTokenService)
+		revokedrepo := new(mockRevokedTokenRepository)
+		return &authInterceptor{
+			tokensv:     tokensv,
+			revokedrepo: revokedrepo,
+			publicMethods: map[string]bool{
+				userv1.UserService_CreateUser_FullMethodName: true,
+			},
+			sessionOnlyMethods: map[string]bool{
+				userv1.AuthService_ChangePassword_FullMethodName: true,
+			},
+			impersonationBlockedMethods: map[string]bool{
+				userv1.AuthService_ChangePassword_FullMethodName: true,
+			},
+		}, tokensv, revokedrepo
+	}
+
+	t.Run("public method", func(t *testing.T) {
+		i, tokensv, _ := newInterceptor()
+
+		res, err := i.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: userv1.UserService_CreateUser_FullMethodName}, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+		tokensv.AssertNotCalled(t, "VerifyAccessToken", mock.Anything)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		i, _, _ := newInterceptor()
+
+		res, err := i.Unary()(context.Background(), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		i, tokensv, _ := newInterceptor()
+		tokensv.On("VerifyAccessToken", "bad").Return(nil, errors.New("Access token is invalid.")).Once()
+
+		res, err := i.Unary()(withToken("bad"), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		tokensv.AssertExpectations(t)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		i, tokensv, _ := newInterceptor()
+		tokensv.On("VerifyAccessToken", "expired").Return(nil, token.ErrTokenExpired).Once()
+
+		res, err := i.Unary()(withToken("expired"), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.Unauthenticated, "Access token has expired."), err)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		ctx := withToken("revoked")
+		tokensv.On("VerifyAccessToken", "revoked").Return(claims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(true, nil).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.Unauthenticated, "Access token has been revoked."), err)
+		revokedrepo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(claims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+		tokensv.AssertExpectations(t)
+		revokedrepo.AssertExpectations(t)
+	})
+
+	impersonated := *claims
+	impersonated.Actor = &token.Actor{Subject: "admin"}
+
+	t.Run("impersonated call is audited", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		auditrepo := new(mockAuditLogRepository)
+		i.auditrepo = auditrepo
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(&impersonated, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		auditrepo.On("InsertOne", ctx, mock.MatchedBy(func(l *auditlog.AuditLog) bool {
+			return l.Action == auditlog.ActionImpersonationRequest && l.ActorID == "admin" && l.UserID == "uid" && l.Method == protected.FullMethod
+		})).Return(nil).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+		auditrepo.AssertExpectations(t)
+	})
+
+	t.Run("impersonated call to a blocked method", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		auditrepo := new(mockAuditLogRepository)
+		i.auditrepo = auditrepo
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(&impersonated, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		auditrepo.On("InsertOne", ctx, mock.Anything).Return(nil).Once()
+
+		res, err := i.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: userv1.AuthService_ChangePassword_FullMethodName}, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.PermissionDenied, "This method cannot be called while impersonating a user."), err)
+		auditrepo.AssertExpectations(t)
+	})
+
+	t.Run("impersonated call fails when it cannot be audited", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		auditrepo := new(mockAuditLogRepository)
+		i.auditrepo = auditrepo
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(&impersonated, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		auditrepo.On("InsertOne", ctx, mock.Anything).Return(errors.New("write error")).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Error(t, err)
+	})
+
+	sid := primitive.NewObjectID()
+	sessionClaims := *claims
+	sessionClaims.SessionID = sid.Hex()
+	cfg := &config.AuthConfig{SessionLastActiveInterval: time.Minute}
+
+	t.Run("active session", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		sessionrepo := new(mockSessionRepository)
+		i.cfg, i.sessionrepo = cfg, sessionrepo
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(&sessionClaims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(&session.Session{ID: sid, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+		sessionrepo.On("TouchLastActive", ctx, sid, time.Minute).Return(nil).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+		sessionrepo.AssertExpectations(t)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		sessionrepo := new(mockSessionRepository)
+		i.cfg, i.sessionrepo = cfg, sessionrepo
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(&sessionClaims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(&session.Session{ID: sid, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: ptr.Time(time.Now())}, nil).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.Unauthenticated, "Session has been revoked."), err)
+		sessionrepo.AssertNotCalled(t, "TouchLastActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing session", func(t *testing.T) {
+		i, tokensv, revokedrepo := newInterceptor()
+		sessionrepo := new(mockSessionRepository)
+		i.cfg, i.sessionrepo = cfg, sessionrepo
+		ctx := withToken("valid")
+		tokensv.On("VerifyAccessToken", "valid").Return(&sessionClaims, nil).Once()
+		revokedrepo.On("IsRevoked", ctx, "jti").Return(false, nil).Once()
+		sessionrepo.On("FindByID", ctx, sid.Hex()).Return(nil, session.ErrSessionNotFound).Once()
+
+		res, err := i.Unary()(ctx, nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	withAPIKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))
+	}
+	keyClaims := &token.JwtToken{UserID: "uid", APIKeyID: "kid", Scopes: []string{"users:read"}}
+
+	t.Run("api key", func(t *testing.T) {
+		i, tokensv, _ := newInterceptor()
+		keysv := new(mockAPIKeyService)
+		i.apikeysv = keysv
+		keysv.On("Authenticate", "bgt_key").Return(keyClaims, nil).Once()
+
+		var got *token.JwtToken
+		res, err := i.Unary()(withAPIKey("bgt_key"), nil, protected, func(ctx context.Context, req any) (any, error) {
+			got = identity.ClaimsFromContext(ctx)
+			return "ok", nil
+		})
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+		assert.Equal(t, keyClaims, got)
+		tokensv.AssertNotCalled(t, "VerifyAccessToken", mock.Anything)
+	})
+
+	t.Run("invalid api key", func(t *testing.T) {
+		i, _, _ := newInterceptor()
+		keysv := new(mockAPIKeyService)
+		i.apikeysv = keysv
+		keysv.On("Authenticate", "bgt_bad").Return(nil, apikey.ErrInvalidAPIKey).Once()
+
+		res, err := i.Unary()(withAPIKey("bgt_bad"), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("api key on session only method", func(t *testing.T) {
+		i, _, _ := newInterceptor()
+		keysv := new(mockAPIKeyService)
+		i.apikeysv = keysv
+		keysv.On("Authenticate", "bgt_key").Return(keyClaims, nil).Once()
+
+		res, err := i.Unary()(withAPIKey("bgt_key"), nil, &grpc.UnaryServerInfo{FullMethod: userv1.AuthService_ChangePassword_FullMethodName}, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -0,0 +1,19 @@
+package interceptor
+
+import (
+	"github.com/google/wire"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor/auth"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor/permission"
+)
+
+type Interceptors struct {
+	Auth       auth.AuthInterceptor
+	Permission permission.PermissionInterceptor
+}
+
+var InterceptorSet = wire.NewSet(
+	auth.ProvideAuthInterceptor,
+	permission.ProvidePermissionInterceptor,
+
+	wire.Struct(new(Interceptors), "*"),
+)
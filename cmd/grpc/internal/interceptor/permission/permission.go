@@ -0,0 +1,84 @@
+package permission
+
+import (
+	"context"
+
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type PermissionInterceptor interface {
+	Unary() grpc.UnaryServerInterceptor
+}
+
+type permissionInterceptor struct {
+	methodPermissions  map[string]rbac.Permission
+	ownerMethods       map[string]bool
+	currentUserMethods map[string]bool
+}
+
+type idRequest interface {
+	GetId() string
+}
+
+func ProvidePermissionInterceptor() PermissionInterceptor {
+	return &permissionInterceptor{
+		methodPermissions:  handler.MethodPermissions,
+		ownerMethods:       handler.OwnerMethods,
+		currentUserMethods: handler.CurrentUserMethods,
+	}
+}
+
+// Unary must be chained after the auth interceptor, it reads the roles from
+// the verified claims. Methods without a declared permission only require
+// authentication, except for client tokens: they act for no user, so they
+// are limited to the methods their scopes cover.
+func (i *permissionInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+		claims := identity.ClaimsFromContext(ctx)
+
+		perm, ok := i.methodPermissions[info.FullMethod]
+		if !ok {
+			if claims != nil && claims.ClientID != "" {
+				return nil, status.Error(codes.PermissionDenied, "Permission denied.")
+			}
+			return h(ctx, req)
+		}
+
+		if claims == nil {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized.")
+		}
+
+		// A client token has no account of its own to act on.
+		if claims.ClientID != "" && i.currentUserMethods[info.FullMethod] {
+			return nil, status.Error(codes.PermissionDenied, "Permission denied.")
+		}
+
+		if claims.HasPermission(perm) {
+			return h(ctx, req)
+		}
+
+		// An API key acts on its owner's account only within its scopes.
+		if i.isOwnAccount(info.FullMethod, req, claims.UserID) && claims.HasScope(perm) {
+			return h(ctx, req)
+		}
+		return nil, status.Error(codes.PermissionDenied, "Permission denied.")
+	}
+}
+
+// isOwnAccount reports whether the method acts on the account of userID. A
+// client token acts for no user, it never owns an account.
+func (i *permissionInterceptor) isOwnAccount(method string, req any, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if i.currentUserMethods[method] {
+		return true
+	}
+	r, ok := req.(idRequest)
+	return ok && i.ownerMethods[method] && r.GetId() == userID
+}
@@ -0,0 +1,195 @@
+package permission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnary(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	i := &permissionInterceptor{
+		methodPermissions: map[string]rbac.Permission{
+			userv1.UserService_DeleteUser_FullMethodName: rbac.PermissionUsersDelete,
+			userv1.UserService_GrantRole_FullMethodName:  rbac.PermissionRolesManage,
+		},
+		ownerMethods: map[string]bool{
+			userv1.UserService_DeleteUser_FullMethodName: true,
+		},
+	}
+	protected := &grpc.UnaryServerInfo{FullMethod: userv1.UserService_DeleteUser_FullMethodName}
+	withRoles := func(roles ...string) context.Context {
+		return identity.WithClaims(context.Background(), &token.JwtToken{UserID: "uid", Roles: roles})
+	}
+
+	t.Run("method without permission", func(t *testing.T) {
+		res, err := i.Unary()(withRoles(), nil, &grpc.UnaryServerInfo{FullMethod: userv1.AuthService_Logout_FullMethodName}, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("granted", func(t *testing.T) {
+		res, err := i.Unary()(withRoles("user", "admin"), nil, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		res, err := i.Unary()(withRoles("support"), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.PermissionDenied, "Permission denied."), err)
+	})
+
+	t.Run("own resource", func(t *testing.T) {
+		res, err := i.Unary()(withRoles("user"), &userv1.DeleteUserRequest{Id: "uid"}, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("someone else's resource", func(t *testing.T) {
+		res, err := i.Unary()(withRoles("user"), &userv1.DeleteUserRequest{Id: "other"}, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("owner rule only applies to owner methods", func(t *testing.T) {
+		info := &grpc.UnaryServerInfo{FullMethod: userv1.UserService_GrantRole_FullMethodName}
+		res, err := i.Unary()(withRoles("user"), &userv1.GrantRoleRequest{UserId: "uid", Role: "admin"}, info, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	withAPIKey := func(roles []string, scopes ...string) context.Context {
+		return identity.WithClaims(context.Background(), &token.JwtToken{UserID: "uid", Roles: roles, APIKeyID: "kid", Scopes: scopes})
+	}
+
+	t.Run("api key within scopes", func(t *testing.T) {
+		res, err := i.Unary()(withAPIKey([]string{"admin"}, "users:delete"), nil, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("api key outside scopes", func(t *testing.T) {
+		res, err := i.Unary()(withAPIKey([]string{"admin"}, "users:read"), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("api key scope beyond the owner's roles", func(t *testing.T) {
+		res, err := i.Unary()(withAPIKey([]string{"support"}, "users:delete"), &userv1.DeleteUserRequest{Id: "other"}, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("api key on own resource outside scopes", func(t *testing.T) {
+		res, err := i.Unary()(withAPIKey([]string{"user"}, "users:read"), &userv1.DeleteUserRequest{Id: "uid"}, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	withClient := func(scopes ...string) context.Context {
+		return identity.WithClaims(context.Background(), &token.JwtToken{ClientID: "client", Scopes: scopes})
+	}
+
+	t.Run("client token within scopes", func(t *testing.T) {
+		res, err := i.Unary()(withClient("users:delete"), &userv1.DeleteUserRequest{Id: "other"}, protected, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("client token outside scopes", func(t *testing.T) {
+		res, err := i.Unary()(withClient("users:read"), &userv1.DeleteUserRequest{Id: ""}, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("client token on method without permission", func(t *testing.T) {
+		res, err := i.Unary()(withClient("users:read"), nil, &grpc.UnaryServerInfo{FullMethod: userv1.AuthService_ChangePassword_FullMethodName}, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, status.Error(codes.PermissionDenied, "Permission denied."), err)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		res, err := i.Unary()(context.Background(), nil, protected, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestCurrentUserMethods(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	i := ProvidePermissionInterceptor()
+	update := &grpc.UnaryServerInfo{FullMethod: userv1.UserService_UpdateCurrentUser_FullMethodName}
+	remove := &grpc.UnaryServerInfo{FullMethod: userv1.UserService_DeleteCurrentUser_FullMethodName}
+	withAPIKey := func(scopes ...string) context.Context {
+		return identity.WithClaims(context.Background(), &token.JwtToken{UserID: "uid", Roles: []string{"user"}, APIKeyID: "kid", Scopes: scopes})
+	}
+
+	t.Run("user", func(t *testing.T) {
+		ctx := identity.WithClaims(context.Background(), &token.JwtToken{UserID: "uid", Roles: []string{"user"}})
+
+		for _, info := range []*grpc.UnaryServerInfo{update, remove} {
+			res, err := i.Unary()(ctx, nil, info, handler)
+
+			assert.Equal(t, "ok", res, info.FullMethod)
+			assert.NoError(t, err, info.FullMethod)
+		}
+	})
+
+	t.Run("read-only api key", func(t *testing.T) {
+		for _, info := range []*grpc.UnaryServerInfo{update, remove} {
+			res, err := i.Unary()(withAPIKey("users:read"), nil, info, handler)
+
+			assert.Nil(t, res, info.FullMethod)
+			assert.Equal(t, codes.PermissionDenied, status.Code(err), info.FullMethod)
+		}
+	})
+
+	t.Run("api key without scopes", func(t *testing.T) {
+		res, err := i.Unary()(withAPIKey(), nil, update, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("api key within scopes", func(t *testing.T) {
+		res, err := i.Unary()(withAPIKey("users:write"), nil, update, handler)
+
+		assert.Equal(t, "ok", res)
+		assert.NoError(t, err)
+	})
+
+	t.Run("client token", func(t *testing.T) {
+		ctx := identity.WithClaims(context.Background(), &token.JwtToken{ClientID: "client", Scopes: []string{"users:write"}})
+		res, err := i.Unary()(ctx, nil, update, handler)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -2,14 +2,18 @@ package server
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"syscall"
 	"time"
 
 	"github.com/nuea/backend-golang-test/cmd/grpc/internal/handler"
+	"github.com/nuea/backend-golang-test/cmd/grpc/internal/interceptor"
 	"github.com/nuea/backend-golang-test/internal/config"
 	"github.com/nuea/backend-golang-test/internal/repository"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
@@ -45,6 +49,26 @@ func (s *GRPCServer) Serve() {
 		s.srv.Stop()
 	})
 
+	if s.cfg.GRPCConfig.MetricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		metrics := &http.Server{
+			Addr:              fmt.Sprintf(":%s", s.cfg.GRPCConfig.MetricsPort),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		g.Add(func() error {
+			log.Println("GRPC Server - metrics at ip address", metrics.Addr)
+			if err := metrics.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		}, func(err error) {
+			metrics.Close()
+		})
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	g.Add(func() error {
 		ticker := time.NewTicker(10 * time.Second)
@@ -75,9 +99,10 @@ func (s *GRPCServer) Serve() {
 	}
 }
 
-func ProvideGRPCServer(cfg *config.AppConfig, h *handler.GrpcServices, r *repository.Repository) *GRPCServer {
+func ProvideGRPCServer(cfg *config.AppConfig, h *handler.GrpcServices, r *repository.Repository, i *interceptor.Interceptors) *GRPCServer {
 	opt := make([]grpc.ServerOption, 0)
 	opt = append(opt, grpc.Creds(insecure.NewCredentials()))
+	opt = append(opt, grpc.ChainUnaryInterceptor(i.Auth.Unary(), i.Permission.Unary()))
 	opt = append(opt, grpc.KeepaliveParams(keepalive.ServerParameters{
 		Time:    2 * time.Hour,
 		Timeout: 20 * time.Second,
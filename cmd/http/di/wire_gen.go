@@ -9,6 +9,7 @@ package di
 import (
 	"github.com/google/wire"
 	"github.com/nuea/backend-golang-test/cmd/http/internal/handler"
+	"github.com/nuea/backend-golang-test/cmd/http/internal/handler/apikey"
 	auth2 "github.com/nuea/backend-golang-test/cmd/http/internal/handler/auth"
 	"github.com/nuea/backend-golang-test/cmd/http/internal/handler/user"
 	"github.com/nuea/backend-golang-test/cmd/http/internal/server"
@@ -18,8 +19,10 @@ import (
 	"github.com/nuea/backend-golang-test/internal/di"
 	"github.com/nuea/backend-golang-test/internal/middleware"
 	auth3 "github.com/nuea/backend-golang-test/internal/middleware/auth"
+	"github.com/nuea/backend-golang-test/internal/middleware/permission"
 	"github.com/nuea/backend-golang-test/internal/service"
 	"github.com/nuea/backend-golang-test/internal/service/auth"
+	"github.com/nuea/backend-golang-test/internal/service/token"
 )
 
 // Injectors from di.go:
@@ -29,28 +32,44 @@ func InitContainer() (*Container, func(), error) {
 	apiClient := backendgolangtest.ProvideBackendGolangTestServiceGRPC(appConfig)
 	userServiceClient := backendgolangtest.ProvideUserServiceClient(apiClient)
 	authServiceClient := backendgolangtest.ProvideAuthServiceClient(apiClient)
+	apiKeyServiceClient := backendgolangtest.ProvideAPIKeyServiceClient(apiClient)
 	backendGolangTestGRPCService := &backendgolangtest.BackendGolangTestGRPCService{
-		UserServiceClient: userServiceClient,
-		AuthServiceClient: authServiceClient,
+		UserServiceClient:   userServiceClient,
+		AuthServiceClient:   authServiceClient,
+		APIKeyServiceClient: apiKeyServiceClient,
 	}
 	grpcClients := &client.GRPCClients{
 		BackendGolangTestGRPCService: backendGolangTestGRPCService,
 	}
-	authService := auth.ProvideAuthenticationService(appConfig, grpcClients)
+	tokenService, err := token.ProvideTokenService(appConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	authService, err := auth.ProvideAuthenticationService(appConfig, grpcClients, tokenService)
+	if err != nil {
+		return nil, nil, err
+	}
 	serviceService := &service.Service{
 		AuthService: authService,
 	}
 	authHandler := auth2.ProvideAuthHandler(serviceService)
 	userHandler := user.ProvideUserHandler(grpcClients)
+	apikeyHandler := apikey.ProvideAPIKeyHandler(grpcClients)
 	handlers := &handler.Handlers{
-		AuthHandler: authHandler,
-		UserHandler: userHandler,
+		AuthHandler:   authHandler,
+		UserHandler:   userHandler,
+		APIKeyHandler: apikeyHandler,
 	}
-	authMiddleware := auth3.ProvideAuthMiddleware(serviceService)
+	authMiddleware := auth3.ProvideAuthMiddleware(appConfig, serviceService)
+	permissionMiddleware := permission.ProvidePermissionMiddleware()
 	middlewareMiddleware := &middleware.Middleware{
-		Auth: authMiddleware,
+		Auth:       authMiddleware,
+		Permission: permissionMiddleware,
+	}
+	httpServer, err := server.ProvideHTTPServer(appConfig, handlers, middlewareMiddleware)
+	if err != nil {
+		return nil, nil, err
 	}
-	httpServer := server.ProvideHTTPServer(appConfig, handlers, middlewareMiddleware)
 	container := &Container{
 		server: httpServer,
 	}
@@ -0,0 +1,373 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Handler struct {
+	begotc userv1.APIKeyServiceClient
+}
+
+func ProvideAPIKeyHandler(c *client.GRPCClients) *Handler {
+	return &Handler{
+		begotc: c.BackendGolangTestGRPCService.APIKeyServiceClient,
+	}
+}
+
+// @id CreateAPIKey
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @tags APIKey
+// @param req body CreateAPIKeyRequest true "req"
+// @success 200 {object} CreateAPIKeyResponse
+// @router /api/v1/api-keys [POST]
+func (h *Handler) CreateAPIKey(ctx *gin.Context) {
+	var req *CreateAPIKeyRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	greq := &userv1.CreateAPIKeyRequest{
+		Name:             req.Name,
+		Scopes:           req.Scopes,
+		ServiceAccountId: req.ServiceAccountID,
+	}
+	if req.ExpiresAt != nil {
+		greq.ExpiresAt = timestamppb.New(*req.ExpiresAt)
+	}
+
+	gRes, err := h.begotc.CreateAPIKey(ctx, greq)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	key, err := mapToAPIKey(gRes.ApiKey)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &CreateAPIKeyResponse{
+		APIKey: *key,
+		Key:    gRes.Key,
+	})
+}
+
+// @id ListAPIKeys
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @param req formData ListAPIKeysRequest true "req"
+// @success 200 {object} ListAPIKeysResponse
+// @router /api/v1/api-keys [GET]
+func (h *Handler) ListAPIKeys(ctx *gin.Context) {
+	var req ListAPIKeysRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	gRes, err := h.begotc.ListAPIKeys(ctx, &userv1.ListAPIKeysRequest{
+		ServiceAccountId: req.ServiceAccountID,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	datas, err := util.MapToSlice(mapToAPIKey, gRes.Data)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ListAPIKeysResponse{
+		Data: datas,
+	})
+}
+
+// @id RevokeAPIKey
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @param id path string true "id"
+// @success 200 {object} RevokeAPIKeyResponse
+// @router /api/v1/api-keys/{id} [DELETE]
+func (h *Handler) RevokeAPIKey(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	if _, err := h.begotc.RevokeAPIKey(ctx, &userv1.RevokeAPIKeyRequest{
+		Id: id,
+	}); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &RevokeAPIKeyResponse{
+		Message: "Revoked successfully",
+	})
+}
+
+// @id CreateServiceAccount
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @param req body CreateServiceAccountRequest true "req"
+// @success 200 {object} CreateServiceAccountResponse
+// @router /api/v1/service-accounts [POST]
+func (h *Handler) CreateServiceAccount(ctx *gin.Context) {
+	var req *CreateServiceAccountRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	gRes, err := h.begotc.CreateServiceAccount(ctx, &userv1.CreateServiceAccountRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Roles:       req.Roles,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	sa, err := mapToServiceAccount(gRes.ServiceAccount)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &CreateServiceAccountResponse{
+		ServiceAccount: *sa,
+	})
+}
+
+// @id ListServiceAccounts
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @success 200 {object} ListServiceAccountsResponse
+// @router /api/v1/service-accounts [GET]
+func (h *Handler) ListServiceAccounts(ctx *gin.Context) {
+	gRes, err := h.begotc.ListServiceAccounts(ctx, &userv1.ListServiceAccountsRequest{})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	datas, err := util.MapToSlice(mapToServiceAccount, gRes.Data)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ListServiceAccountsResponse{
+		Data: datas,
+	})
+}
+
+// @id DeleteServiceAccount
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @param id path string true "id"
+// @success 200 {object} DeleteServiceAccountResponse
+// @router /api/v1/service-accounts/{id} [DELETE]
+func (h *Handler) DeleteServiceAccount(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	if _, err := h.begotc.DeleteServiceAccount(ctx, &userv1.DeleteServiceAccountRequest{
+		Id: id,
+	}); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &DeleteServiceAccountResponse{
+		Message: "Deleted successfully",
+	})
+}
+
+// @id CreateOAuthClient
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @param req body CreateOAuthClientRequest true "req"
+// @success 200 {object} CreateOAuthClientResponse
+// @router /api/v1/oauth-clients [POST]
+func (h *Handler) CreateOAuthClient(ctx *gin.Context) {
+	var req *CreateOAuthClientRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	gRes, err := h.begotc.CreateOAuthClient(ctx, &userv1.CreateOAuthClientRequest{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c, err := mapToOAuthClient(gRes.OauthClient)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &CreateOAuthClientResponse{
+		OAuthClient:  *c,
+		ClientSecret: gRes.ClientSecret,
+	})
+}
+
+// @id ListOAuthClients
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @success 200 {object} ListOAuthClientsResponse
+// @router /api/v1/oauth-clients [GET]
+func (h *Handler) ListOAuthClients(ctx *gin.Context) {
+	gRes, err := h.begotc.ListOAuthClients(ctx, &userv1.ListOAuthClientsRequest{})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	datas, err := util.MapToSlice(mapToOAuthClient, gRes.Data)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ListOAuthClientsResponse{
+		Data: datas,
+	})
+}
+
+// @id DeleteOAuthClient
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags APIKey
+// @param id path string true "id"
+// @success 200 {object} DeleteOAuthClientResponse
+// @router /api/v1/oauth-clients/{id} [DELETE]
+func (h *Handler) DeleteOAuthClient(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	if _, err := h.begotc.DeleteOAuthClient(ctx, &userv1.DeleteOAuthClientRequest{
+		Id: id,
+	}); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &DeleteOAuthClientResponse{
+		Message: "Deleted successfully",
+	})
+}
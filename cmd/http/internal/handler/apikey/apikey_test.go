@@ -0,0 +1,406 @@
+package apikey
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/client/backendgolangtest"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	pbmock "github.com/nuea/backend-golang-test/proto/mock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func setupTestRequest(t *testing.T, method, path string, payload interface{}) (*httptest.ResponseRecorder, *gin.Context) {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+
+	var body *bytes.Buffer
+	if str, ok := payload.(string); ok {
+		body = bytes.NewBufferString(str)
+	} else if payload != nil {
+		jb, err := json.Marshal(payload)
+		assert.NoError(t, err)
+		body = bytes.NewBuffer(jb)
+	} else {
+		body = bytes.NewBuffer(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+
+	assert.NoError(t, err)
+	ctx.Request = req
+
+	return rec, ctx
+}
+
+func TestProvideAPIKeyHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+
+		h := ProvideAPIKeyHandler(&client.GRPCClients{
+			BackendGolangTestGRPCService: &backendgolangtest.BackendGolangTestGRPCService{
+				APIKeyServiceClient: maksc,
+			},
+		})
+		assert.NotNil(t, h)
+	})
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+	path := "/api/v1/api-keys"
+
+	t.Run("success", func(t *testing.T) {
+		expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		req := &CreateAPIKeyRequest{
+			Name:      "batch",
+			Scopes:    []string{"users:read"},
+			ExpiresAt: &expiresAt,
+		}
+		gReq := &userv1.CreateAPIKeyRequest{
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+			ExpiresAt: timestamppb.New(expiresAt),
+		}
+		gRes := &userv1.CreateAPIKeyResponse{
+			ApiKey: &userv1.APIKey{
+				Id:        "686b6ce8dbf72bfc4d0fef95",
+				Name:      "batch",
+				Prefix:    "bgt_abcdefgh",
+				Scopes:    req.Scopes,
+				CreatedAt: timestamppb.Now(),
+				ExpiresAt: timestamppb.New(expiresAt),
+			},
+			Key: "bgt_abcdefghijklmnop",
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		maksc.EXPECT().CreateAPIKey(ctx, gReq).Return(gRes, nil).Times(1)
+		h.CreateAPIKey(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res CreateAPIKeyResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "bgt_abcdefghijklmnop", res.Key)
+		assert.Equal(t, "bgt_abcdefgh", res.Prefix)
+		assert.Equal(t, expiresAt, *res.ExpiresAt)
+	})
+
+	t.Run("bad request - validation failed", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &CreateAPIKeyRequest{})
+		h.CreateAPIKey(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "name is required")
+	})
+
+	t.Run("permission denied - called with an api key", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &CreateAPIKeyRequest{Name: "batch"})
+		msgerr := status.Error(codes.PermissionDenied, "This method cannot be called with an API key.")
+		maksc.EXPECT().CreateAPIKey(ctx, gomock.Any()).Return(nil, msgerr).Times(1)
+		h.CreateAPIKey(ctx)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "This method cannot be called with an API key.")
+	})
+}
+
+func TestListAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		gRes := &userv1.ListAPIKeysResponse{
+			Data: []*userv1.APIKey{
+				{Id: "686b6ce8dbf72bfc4d0fef95", Name: "batch", LastUsedAt: timestamppb.Now()},
+			},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, "/api/v1/api-keys?service_account_id=sa", nil)
+		maksc.EXPECT().ListAPIKeys(ctx, &userv1.ListAPIKeysRequest{ServiceAccountId: ptr.String("sa")}).Return(gRes, nil).Times(1)
+		h.ListAPIKeys(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res ListAPIKeysResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "batch", res.Data[0].Name)
+		assert.NotNil(t, res.Data[0].LastUsedAt)
+	})
+
+	t.Run("internal server error - gRPC", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodGet, "/api/v1/api-keys", nil)
+		maksc.EXPECT().ListAPIKeys(ctx, gomock.Any()).Return(nil, status.Error(codes.Internal, "internal server error")).Times(1)
+		h.ListAPIKeys(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+	path := "/api/v1/api-keys"
+	kid := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: kid})
+		maksc.EXPECT().RevokeAPIKey(ctx, &userv1.RevokeAPIKeyRequest{Id: kid}).Return(&userv1.RevokeAPIKeyResponse{}, nil).Times(1)
+		h.RevokeAPIKey(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Revoked successfully")
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		h.RevokeAPIKey(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "path parameter is missing.")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: kid})
+		maksc.EXPECT().RevokeAPIKey(ctx, gomock.Any()).Return(nil, status.Error(codes.NotFound, "API key not found.")).Times(1)
+		h.RevokeAPIKey(ctx)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestCreateServiceAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+	path := "/api/v1/service-accounts"
+
+	t.Run("success", func(t *testing.T) {
+		req := &CreateServiceAccountRequest{Name: "batch", Roles: []string{"support"}}
+		gReq := &userv1.CreateServiceAccountRequest{Name: req.Name, Roles: req.Roles}
+		gRes := &userv1.CreateServiceAccountResponse{
+			ServiceAccount: &userv1.ServiceAccount{Id: "686b6ce8dbf72bfc4d0fef95", Name: "batch", Roles: req.Roles, CreatedAt: timestamppb.Now()},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		maksc.EXPECT().CreateServiceAccount(ctx, gReq).Return(gRes, nil).Times(1)
+		h.CreateServiceAccount(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res CreateServiceAccountResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "686b6ce8dbf72bfc4d0fef95", res.ID)
+	})
+
+	t.Run("conflict - already exists", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &CreateServiceAccountRequest{Name: "batch"})
+		maksc.EXPECT().CreateServiceAccount(ctx, gomock.Any()).Return(nil, status.Error(codes.AlreadyExists, "Service account already exists.")).Times(1)
+		h.CreateServiceAccount(ctx)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestListServiceAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		gRes := &userv1.ListServiceAccountsResponse{
+			Data: []*userv1.ServiceAccount{{Id: "686b6ce8dbf72bfc4d0fef95", Name: "batch"}},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, "/api/v1/service-accounts", nil)
+		maksc.EXPECT().ListServiceAccounts(ctx, &userv1.ListServiceAccountsRequest{}).Return(gRes, nil).Times(1)
+		h.ListServiceAccounts(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res ListServiceAccountsResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "batch", res.Data[0].Name)
+	})
+}
+
+func TestDeleteServiceAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+	path := "/api/v1/service-accounts"
+	said := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: said})
+		maksc.EXPECT().DeleteServiceAccount(ctx, &userv1.DeleteServiceAccountRequest{Id: said}).Return(&userv1.DeleteServiceAccountResponse{}, nil).Times(1)
+		h.DeleteServiceAccount(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Deleted successfully")
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		h.DeleteServiceAccount(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestCreateOAuthClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+	path := "/api/v1/oauth-clients"
+
+	t.Run("success", func(t *testing.T) {
+		req := &CreateOAuthClientRequest{Name: "reporting", Scopes: []string{"users:read"}}
+		gReq := &userv1.CreateOAuthClientRequest{Name: req.Name, Scopes: req.Scopes}
+		gRes := &userv1.CreateOAuthClientResponse{
+			OauthClient:  &userv1.OAuthClient{Id: "686b6ce8dbf72bfc4d0fef95", ClientId: "client", Name: "reporting", Scopes: req.Scopes, CreatedAt: timestamppb.Now()},
+			ClientSecret: "bgtcs_secret",
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		maksc.EXPECT().CreateOAuthClient(ctx, gReq).Return(gRes, nil).Times(1)
+		h.CreateOAuthClient(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res CreateOAuthClientResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "client", res.ClientID)
+		assert.Equal(t, "bgtcs_secret", res.ClientSecret)
+	})
+
+	t.Run("bad request - name is required", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &CreateOAuthClientRequest{Scopes: []string{"users:read"}})
+		h.CreateOAuthClient(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "name is required.")
+	})
+
+	t.Run("forbidden - scope not granted to the caller", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &CreateOAuthClientRequest{Name: "reporting", Scopes: []string{"users:delete"}})
+		maksc.EXPECT().CreateOAuthClient(ctx, gomock.Any()).Return(nil, status.Error(codes.PermissionDenied, "Scope users:delete is not granted to the caller.")).Times(1)
+		h.CreateOAuthClient(ctx)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestListOAuthClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		gRes := &userv1.ListOAuthClientsResponse{
+			Data: []*userv1.OAuthClient{{Id: "686b6ce8dbf72bfc4d0fef95", ClientId: "client", Name: "reporting"}},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, "/api/v1/oauth-clients", nil)
+		maksc.EXPECT().ListOAuthClients(ctx, &userv1.ListOAuthClientsRequest{}).Return(gRes, nil).Times(1)
+		h.ListOAuthClients(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res ListOAuthClientsResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "client", res.Data[0].ClientID)
+	})
+}
+
+func TestDeleteOAuthClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	maksc := pbmock.NewMockAPIKeyServiceClient(ctrl)
+	h := &Handler{
+		begotc: maksc,
+	}
+	path := "/api/v1/oauth-clients"
+	id := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: id})
+		maksc.EXPECT().DeleteOAuthClient(ctx, &userv1.DeleteOAuthClientRequest{Id: id}).Return(&userv1.DeleteOAuthClientResponse{}, nil).Times(1)
+		h.DeleteOAuthClient(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Deleted successfully")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: id})
+		maksc.EXPECT().DeleteOAuthClient(ctx, &userv1.DeleteOAuthClientRequest{Id: id}).Return(nil, status.Error(codes.NotFound, "OAuth client not found.")).Times(1)
+		h.DeleteOAuthClient(ctx)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		h.DeleteOAuthClient(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,54 @@
+package apikey
+
+import (
+	"github.com/gotidy/ptr"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+)
+
+func mapToAPIKey(k *userv1.APIKey) (*APIKey, error) {
+	response := &APIKey{
+		ID:        k.Id,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		OwnerID:   k.OwnerId,
+		OwnerType: k.OwnerType,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt.AsTime(),
+	}
+
+	if k.ExpiresAt != nil {
+		response.ExpiresAt = ptr.Of(k.ExpiresAt.AsTime())
+	}
+
+	if k.LastUsedAt != nil {
+		response.LastUsedAt = ptr.Of(k.LastUsedAt.AsTime())
+	}
+
+	if k.RevokedAt != nil {
+		response.RevokedAt = ptr.Of(k.RevokedAt.AsTime())
+	}
+
+	return response, nil
+}
+
+func mapToServiceAccount(sa *userv1.ServiceAccount) (*ServiceAccount, error) {
+	return &ServiceAccount{
+		ID:          sa.Id,
+		Name:        sa.Name,
+		Description: sa.Description,
+		Roles:       sa.Roles,
+		CreatedBy:   sa.CreatedBy,
+		CreatedAt:   sa.CreatedAt.AsTime(),
+	}, nil
+}
+
+func mapToOAuthClient(c *userv1.OAuthClient) (*OAuthClient, error) {
+	return &OAuthClient{
+		ID:        c.Id,
+		ClientID:  c.ClientId,
+		Name:      c.Name,
+		Scopes:    c.Scopes,
+		CreatedBy: c.CreatedBy,
+		CreatedAt: c.CreatedAt.AsTime(),
+	}, nil
+}
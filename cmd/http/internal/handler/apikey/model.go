@@ -0,0 +1,97 @@
+package apikey
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name             string     `json:"name" validate:"required"`
+	Scopes           []string   `json:"scopes"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	ServiceAccountID *string    `json:"service_account_id,omitempty"`
+}
+
+// CreateAPIKeyResponse carries the key in clear, it cannot be read again.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type ListAPIKeysRequest struct {
+	ServiceAccountID *string `form:"service_account_id,omitempty"`
+}
+
+type ListAPIKeysResponse struct {
+	Data []*APIKey `json:"data"`
+}
+
+type RevokeAPIKeyResponse struct {
+	Message string `json:"message"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+}
+
+type CreateServiceAccountResponse struct {
+	ServiceAccount
+}
+
+type ListServiceAccountsResponse struct {
+	Data []*ServiceAccount `json:"data"`
+}
+
+type DeleteServiceAccountResponse struct {
+	Message string `json:"message"`
+}
+
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name" validate:"required"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateOAuthClientResponse carries the secret in clear, it cannot be read
+// again.
+type CreateOAuthClientResponse struct {
+	OAuthClient
+	ClientSecret string `json:"client_secret"`
+}
+
+type ListOAuthClientsResponse struct {
+	Data []*OAuthClient `json:"data"`
+}
+
+type DeleteOAuthClientResponse struct {
+	Message string `json:"message"`
+}
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	OwnerID    string     `json:"owner_id"`
+	OwnerType  string     `json:"owner_type"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type ServiceAccount struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Roles       []string  `json:"roles"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type OAuthClient struct {
+	ID        string    `json:"id"`
+	ClientID  string    `json:"client_id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,13 +1,22 @@
 package auth
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/nuea/backend-golang-test/internal/service"
 	"github.com/nuea/backend-golang-test/internal/service/auth"
 	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Handler struct {
@@ -23,6 +32,7 @@ func ProvideAuthHandler(sv *service.Service) *Handler {
 // @id Login
 // @accept  json
 // @produce  json
+// @security DeviceID
 // @tags Auth
 // @param req body LoginRequest true "req"
 // @success 200 {object} LoginResponse
@@ -48,15 +58,566 @@ func (h *Handler) Login(ctx *gin.Context) {
 		Password: req.Password,
 	}
 
-	ac, err := h.authsv.Login(ctx, gReq)
+	tp, err := h.authsv.Login(ctx, gReq)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		if retryAfter, ok := util.RetryAfterFromError(err); ok {
+			ctx.Header("Retry-After", strconv.FormatInt(int64(retryAfter/time.Second), 10))
+		}
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, &LoginResponse{
-		AccessToken: ac,
+		AccessToken:  tp.AccessToken,
+		RefreshToken: tp.RefreshToken,
+		CSRFToken:    tp.CSRFToken,
+		SessionID:    tp.SessionID,
+		MFARequired:  tp.MFARequired,
+		MFAToken:     tp.MFAToken,
+	})
+}
+
+// @id VerifyMFA
+// @accept  json
+// @produce  json
+// @security DeviceID
+// @tags Auth
+// @param req body VerifyMFARequest true "req"
+// @success 200 {object} VerifyMFAResponse
+// @router /api/v1/login/mfa [POST]
+func (h *Handler) VerifyMFA(ctx *gin.Context) {
+	var req *VerifyMFARequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tp, err := h.authsv.VerifyMFA(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		if retryAfter, ok := util.RetryAfterFromError(err); ok {
+			ctx.Header("Retry-After", strconv.FormatInt(int64(retryAfter/time.Second), 10))
+		}
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &VerifyMFAResponse{
+		AccessToken:  tp.AccessToken,
+		RefreshToken: tp.RefreshToken,
+		CSRFToken:    tp.CSRFToken,
+		SessionID:    tp.SessionID,
+	})
+}
+
+// @id RefreshToken
+// @accept  json
+// @produce  json
+// @security DeviceID
+// @tags Auth
+// @param req body RefreshTokenRequest true "req"
+// @success 200 {object} RefreshTokenResponse
+// @router /api/v1/token/refresh [POST]
+func (h *Handler) RefreshToken(ctx *gin.Context) {
+	var req *RefreshTokenRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tp, err := h.authsv.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &RefreshTokenResponse{
+		AccessToken:  tp.AccessToken,
+		RefreshToken: tp.RefreshToken,
+		CSRFToken:    tp.CSRFToken,
+		SessionID:    tp.SessionID,
+	})
+}
+
+// @id Logout
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param req body LogoutRequest false "req"
+// @success 200 {object} LogoutResponse
+// @router /api/v1/logout [POST]
+func (h *Handler) Logout(ctx *gin.Context) {
+	var req LogoutRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authsv.Logout(ctx, req.RefreshToken); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &LogoutResponse{
+		Message: "Logged out successfully",
+	})
+}
+
+// @id ChangePassword
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param req body ChangePasswordRequest true "req"
+// @success 200 {object} ChangePasswordResponse
+// @router /api/v1/password/change [POST]
+func (h *Handler) ChangePassword(ctx *gin.Context) {
+	var req *ChangePasswordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authsv.ChangePassword(ctx, req.CurrentPassword, req.NewPassword); err != nil {
+		body := gin.H{"error": err.Error()}
+		if violations := util.FieldViolationsFromError(err); len(violations) > 0 {
+			body["violations"] = violations
+		}
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), body)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ChangePasswordResponse{
+		Message: "Password changed successfully",
+	})
+}
+
+// @id ForgotPassword
+// @accept  json
+// @produce  json
+// @tags Auth
+// @param req body ForgotPasswordRequest true "req"
+// @success 200 {object} ForgotPasswordResponse
+// @router /api/v1/password/forgot [POST]
+func (h *Handler) ForgotPassword(ctx *gin.Context) {
+	var req *ForgotPasswordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authsv.ForgotPassword(ctx, req.Email); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ForgotPasswordResponse{
+		Message: "If the email is registered, a reset link has been sent",
+	})
+}
+
+// @id ResetPassword
+// @accept  json
+// @produce  json
+// @tags Auth
+// @param req body ResetPasswordRequest true "req"
+// @success 200 {object} ResetPasswordResponse
+// @router /api/v1/password/reset [POST]
+func (h *Handler) ResetPassword(ctx *gin.Context) {
+	var req *ResetPasswordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authsv.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		body := gin.H{"error": err.Error()}
+		if violations := util.FieldViolationsFromError(err); len(violations) > 0 {
+			body["violations"] = violations
+		}
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), body)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ResetPasswordResponse{
+		Message: "Password reset successfully",
+	})
+}
+
+// @id JWKS
+// @produce  json
+// @tags Auth
+// @success 200 {object} token.JSONWebKeySet
+// @router /.well-known/jwks.json [GET]
+func (h *Handler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.authsv.JWKS())
+}
+
+// @id EnrollMFA
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @success 200 {object} EnrollMFAResponse
+// @router /api/v1/mfa/enroll [POST]
+func (h *Handler) EnrollMFA(ctx *gin.Context) {
+	res, err := h.authsv.EnrollMFA(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &EnrollMFAResponse{
+		Secret:     res.Secret,
+		OtpauthURI: res.OtpauthUri,
+	})
+}
+
+// @id ConfirmMFA
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param req body ConfirmMFARequest true "req"
+// @success 200 {object} ConfirmMFAResponse
+// @router /api/v1/mfa/confirm [POST]
+func (h *Handler) ConfirmMFA(ctx *gin.Context) {
+	var req *ConfirmMFARequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	recoveryCodes, err := h.authsv.ConfirmMFA(ctx, req.Code)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ConfirmMFAResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// @id DisableMFA
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param req body DisableMFARequest true "req"
+// @success 200 {object} DisableMFAResponse
+// @router /api/v1/mfa/disable [POST]
+func (h *Handler) DisableMFA(ctx *gin.Context) {
+	var req *DisableMFARequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authsv.DisableMFA(ctx, req.Password, req.Code); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &DisableMFAResponse{
+		Message: "MFA disabled successfully",
+	})
+}
+
+// @id BeginOIDCLogin
+// @tags Auth
+// @success 302
+// @router /api/v1/oidc/login [GET]
+func (h *Handler) BeginOIDCLogin(ctx *gin.Context) {
+	authURL, err := h.authsv.BeginOIDCLogin(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// @id CompleteOIDCLogin
+// @produce  json
+// @security DeviceID
+// @tags Auth
+// @param req query OIDCCallbackRequest true "req"
+// @success 200 {object} LoginResponse
+// @router /api/v1/oidc/callback [GET]
+func (h *Handler) CompleteOIDCLogin(ctx *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// The identity provider redirects back with an error when the user
+	// declined or the request was refused.
+	if req.Error != "" {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": strings.TrimSpace(req.Error + " " + req.ErrorDescription),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tp, err := h.authsv.CompleteOIDCLogin(ctx, req.State, req.Code)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &LoginResponse{
+		AccessToken:  tp.AccessToken,
+		RefreshToken: tp.RefreshToken,
+		CSRFToken:    tp.CSRFToken,
+		SessionID:    tp.SessionID,
+		MFARequired:  tp.MFARequired,
+		MFAToken:     tp.MFAToken,
+	})
+}
+
+// Token implements the client_credentials grant of RFC 6749. The client
+// authenticates with HTTP Basic, or with client_id and client_secret in the
+// form. Errors carry the OAuth error code in "error", as clients expect.
+// @id Token
+// @accept  x-www-form-urlencoded
+// @produce  json
+// @security ClientBasicAuth
+// @tags Auth
+// @param req formData OAuthTokenRequest true "req"
+// @success 200 {object} OAuthTokenResponse
+// @router /oauth/token [POST]
+func (h *Handler) Token(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var req OAuthTokenRequest
+	if err := ctx.ShouldBindWith(&req, binding.Form); err != nil {
+		oauthError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		oauthError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if req.GrantType != "client_credentials" {
+		oauthError(ctx, http.StatusBadRequest, "unsupported_grant_type", "Grant type is not supported.")
+		return
+	}
+
+	clientID, clientSecret, basic := ctx.Request.BasicAuth()
+	if basic {
+		if req.ClientID != "" || req.ClientSecret != "" {
+			oauthError(ctx, http.StatusBadRequest, "invalid_request", "Only one client authentication method may be used.")
+			return
+		}
+
+		// The credentials are form encoded before they are put in the
+		// header, see RFC 6749 section 2.3.1.
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err == nil {
+			clientSecret, err = url.QueryUnescape(clientSecret)
+		}
+		if err != nil {
+			oauthError(ctx, http.StatusBadRequest, "invalid_request", "Client credentials are malformed.")
+			return
+		}
+	} else {
+		clientID, clientSecret = req.ClientID, req.ClientSecret
+	}
+
+	ct, err := h.authsv.ClientCredentials(ctx, clientID, clientSecret, strings.Fields(req.Scope))
+	if err != nil {
+		msg := status.Convert(err).Message()
+		switch status.Code(err) {
+		case codes.Unauthenticated:
+			if basic {
+				ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			}
+			oauthError(ctx, http.StatusUnauthorized, "invalid_client", msg)
+		case codes.PermissionDenied:
+			oauthError(ctx, http.StatusBadRequest, "invalid_scope", msg)
+		default:
+			oauthError(ctx, util.HTTPStatusFromError(err), "server_error", msg)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &OAuthTokenResponse{
+		AccessToken: ct.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ct.ExpiresIn / time.Second),
+		Scope:       strings.Join(ct.Scopes, " "),
+	})
+}
+
+// Introspect implements RFC 7662 for services that receive our access
+// tokens. The caller authenticates with its own token, which needs the
+// tokens:introspect permission. Only access tokens can be introspected, a
+// refresh token is reported as invalid.
+// @id Introspect
+// @accept  x-www-form-urlencoded
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param req formData OAuthIntrospectRequest true "req"
+// @success 200 {object} OAuthIntrospectResponse
+// @router /oauth/introspect [POST]
+func (h *Handler) Introspect(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var req OAuthIntrospectRequest
+	if err := ctx.ShouldBindWith(&req, binding.Form); err != nil {
+		oauthError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if err := util.ValidateStruct(&req); err != nil {
+		oauthError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	res, err := h.authsv.IntrospectToken(ctx, req.Token)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapToIntrospectResponse(res))
+}
+
+func mapToIntrospectResponse(res *userv1.IntrospectTokenResponse) *OAuthIntrospectResponse {
+	if !res.Active {
+		return &OAuthIntrospectResponse{Status: res.Status}
+	}
+
+	out := &OAuthIntrospectResponse{
+		Active:    true,
+		Status:    res.Status,
+		Sub:       res.Subject,
+		ClientID:  res.ClientId,
+		SessionID: res.SessionId,
+		Roles:     res.Roles,
+		Scope:     strings.Join(res.Scopes, " "),
+		TokenType: "Bearer",
+		Exp:       res.ExpiresAt.AsTime().Unix(),
+		Jti:       res.TokenId,
+		Iss:       res.Issuer,
+		Aud:       res.Audience,
+	}
+	if res.Actor != "" {
+		out.Act = &OAuthIntrospectActor{Sub: res.Actor}
+	}
+	if res.IssuedAt != nil {
+		out.Iat = res.IssuedAt.AsTime().Unix()
+	}
+	return out
+}
+
+// oauthError writes an error response of RFC 6749 section 5.2.
+func oauthError(ctx *gin.Context, code int, err, description string) {
+	ctx.AbortWithStatusJSON(code, &OAuthErrorResponse{
+		Error:            err,
+		ErrorDescription: description,
 	})
 }
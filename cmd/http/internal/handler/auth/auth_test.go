@@ -8,13 +8,20 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nuea/backend-golang-test/internal/service"
 	"github.com/nuea/backend-golang-test/internal/service/auth"
+	"github.com/nuea/backend-golang-test/internal/service/password"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type mockAuthService struct {
@@ -22,12 +29,129 @@ type mockAuthService struct {
 	auth.AuthService
 }
 
-func (m *mockAuthService) Login(ctx context.Context, req *userv1.LoginRequest) (accessToken string, err error) {
+func (m *mockAuthService) Login(ctx context.Context, req *userv1.LoginRequest) (*auth.TokenPair, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
-		return "", args.Error(1)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).(string), args.Error(1)
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *mockAuthService) RefreshToken(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *mockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	args := m.Called(ctx, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) ForgotPassword(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) VerifyMFA(ctx context.Context, mfaToken, code string) (*auth.TokenPair, error) {
+	args := m.Called(ctx, mfaToken, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *mockAuthService) EnrollMFA(ctx context.Context) (*userv1.EnrollMFAResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userv1.EnrollMFAResponse), args.Error(1)
+}
+
+func (m *mockAuthService) ConfirmMFA(ctx context.Context, code string) ([]string, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockAuthService) DisableMFA(ctx context.Context, password, code string) error {
+	args := m.Called(ctx, password, code)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) BeginOIDCLogin(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockAuthService) CompleteOIDCLogin(ctx context.Context, state, code string) (*auth.TokenPair, error) {
+	args := m.Called(ctx, state, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *mockAuthService) ClientCredentials(ctx context.Context, clientID, clientSecret string, scopes []string) (*auth.ClientToken, error) {
+	args := m.Called(ctx, clientID, clientSecret, scopes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.ClientToken), args.Error(1)
+}
+
+func (m *mockAuthService) ListSessions(ctx context.Context, userID string) ([]*userv1.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*userv1.Session), args.Error(1)
+}
+
+func (m *mockAuthService) RevokeSession(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) RevokeAllSessions(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) IntrospectToken(ctx context.Context, accessToken string) (*userv1.IntrospectTokenResponse, error) {
+	args := m.Called(ctx, accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userv1.IntrospectTokenResponse), args.Error(1)
+}
+
+func (m *mockAuthService) ImpersonateUser(ctx context.Context, userID, reason string) (*userv1.ImpersonateUserResponse, error) {
+	args := m.Called(ctx, userID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userv1.ImpersonateUserResponse), args.Error(1)
+}
+
+func (m *mockAuthService) JWKS() *token.JSONWebKeySet {
+	args := m.Called()
+	return args.Get(0).(*token.JSONWebKeySet)
 }
 
 func TestProvideHandler(t *testing.T) {
@@ -80,8 +204,8 @@ func TestLogin(t *testing.T) {
 		}
 
 		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
-		ac := "fake-access-token"
-		sv.On("Login", ctx, greq).Return(ac, nil).Once()
+		tp := &auth.TokenPair{AccessToken: "fake-access-token", RefreshToken: "fake-refresh-token", CSRFToken: "fake-csrf-token"}
+		sv.On("Login", ctx, greq).Return(tp, nil).Once()
 
 		h.Login(ctx)
 
@@ -89,7 +213,9 @@ func TestLogin(t *testing.T) {
 		var response LoginResponse
 		err := json.Unmarshal(rec.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, ac, response.AccessToken)
+		assert.Equal(t, tp.AccessToken, response.AccessToken)
+		assert.Equal(t, tp.RefreshToken, response.RefreshToken)
+		assert.Equal(t, tp.CSRFToken, response.CSRFToken)
 
 		sv.AssertExpectations(t)
 	})
@@ -142,4 +268,770 @@ func TestLogin(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), msgerr.Error())
 		sv.AssertExpectations(t)
 	})
+
+	t.Run("mfa required", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &LoginRequest{
+			Email:    "test@example.com",
+			Password: "password",
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("Login", ctx, mock.Anything).Return(&auth.TokenPair{MFARequired: true, MFAToken: "mfa-token"}, nil).Once()
+
+		h.Login(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"mfa_required":true,"mfa_token":"mfa-token"}`, rec.Body.String())
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("too many requests - account locked", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &LoginRequest{
+			Email:    "test@example.com",
+			Password: "password",
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+
+		msgerr := util.ErrorWithRetryAfter(codes.ResourceExhausted, "Too many failed login attempts, try again in 60 seconds.", time.Minute)
+		sv.On("Login", ctx, mock.Anything).Return(nil, msgerr).Once()
+
+		h.Login(ctx)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		sv.AssertExpectations(t)
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/token/refresh"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &RefreshTokenRequest{
+			RefreshToken: "fake-refresh-token",
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		tp := &auth.TokenPair{AccessToken: "new-access-token", RefreshToken: "new-refresh-token"}
+		sv.On("RefreshToken", ctx, req.RefreshToken).Return(tp, nil).Once()
+
+		h.RefreshToken(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response RefreshTokenResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tp.AccessToken, response.AccessToken)
+		assert.Equal(t, tp.RefreshToken, response.RefreshToken)
+
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - invalid json", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, "invalid json")
+		h.RefreshToken(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid character")
+	})
+
+	t.Run("bad request - validation failed", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &RefreshTokenRequest{})
+		h.RefreshToken(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "refresh_token is required")
+	})
+
+	t.Run("unauthorized - revoked token", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &RefreshTokenRequest{
+			RefreshToken: "reused-refresh-token",
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		msgerr := status.Error(codes.Unauthenticated, "Refresh token is revoked.")
+		sv.On("RefreshToken", ctx, req.RefreshToken).Return(nil, msgerr).Once()
+
+		h.RefreshToken(ctx)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Refresh token is revoked.")
+		sv.AssertExpectations(t)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/logout"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &LogoutRequest{RefreshToken: "fake-refresh-token"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("Logout", ctx, req.RefreshToken).Return(nil).Once()
+
+		h.Logout(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response LogoutResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "Logged out successfully", response.Message)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("success - without body", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		sv.On("Logout", ctx, "").Return(nil).Once()
+
+		h.Logout(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - invalid json", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, "invalid json")
+		h.Logout(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid character")
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		msgerr := errors.New("internal server error")
+		sv.On("Logout", ctx, "").Return(msgerr).Once()
+
+		h.Logout(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), msgerr.Error())
+		sv.AssertExpectations(t)
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/.well-known/jwks.json"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		jwks := &token.JSONWebKeySet{
+			Keys: []token.JSONWebKey{{Kty: "RSA", Kid: "rsa-1", Use: "sig", Alg: "RS256", N: "n", E: "AQAB"}},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		sv.On("JWKS").Return(jwks).Once()
+
+		h.JWKS(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "public, max-age=300", rec.Header().Get("Cache-Control"))
+		var response token.JSONWebKeySet
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, jwks, &response)
+		sv.AssertExpectations(t)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/password/change"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ChangePasswordRequest{CurrentPassword: "password", NewPassword: "new-password"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ChangePassword", ctx, req.CurrentPassword, req.NewPassword).Return(nil).Once()
+
+		h.ChangePassword(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Password changed successfully")
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - missing field", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ChangePasswordRequest{CurrentPassword: "password"})
+		h.ChangePassword(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "new_password is required.")
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-password"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ChangePassword", ctx, req.CurrentPassword, req.NewPassword).
+			Return(status.Error(codes.InvalidArgument, "Password is invalid.")).Once()
+
+		h.ChangePassword(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Password is invalid.")
+	})
+
+	t.Run("password policy violation", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ChangePasswordRequest{CurrentPassword: "password", NewPassword: "short"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ChangePassword", ctx, req.CurrentPassword, req.NewPassword).
+			Return((&password.PolicyError{Violations: []password.Violation{{Reason: "min_length", Message: "Password must be at least 8 characters."}}}).GRPCStatus().Err()).Once()
+
+		h.ChangePassword(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"violations":[{"field":"password","reason":"min_length","message":"Password must be at least 8 characters."}]`)
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/password/forgot"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ForgotPasswordRequest{Email: "test@example.com"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ForgotPassword", ctx, req.Email).Return(nil).Once()
+
+		h.ForgotPassword(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - missing email", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ForgotPasswordRequest{})
+		h.ForgotPassword(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "email is required.")
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/password/reset"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ResetPasswordRequest{Token: "reset-token", NewPassword: "new-password"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ResetPassword", ctx, req.Token, req.NewPassword).Return(nil).Once()
+
+		h.ResetPassword(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Password reset successfully")
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ResetPasswordRequest{Token: "reset-token", NewPassword: "new-password"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ResetPassword", ctx, req.Token, req.NewPassword).
+			Return(status.Error(codes.InvalidArgument, "Reset token is invalid or expired.")).Once()
+
+		h.ResetPassword(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Reset token is invalid or expired.")
+	})
+}
+
+func TestVerifyMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/login/mfa"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &VerifyMFARequest{MFAToken: "mfa-token", Code: "123456"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		tp := &auth.TokenPair{AccessToken: "fake-access-token", RefreshToken: "fake-refresh-token"}
+		sv.On("VerifyMFA", ctx, req.MFAToken, req.Code).Return(tp, nil).Once()
+
+		h.VerifyMFA(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response VerifyMFAResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tp.AccessToken, response.AccessToken)
+		assert.Equal(t, tp.RefreshToken, response.RefreshToken)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - missing field", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &VerifyMFARequest{MFAToken: "mfa-token"})
+		h.VerifyMFA(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "code is required.")
+	})
+
+	t.Run("unauthorized - invalid mfa token", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &VerifyMFARequest{MFAToken: "mfa-token", Code: "123456"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("VerifyMFA", ctx, req.MFAToken, req.Code).
+			Return(nil, status.Error(codes.Unauthenticated, "MFA token is invalid or expired.")).Once()
+
+		h.VerifyMFA(ctx)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestEnrollMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/mfa/enroll"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		sv.On("EnrollMFA", ctx).Return(&userv1.EnrollMFAResponse{Secret: "SECRET", OtpauthUri: "otpauth://totp/test"}, nil).Once()
+
+		h.EnrollMFA(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"secret":"SECRET","otpauth_uri":"otpauth://totp/test"}`, rec.Body.String())
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		sv.On("EnrollMFA", ctx).Return(nil, status.Error(codes.FailedPrecondition, "MFA is already enabled.")).Once()
+
+		h.EnrollMFA(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestConfirmMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/mfa/confirm"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ConfirmMFARequest{Code: "123456"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("ConfirmMFA", ctx, req.Code).Return([]string{"abcd-efgh"}, nil).Once()
+
+		h.ConfirmMFA(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"recovery_codes":["abcd-efgh"]}`, rec.Body.String())
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - missing field", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ConfirmMFARequest{})
+		h.ConfirmMFA(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "code is required.")
+	})
+}
+
+func TestDisableMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/mfa/disable"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &DisableMFARequest{Password: "password", Code: "123456"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("DisableMFA", ctx, req.Password, req.Code).Return(nil).Once()
+
+		h.DisableMFA(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "MFA disabled successfully")
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &DisableMFARequest{Password: "password", Code: "000000"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		sv.On("DisableMFA", ctx, req.Password, req.Code).Return(status.Error(codes.InvalidArgument, "Code is invalid.")).Once()
+
+		h.DisableMFA(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestBeginOIDCLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/oidc/login"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		sv.On("BeginOIDCLogin", ctx).Return("https://idp.example.com/authorize?state=state", nil).Once()
+
+		h.BeginOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://idp.example.com/authorize?state=state", rec.Header().Get("Location"))
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		sv.On("BeginOIDCLogin", ctx).Return("", status.Error(codes.FailedPrecondition, "OIDC login is not configured.")).Once()
+
+		h.BeginOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "OIDC login is not configured.")
+	})
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/oidc/callback"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path+"?code=code&state=state", nil)
+		tp := &auth.TokenPair{AccessToken: "fake-access-token", RefreshToken: "fake-refresh-token", CSRFToken: "fake-csrf-token"}
+		sv.On("CompleteOIDCLogin", ctx, "state", "code").Return(tp, nil).Once()
+
+		h.CompleteOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response LoginResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, tp.AccessToken, response.AccessToken)
+		assert.Equal(t, tp.RefreshToken, response.RefreshToken)
+		assert.Equal(t, tp.CSRFToken, response.CSRFToken)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("mfa required", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path+"?code=code&state=state", nil)
+		sv.On("CompleteOIDCLogin", ctx, "state", "code").Return(&auth.TokenPair{MFARequired: true, MFAToken: "mfa-token"}, nil).Once()
+
+		h.CompleteOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"mfa_required":true,"mfa_token":"mfa-token"}`, rec.Body.String())
+	})
+
+	t.Run("bad request - missing code", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path+"?state=state", nil)
+		h.CompleteOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "code is required.")
+	})
+
+	t.Run("unauthorized - declined at the identity provider", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path+"?error=access_denied&error_description=User+declined&state=state", nil)
+		h.CompleteOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "access_denied User declined")
+		sv.AssertNotCalled(t, "CompleteOIDCLogin", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unauthorized - state does not match", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path+"?code=code&state=state", nil)
+		sv.On("CompleteOIDCLogin", ctx, "state", "code").Return(nil, status.Error(codes.Unauthenticated, "Login state does not match.")).Once()
+
+		h.CompleteOIDCLogin(ctx)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/oauth/token"
+	ct := &auth.ClientToken{AccessToken: "fake-access-token", ExpiresIn: 15 * time.Minute, Scopes: []string{"users:read"}}
+	formRequest := func(t *testing.T, form string) (*httptest.ResponseRecorder, *gin.Context) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, form)
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return rec, ctx
+	}
+
+	t.Run("success - client secret in the form", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=client_credentials&client_id=client&client_secret=secret&scope=users:read")
+		sv.On("ClientCredentials", ctx, "client", "secret", []string{"users:read"}).Return(ct, nil).Once()
+
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"access_token":"fake-access-token","token_type":"Bearer","expires_in":900,"scope":"users:read"}`, rec.Body.String())
+	})
+
+	t.Run("success - basic auth", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=client_credentials")
+		ctx.Request.SetBasicAuth("client", "se%2Bcret")
+		sv.On("ClientCredentials", ctx, "client", "se+cret", []string{}).Return(ct, nil).Once()
+
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - missing grant type", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "client_id=client&client_secret=secret")
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid_request","error_description":"grant_type is required."}`, rec.Body.String())
+	})
+
+	t.Run("bad request - unsupported grant type", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=password&client_id=client&client_secret=secret")
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"unsupported_grant_type"`)
+	})
+
+	t.Run("bad request - two authentication methods", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=client_credentials&client_secret=secret")
+		ctx.Request.SetBasicAuth("client", "secret")
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"invalid_request"`)
+		sv.AssertNotCalled(t, "ClientCredentials", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unauthorized - invalid client", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=client_credentials")
+		ctx.Request.SetBasicAuth("client", "wrong")
+		sv.On("ClientCredentials", ctx, "client", "wrong", []string{}).Return(nil, status.Error(codes.Unauthenticated, "Client authentication failed.")).Once()
+
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, `Basic realm="oauth"`, rec.Header().Get("WWW-Authenticate"))
+		assert.JSONEq(t, `{"error":"invalid_client","error_description":"Client authentication failed."}`, rec.Body.String())
+	})
+
+	t.Run("bad request - invalid scope", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=client_credentials&client_id=client&client_secret=secret&scope=users:delete")
+		sv.On("ClientCredentials", ctx, "client", "secret", []string{"users:delete"}).Return(nil, status.Error(codes.PermissionDenied, "Scope users:delete is not allowed for this client.")).Once()
+
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"invalid_scope"`)
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "grant_type=client_credentials&client_id=client&client_secret=secret")
+		sv.On("ClientCredentials", ctx, "client", "secret", []string{}).Return(nil, errors.New("error")).Once()
+
+		h.Token(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"server_error"`)
+	})
+}
+
+func TestIntrospect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/oauth/introspect"
+	formRequest := func(t *testing.T, form string) (*httptest.ResponseRecorder, *gin.Context) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, form)
+		ctx.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return rec, ctx
+	}
+
+	t.Run("active", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "token=at")
+		sv.On("IntrospectToken", ctx, "at").Return(&userv1.IntrospectTokenResponse{
+			Active:    true,
+			Status:    "active",
+			Subject:   "cid",
+			ClientId:  "cid",
+			Scopes:    []string{"users:read", "users:write"},
+			TokenId:   "jti",
+			ExpiresAt: timestamppb.New(time.Unix(1735689600, 0)),
+		}, nil).Once()
+
+		h.Introspect(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"active":true,"status":"active","sub":"cid","client_id":"cid","scope":"users:read users:write","token_type":"Bearer","exp":1735689600,"jti":"jti"}`, rec.Body.String())
+	})
+
+	t.Run("active impersonation token", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "token=at")
+		sv.On("IntrospectToken", ctx, "at").Return(&userv1.IntrospectTokenResponse{
+			Active:    true,
+			Status:    "active",
+			Subject:   "uid",
+			Roles:     []string{"user"},
+			TokenId:   "jti",
+			ExpiresAt: timestamppb.New(time.Unix(1735689600, 0)),
+			Actor:     "admin-id",
+			Issuer:    "iss",
+			Audience:  "aud",
+			IssuedAt:  timestamppb.New(time.Unix(1735688700, 0)),
+		}, nil).Once()
+
+		h.Introspect(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"active":true,"status":"active","sub":"uid","roles":["user"],"token_type":"Bearer","exp":1735689600,"jti":"jti","iss":"iss","aud":"aud","iat":1735688700,"act":{"sub":"admin-id"}}`, rec.Body.String())
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "token=at&token_type_hint=access_token")
+		sv.On("IntrospectToken", ctx, "at").Return(&userv1.IntrospectTokenResponse{Status: "revoked"}, nil).Once()
+
+		h.Introspect(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"active":false,"status":"revoked"}`, rec.Body.String())
+	})
+
+	t.Run("bad request - missing token", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := formRequest(t, "token_type_hint=access_token")
+
+		h.Introspect(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_request")
+		sv.AssertNotCalled(t, "IntrospectToken")
+	})
 }
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/util"
+)
+
+// ImpersonateUser returns an access token to act as the user. Send it as a
+// bearer token; it cannot be refreshed and is not set as a cookie.
+// @id ImpersonateUser
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param id path string true "id"
+// @param req body ImpersonateUserRequest true "req"
+// @success 200 {object} ImpersonateUserResponse
+// @router /api/v1/users/{id}/impersonate [POST]
+func (h *Handler) ImpersonateUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	var req *ImpersonateUserRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	res, err := h.authsv.ImpersonateUser(ctx, id, req.Reason)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ImpersonateUserResponse{
+		AccessToken: res.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   res.ExpiresAt.AsTime(),
+	})
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestImpersonateUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/users/uid/impersonate"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+		req := &ImpersonateUserRequest{Reason: "ticket 42"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, req)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "uid"})
+		sv.On("ImpersonateUser", ctx, "uid", "ticket 42").Return(&userv1.ImpersonateUserResponse{
+			AccessToken: "at",
+			ExpiresAt:   timestamppb.New(time.Date(2025, 1, 1, 0, 15, 0, 0, time.UTC)),
+		}, nil).Once()
+
+		h.ImpersonateUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"access_token":"at","token_type":"Bearer","expires_at":"2025-01-01T00:15:00Z"}`, rec.Body.String())
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("bad request - missing reason", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ImpersonateUserRequest{})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "uid"})
+
+		h.ImpersonateUser(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "reason is required.")
+	})
+
+	t.Run("admin cannot be impersonated", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ImpersonateUserRequest{Reason: "ticket 42"})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "uid"})
+		sv.On("ImpersonateUser", ctx, "uid", "ticket 42").Return(nil, status.Error(codes.PermissionDenied, "This user cannot be impersonated.")).Once()
+
+		h.ImpersonateUser(ctx)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
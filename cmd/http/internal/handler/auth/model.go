@@ -1,10 +1,185 @@
 package auth
 
+import "time"
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	CSRFToken    string `json:"csrf_token,omitempty"`
+	SessionID    string `json:"session_id,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RefreshTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	CSRFToken    string `json:"csrf_token"`
+	SessionID    string `json:"session_id"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type LogoutResponse struct {
+	Message string `json:"message"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type ForgotPasswordResponse struct {
+	Message string `json:"message"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type ResetPasswordResponse struct {
+	Message string `json:"message"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type VerifyMFAResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	CSRFToken    string `json:"csrf_token"`
+	SessionID    string `json:"session_id"`
+}
+
+type EnrollMFAResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type ConfirmMFARequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type ConfirmMFAResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type DisableMFAResponse struct {
+	Message string `json:"message"`
+}
+
+type OIDCCallbackRequest struct {
+	Code             string `form:"code" json:"code" validate:"required"`
+	State            string `form:"state" json:"state" validate:"required"`
+	Error            string `form:"error" json:"error,omitempty"`
+	ErrorDescription string `form:"error_description" json:"error_description,omitempty"`
+}
+
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" validate:"required"`
+	Scope        string `form:"scope" json:"scope,omitempty"`
+	ClientID     string `form:"client_id" json:"client_id,omitempty"`
+	ClientSecret string `form:"client_secret" json:"client_secret,omitempty"`
+}
+
+type OAuthTokenResponse struct {
 	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+type OAuthIntrospectRequest struct {
+	Token         string `form:"token" json:"token" validate:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint,omitempty"`
+}
+
+// OAuthIntrospectResponse follows RFC 7662 section 2.2, status is added to
+// tell why a token is not active.
+type OAuthIntrospectResponse struct {
+	Active    bool     `json:"active"`
+	Status    string   `json:"status"`
+	Sub       string   `json:"sub,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Aud       string   `json:"aud,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	// Act names the caller of an impersonation token, RFC 8693 section 4.1.
+	Act *OAuthIntrospectActor `json:"act,omitempty"`
+}
+
+type OAuthIntrospectActor struct {
+	Sub string `json:"sub"`
+}
+
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type ImpersonateUserResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type Session struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	DeviceID     string    `json:"device_id,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Current      bool      `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Data []*Session `json:"data"`
+}
+
+type RevokeSessionResponse struct {
+	Message string `json:"message"`
+}
+
+type RevokeAllSessionsResponse struct {
+	Message string `json:"message"`
 }
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+)
+
+// @id ListSessions
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @success 200 {object} ListSessionsResponse
+// @router /api/v1/sessions [GET]
+func (h *Handler) ListSessions(ctx *gin.Context) {
+	h.listSessions(ctx, "")
+}
+
+// @id ListUserSessions
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param id path string true "id"
+// @success 200 {object} ListSessionsResponse
+// @router /api/v1/users/{id}/sessions [GET]
+func (h *Handler) ListUserSessions(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	h.listSessions(ctx, id)
+}
+
+// @id RevokeSession
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param id path string true "id"
+// @success 200 {object} RevokeSessionResponse
+// @router /api/v1/sessions/{id} [DELETE]
+func (h *Handler) RevokeSession(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	if err := h.authsv.RevokeSession(ctx, id); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &RevokeSessionResponse{
+		Message: "Revoked successfully",
+	})
+}
+
+// @id RevokeAllSessions
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @success 200 {object} RevokeAllSessionsResponse
+// @router /api/v1/sessions [DELETE]
+func (h *Handler) RevokeAllSessions(ctx *gin.Context) {
+	h.revokeAllSessions(ctx, "")
+}
+
+// @id RevokeUserSessions
+// @produce  json
+// @security BearerAuth
+// @tags Auth
+// @param id path string true "id"
+// @success 200 {object} RevokeAllSessionsResponse
+// @router /api/v1/users/{id}/sessions [DELETE]
+func (h *Handler) RevokeUserSessions(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	h.revokeAllSessions(ctx, id)
+}
+
+func (h *Handler) listSessions(ctx *gin.Context, userID string) {
+	sessions, err := h.authsv.ListSessions(ctx, userID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	datas, err := util.MapToSlice(mapToSession, sessions)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ListSessionsResponse{
+		Data: datas,
+	})
+}
+
+func (h *Handler) revokeAllSessions(ctx *gin.Context, userID string) {
+	if err := h.authsv.RevokeAllSessions(ctx, userID); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &RevokeAllSessionsResponse{
+		Message: "Revoked successfully",
+	})
+}
+
+func mapToSession(s *userv1.Session) (*Session, error) {
+	return &Session{
+		ID:           s.Id,
+		UserID:       s.UserId,
+		DeviceID:     s.DeviceId,
+		UserAgent:    s.UserAgent,
+		IP:           s.Ip,
+		CreatedAt:    s.CreatedAt.AsTime(),
+		LastActiveAt: s.LastActiveAt.AsTime(),
+		ExpiresAt:    s.ExpiresAt.AsTime(),
+		Current:      s.Current,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestListSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/sessions"
+	now := timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		sv.On("ListSessions", ctx, "").Return([]*userv1.Session{{
+			Id:           "sid",
+			UserId:       "uid",
+			DeviceId:     "phone",
+			CreatedAt:    now,
+			LastActiveAt: now,
+			ExpiresAt:    now,
+			Current:      true,
+		}}, nil).Once()
+
+		h.ListSessions(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"device_id":"phone"`)
+		assert.Contains(t, rec.Body.String(), `"current":true`)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("sessions of another user without permission", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, "/api/v1/users/other/sessions", nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "other"})
+		sv.On("ListSessions", ctx, "other").Return(nil, status.Error(codes.PermissionDenied, "Permission denied.")).Once()
+
+		h.ListUserSessions(ctx)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := "/api/v1/sessions/sid"
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "sid"})
+		sv.On("RevokeSession", ctx, "sid").Return(nil).Once()
+
+		h.RevokeSession(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Revoked successfully")
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("session not found", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "sid"})
+		sv.On("RevokeSession", ctx, "sid").Return(status.Error(codes.NotFound, "Session not found.")).Once()
+
+		h.RevokeSession(ctx)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("missing path parameter", func(t *testing.T) {
+		h := &Handler{authsv: new(mockAuthService)}
+
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		h.RevokeSession(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodDelete, "/api/v1/sessions", nil)
+		sv.On("RevokeAllSessions", ctx, "").Return(nil).Once()
+
+		h.RevokeAllSessions(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		sv.AssertExpectations(t)
+	})
+
+	t.Run("sessions of another user", func(t *testing.T) {
+		sv := new(mockAuthService)
+		h := &Handler{authsv: sv}
+
+		rec, ctx := setupTestRequest(t, http.MethodDelete, "/api/v1/users/uid/sessions", nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "uid"})
+		sv.On("RevokeAllSessions", ctx, "uid").Return(nil).Once()
+
+		h.RevokeUserSessions(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		sv.AssertExpectations(t)
+	})
+}
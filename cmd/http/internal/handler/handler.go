@@ -2,18 +2,21 @@ package handler
 
 import (
 	"github.com/google/wire"
+	"github.com/nuea/backend-golang-test/cmd/http/internal/handler/apikey"
 	"github.com/nuea/backend-golang-test/cmd/http/internal/handler/auth"
 	"github.com/nuea/backend-golang-test/cmd/http/internal/handler/user"
 )
 
 type Handlers struct {
-	AuthHandler *auth.Handler
-	UserHandler *user.Handler
+	AuthHandler   *auth.Handler
+	UserHandler   *user.Handler
+	APIKeyHandler *apikey.Handler
 }
 
 var HandlerSet = wire.NewSet(
 	auth.ProvideAuthHandler,
 	user.ProvideUserHandler,
+	apikey.ProvideAPIKeyHandler,
 
 	wire.Struct(new(Handlers), "*"),
 )
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setETag(ctx *gin.Context, etag string) {
+	if etag != "" {
+		ctx.Header("ETag", `"`+etag+`"`)
+	}
+}
+
+// invalidETag is never the etag of a user, which is its version number.
+const invalidETag = "invalid"
+
+// ifMatch returns the etag the If-Match header requires, or "" when any
+// version may be changed. Only a single strong etag can match; anything else,
+// a weak or an unquoted etag, or a list, becomes invalidETag so that the
+// precondition fails.
+func ifMatch(ctx *gin.Context) string {
+	v := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if v == "" || v == "*" {
+		return ""
+	}
+	if len(v) > 2 && v[0] == '"' && v[len(v)-1] == '"' && !strings.Contains(v[1:len(v)-1], `"`) {
+		return v[1 : len(v)-1]
+	}
+	return invalidETag
+}
+
+// writeErrorStatus answers a failed If-Match with 412. A user changed by
+// another request at the same time is a 409 when no If-Match was sent.
+func writeErrorStatus(err error, etag string) int {
+	if etag != "" && status.Code(err) == codes.Aborted {
+		return http.StatusPreconditionFailed
+	}
+	return util.HTTPStatusFromError(err)
+}
@@ -1,18 +1,30 @@
 package user
 
 import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
 	"github.com/gotidy/ptr"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func mapToUser(user *userv1.User) (*User, error) {
 	response := &User{
-		ID:        user.Id,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedBy: user.CreatedBy,
-		CreatedAt: user.CreatedAt.AsTime(),
-		UpdatedAt: user.UpdatedAt.AsTime(),
+		ID:         user.Id,
+		Name:       user.Name,
+		Email:      user.Email,
+		CreatedBy:  user.CreatedBy,
+		CreatedAt:  user.CreatedAt.AsTime(),
+		UpdatedAt:  user.UpdatedAt.AsTime(),
+		Roles:      user.Roles,
+		MFAEnabled: user.MfaEnabled,
+	}
+
+	if user.EmailVerifiedAt != nil {
+		response.EmailVerifiedAt = ptr.Of(user.EmailVerifiedAt.AsTime())
 	}
 
 	if user.DeletedAt != nil {
@@ -21,3 +33,35 @@ func mapToUser(user *userv1.User) (*User, error) {
 
 	return response, nil
 }
+
+func mapToUserInfo(user *userv1.User) *UserInfoResponse {
+	return &UserInfoResponse{
+		Sub:           user.Id,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		UpdatedAt:     user.UpdatedAt.AsTime().Unix(),
+		Roles:         user.Roles,
+	}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// updateMask reads the comma separated update_mask query parameter.
+func updateMask(ctx *gin.Context) *fieldmaskpb.FieldMask {
+	q := ctx.Query("update_mask")
+	if q == "" {
+		return nil
+	}
+
+	mask := &fieldmaskpb.FieldMask{}
+	for _, p := range strings.Split(q, ",") {
+		mask.Paths = append(mask.Paths, strings.TrimSpace(p))
+	}
+	return mask
+}
@@ -13,21 +13,54 @@ type CreateResponse struct {
 }
 
 type GetUsersRequest struct {
-	Name  *string `form:"name,omitempty"`
-	Email *string `form:"email,omitempty"`
+	Name         *string `form:"name,omitempty"`
+	Email        *string `form:"email,omitempty"`
+	PageSize     int32   `form:"page_size,omitempty"`
+	PageToken    string  `form:"page_token,omitempty"`
+	OrderBy      string  `form:"order_by,omitempty"`
+	IncludeTotal bool    `form:"include_total,omitempty"`
+
+	NamePrefix    *string    `form:"name_prefix,omitempty"`
+	NameContains  *string    `form:"name_contains,omitempty"`
+	EmailPrefix   *string    `form:"email_prefix,omitempty"`
+	EmailContains *string    `form:"email_contains,omitempty"`
+	CreatedAfter  *time.Time `form:"created_after,omitempty"`
+	CreatedBefore *time.Time `form:"created_before,omitempty"`
+	UpdatedAfter  *time.Time `form:"updated_after,omitempty"`
+	UpdatedBefore *time.Time `form:"updated_before,omitempty"`
+	CreatedBy     *string    `form:"created_by,omitempty"`
+	Q             *string    `form:"q,omitempty"`
 }
 
 type GetUsersResponse struct {
-	Data []*User `json:"data"`
+	Data          []*User `json:"data"`
+	NextPageToken string  `json:"next_page_token,omitempty"`
+	Total         *int64  `json:"total,omitempty"`
 }
 
 type GetUserResponse struct {
 	User
 }
 
+// UserInfoResponse carries the standard claims of OpenID Connect Core
+// section 5.3.2, updated_at is in seconds.
+type UserInfoResponse struct {
+	Sub           string   `json:"sub"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	UpdatedAt     int64    `json:"updated_at"`
+	Roles         []string `json:"roles"`
+}
+
 type UpdateUserRequest struct {
 	Name  *string `json:"name,omitempty"`
-	Email *string `json:"emai,omitempty"`
+	Email *string `json:"email,omitempty"`
+}
+
+type ReplaceUserRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required"`
 }
 
 type UpdateUserResponse struct {
@@ -38,12 +71,43 @@ type DeleteUserResponse struct {
 	Message string `json:"message"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type VerifyEmailResponse struct {
+	Message string `json:"message"`
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type ResendVerificationEmailResponse struct {
+	Message string `json:"message"`
+}
+
+type GrantRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type RoleResponse struct {
+	Roles []string `json:"roles"`
+}
+
+type UnlockUserResponse struct {
+	Message string `json:"message"`
+}
+
 type User struct {
-	ID        string     `json:"id"`
-	Name      string     `json:"name"`
-	Email     string     `json:"email"`
-	CreatedBy *string    `json:"created_by,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Roles           []string   `json:"roles"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	CreatedBy       *string    `json:"created_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
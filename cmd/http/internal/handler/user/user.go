@@ -7,6 +7,7 @@ import (
 	"github.com/nuea/backend-golang-test/internal/client"
 	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type Handler struct {
@@ -47,9 +48,11 @@ func (h *Handler) CreateUser(ctx *gin.Context) {
 		Email:    req.Email,
 		Password: req.Password,
 	}); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		body := gin.H{"error": err.Error()}
+		if violations := util.FieldViolationsFromError(err); len(violations) > 0 {
+			body["violations"] = violations
+		}
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), body)
 		return
 	}
 
@@ -58,10 +61,85 @@ func (h *Handler) CreateUser(ctx *gin.Context) {
 	})
 }
 
+// @id VerifyEmail
+// @accept  json
+// @produce  json
+// @tags User
+// @param req body VerifyEmailRequest true "req"
+// @success 200 {object} VerifyEmailResponse
+// @router /api/v1/email/verify [POST]
+func (h *Handler) VerifyEmail(ctx *gin.Context) {
+	var req *VerifyEmailRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.begotc.VerifyEmail(ctx, &userv1.VerifyEmailRequest{
+		Token: req.Token,
+	}); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &VerifyEmailResponse{
+		Message: "Email verified successfully",
+	})
+}
+
+// @id ResendVerificationEmail
+// @accept  json
+// @produce  json
+// @tags User
+// @param req body ResendVerificationEmailRequest true "req"
+// @success 200 {object} ResendVerificationEmailResponse
+// @router /api/v1/email/verify/resend [POST]
+func (h *Handler) ResendVerificationEmail(ctx *gin.Context) {
+	var req *ResendVerificationEmailRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.begotc.ResendVerificationEmail(ctx, &userv1.ResendVerificationEmailRequest{
+		Email: req.Email,
+	}); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &ResendVerificationEmailResponse{
+		Message: "If the email is registered and not yet verified, a verification link has been sent",
+	})
+}
+
 // @id GetUsers
 // @accept  json
 // @produce  json
 // @security BearerAuth
+// @security APIKeyAuth
 // @tags User
 // @param req formData GetUsersRequest true "req"
 // @success 200 {object} GetUsersResponse
@@ -77,11 +155,25 @@ func (h *Handler) GetUsers(ctx *gin.Context) {
 	}
 
 	users, err := h.begotc.GetUsers(ctx, &userv1.GetUsersRequest{
-		Name:  req.Name,
-		Email: req.Email,
+		Name:          req.Name,
+		Email:         req.Email,
+		PageSize:      req.PageSize,
+		PageToken:     req.PageToken,
+		OrderBy:       req.OrderBy,
+		IncludeTotal:  req.IncludeTotal,
+		NamePrefix:    req.NamePrefix,
+		NameContains:  req.NameContains,
+		EmailPrefix:   req.EmailPrefix,
+		EmailContains: req.EmailContains,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		UpdatedAfter:  optionalTimestamp(req.UpdatedAfter),
+		UpdatedBefore: optionalTimestamp(req.UpdatedBefore),
+		CreatedBy:     req.CreatedBy,
+		Q:             req.Q,
 	})
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
 			"error": err.Error(),
 		})
 		return
@@ -96,7 +188,9 @@ func (h *Handler) GetUsers(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, &GetUsersResponse{
-		Data: datas,
+		Data:          datas,
+		NextPageToken: users.NextPageToken,
+		Total:         users.Total,
 	})
 }
 
@@ -104,9 +198,11 @@ func (h *Handler) GetUsers(ctx *gin.Context) {
 // @accept  json
 // @produce  json
 // @security BearerAuth
+// @security APIKeyAuth
 // @tags User
 // @param id path string true "id"
 // @success 200 {object} GetUserResponse
+// @header 200 {string} ETag "version of the user"
 // @router /api/v1/users/{id} [GET]
 func (h *Handler) GetUser(ctx *gin.Context) {
 	id := ctx.Param("id")
@@ -134,6 +230,7 @@ func (h *Handler) GetUser(ctx *gin.Context) {
 		})
 		return
 	}
+	setETag(ctx, gRes.User.GetEtag())
 
 	ctx.JSON(http.StatusOK, &GetUserResponse{
 		User: *user,
@@ -144,9 +241,12 @@ func (h *Handler) GetUser(ctx *gin.Context) {
 // @accept  json
 // @produce  json
 // @security BearerAuth
+// @security APIKeyAuth
 // @tags User
 // @param req body UpdateUserRequest true "req"
 // @param id path string true "id"
+// @param update_mask query string false "comma separated fields to update, a listed field missing from the body is cleared if optional, name and email cannot be cleared"
+// @param If-Match header string false "ETag of the user, the request fails with 412 when it has changed"
 // @success 200 {object} UpdateUserResponse
 // @router /api/v1/users/{id} [PATCH]
 func (h *Handler) UpdateUser(ctx *gin.Context) {
@@ -166,17 +266,82 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	if _, err := h.begotc.UpdateUser(ctx, &userv1.UpdateUserRequest{
-		Id:    id,
-		Name:  req.Name,
-		Email: req.Email,
-	}); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+	etag := ifMatch(ctx)
+	gRes, err := h.begotc.UpdateUser(ctx, &userv1.UpdateUserRequest{
+		Id:         id,
+		Name:       req.Name,
+		Email:      req.Email,
+		UpdateMask: updateMask(ctx),
+		Etag:       etag,
+	})
+	if err != nil {
+		body := gin.H{"error": err.Error()}
+		if violations := util.FieldViolationsFromError(err); len(violations) > 0 {
+			body["violations"] = violations
+		}
+		ctx.AbortWithStatusJSON(writeErrorStatus(err, etag), body)
+		return
+	}
+	setETag(ctx, gRes.GetEtag())
+
+	ctx.JSON(http.StatusOK, &UpdateUserResponse{
+		Message: "Updated successfully",
+	})
+}
+
+// @id ReplaceUser
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @param req body ReplaceUserRequest true "req"
+// @param id path string true "id"
+// @param If-Match header string false "ETag of the user, the request fails with 412 when it has changed"
+// @success 200 {object} UpdateUserResponse
+// @router /api/v1/users/{id} [PUT]
+func (h *Handler) ReplaceUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	var req *ReplaceUserRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	etag := ifMatch(ctx)
+	gRes, err := h.begotc.UpdateUser(ctx, &userv1.UpdateUserRequest{
+		Id:         id,
+		Name:       &req.Name,
+		Email:      &req.Email,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"*"}},
+		Etag:       etag,
+	})
+	if err != nil {
+		body := gin.H{"error": err.Error()}
+		if violations := util.FieldViolationsFromError(err); len(violations) > 0 {
+			body["violations"] = violations
+		}
+		ctx.AbortWithStatusJSON(writeErrorStatus(err, etag), body)
+		return
+	}
+	setETag(ctx, gRes.GetEtag())
+
 	ctx.JSON(http.StatusOK, &UpdateUserResponse{
 		Message: "Updated successfully",
 	})
@@ -186,8 +351,10 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 // @accept  json
 // @produce  json
 // @security BearerAuth
+// @security APIKeyAuth
 // @tags User
 // @param id path string true "id"
+// @param If-Match header string false "ETag of the user, the request fails with 412 when it has changed"
 // @success 200 {object} DeleteUserResponse
 // @router /api/v1/users/{id} [DELETE]
 func (h *Handler) DeleteUser(ctx *gin.Context) {
@@ -199,16 +366,252 @@ func (h *Handler) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
+	etag := ifMatch(ctx)
 	if _, err := h.begotc.DeleteUser(ctx, &userv1.DeleteUserRequest{
-		Id: id,
+		Id:   id,
+		Etag: etag,
 	}); err != nil {
+		ctx.AbortWithStatusJSON(writeErrorStatus(err, etag), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &DeleteUserResponse{
+		Message: "Deleted successfully",
+	})
+}
+
+// @id GetCurrentUser
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @success 200 {object} GetUserResponse
+// @header 200 {string} ETag "version of the user"
+// @router /api/v1/users/me [GET]
+func (h *Handler) GetCurrentUser(ctx *gin.Context) {
+	gRes, err := h.begotc.GetCurrentUser(ctx, &userv1.GetCurrentUserRequest{})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	user, err := mapToUser(gRes.User)
+	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
+	setETag(ctx, gRes.User.GetEtag())
+
+	ctx.JSON(http.StatusOK, &GetUserResponse{
+		User: *user,
+	})
+}
+
+// UserInfo returns the profile of the token's subject as the OpenID Connect
+// userinfo endpoint does. A client token has no subject and is refused.
+// @id UserInfo
+// @produce  json
+// @security BearerAuth
+// @tags User
+// @success 200 {object} UserInfoResponse
+// @router /userinfo [GET]
+func (h *Handler) UserInfo(ctx *gin.Context) {
+	gRes, err := h.begotc.GetCurrentUser(ctx, &userv1.GetCurrentUserRequest{})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapToUserInfo(gRes.User))
+}
+
+// @id UpdateCurrentUser
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @param req body UpdateUserRequest true "req"
+// @param update_mask query string false "comma separated fields to update, a listed field missing from the body is cleared if optional, name and email cannot be cleared"
+// @param If-Match header string false "ETag of the user, the request fails with 412 when it has changed"
+// @success 200 {object} UpdateUserResponse
+// @router /api/v1/users/me [PATCH]
+func (h *Handler) UpdateCurrentUser(ctx *gin.Context) {
+	var req *UpdateUserRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	etag := ifMatch(ctx)
+	gRes, err := h.begotc.UpdateCurrentUser(ctx, &userv1.UpdateCurrentUserRequest{
+		Name:       req.Name,
+		Email:      req.Email,
+		UpdateMask: updateMask(ctx),
+		Etag:       etag,
+	})
+	if err != nil {
+		body := gin.H{"error": err.Error()}
+		if violations := util.FieldViolationsFromError(err); len(violations) > 0 {
+			body["violations"] = violations
+		}
+		ctx.AbortWithStatusJSON(writeErrorStatus(err, etag), body)
+		return
+	}
+	setETag(ctx, gRes.GetEtag())
+
+	ctx.JSON(http.StatusOK, &UpdateUserResponse{
+		Message: "Updated successfully",
+	})
+}
+
+// @id DeleteCurrentUser
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @param If-Match header string false "ETag of the user, the request fails with 412 when it has changed"
+// @success 200 {object} DeleteUserResponse
+// @router /api/v1/users/me [DELETE]
+func (h *Handler) DeleteCurrentUser(ctx *gin.Context) {
+	etag := ifMatch(ctx)
+	if _, err := h.begotc.DeleteCurrentUser(ctx, &userv1.DeleteCurrentUserRequest{Etag: etag}); err != nil {
+		ctx.AbortWithStatusJSON(writeErrorStatus(err, etag), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	ctx.JSON(http.StatusOK, &DeleteUserResponse{
 		Message: "Deleted successfully",
 	})
 }
+
+// @id GrantRole
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @param req body GrantRoleRequest true "req"
+// @param id path string true "id"
+// @success 200 {object} RoleResponse
+// @router /api/v1/users/{id}/roles [POST]
+func (h *Handler) GrantRole(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	var req *GrantRoleRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := util.ValidateStruct(req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	gRes, err := h.begotc.GrantRole(ctx, &userv1.GrantRoleRequest{
+		UserId: id,
+		Role:   req.Role,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &RoleResponse{
+		Roles: gRes.Roles,
+	})
+}
+
+// @id RevokeRole
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @param id path string true "id"
+// @param role path string true "role"
+// @success 200 {object} RoleResponse
+// @router /api/v1/users/{id}/roles/{role} [DELETE]
+func (h *Handler) RevokeRole(ctx *gin.Context) {
+	id, role := ctx.Param("id"), ctx.Param("role")
+	if id == "" || role == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	gRes, err := h.begotc.RevokeRole(ctx, &userv1.RevokeRoleRequest{
+		UserId: id,
+		Role:   role,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &RoleResponse{
+		Roles: gRes.Roles,
+	})
+}
+
+// @id UnlockUser
+// @accept  json
+// @produce  json
+// @security BearerAuth
+// @security APIKeyAuth
+// @tags User
+// @param id path string true "id"
+// @success 200 {object} UnlockUserResponse
+// @router /api/v1/users/{id}/unlock [POST]
+func (h *Handler) UnlockUser(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "path parameter is missing.",
+		})
+		return
+	}
+
+	if _, err := h.begotc.UnlockUser(ctx, &userv1.UnlockUserRequest{
+		UserId: id,
+	}); err != nil {
+		ctx.AbortWithStatusJSON(util.HTTPStatusFromError(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &UnlockUserResponse{
+		Message: "User unlocked successfully",
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -16,6 +17,11 @@ import (
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
 	pbmock "github.com/nuea/backend-golang-test/proto/mock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func setupTestRequest(t *testing.T, method, path string, payload interface{}) (*httptest.ResponseRecorder, *gin.Context) {
@@ -201,6 +207,80 @@ func TestGetUsers(t *testing.T) {
 		assert.Equal(t, data[0].Id, res.Data[0].ID)
 	})
 
+	t.Run("success - with pagination", func(t *testing.T) {
+		req := &GetUsersRequest{
+			PageSize:     1,
+			PageToken:    "token",
+			OrderBy:      "created_at desc",
+			IncludeTotal: true,
+		}
+		gReq := &userv1.GetUsersRequest{
+			PageSize:     req.PageSize,
+			PageToken:    req.PageToken,
+			OrderBy:      req.OrderBy,
+			IncludeTotal: req.IncludeTotal,
+		}
+		gRes := &userv1.GetUsersResponse{
+			Data:          []*userv1.User{{Id: "686b6ce8dbf72bfc4d0fef95", Name: "test", Email: "test@example.com"}},
+			NextPageToken: "next",
+			Total:         ptr.Int64(3),
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, req)
+		musc.EXPECT().GetUsers(ctx, gReq).Return(gRes, nil).Times(1)
+		h.GetUsers(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res GetUsersResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Len(t, res.Data, 1)
+		assert.Equal(t, "next", res.NextPageToken)
+		assert.Equal(t, int64(3), *res.Total)
+	})
+
+	t.Run("success - with search query", func(t *testing.T) {
+		after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		gReq := &userv1.GetUsersRequest{
+			NamePrefix:    ptr.String("Jo"),
+			EmailContains: ptr.String("example"),
+			CreatedAfter:  timestamppb.New(after),
+			CreatedBy:     ptr.String("admin"),
+			Q:             ptr.String("john doe"),
+		}
+
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet,
+			path+"?name_prefix=Jo&email_contains=example&created_after=2025-01-01T00:00:00Z&created_by=admin&q=john+doe", nil)
+		musc.EXPECT().GetUsers(ctx, gReq).Return(&userv1.GetUsersResponse{}, nil).Times(1)
+		h.GetUsers(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("bad request - invalid time", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, path+"?created_after=yesterday", nil)
+		h.GetUsers(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("bad request - invalid page token", func(t *testing.T) {
+		req := &GetUsersRequest{PageToken: "invalid"}
+		gReq := &userv1.GetUsersRequest{PageToken: req.PageToken}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, req)
+		msgerr := status.Error(codes.InvalidArgument, "Page token is invalid.")
+		musc.EXPECT().GetUsers(ctx, gReq).Return(nil, msgerr).Times(1)
+		h.GetUsers(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Page token is invalid.")
+	})
+
 	t.Run("bad request - invalid json", func(t *testing.T) {
 		req := "invalid json"
 		rec, ctx := setupTestRequest(t, http.MethodGet, path, req)
@@ -328,6 +408,39 @@ func TestUpdateUser(t *testing.T) {
 		assert.Equal(t, "Updated successfully", res.Message)
 	})
 
+	t.Run("success - with update mask", func(t *testing.T) {
+		req := &UpdateUserRequest{
+			Email: ptr.String("test@example.com"),
+		}
+		gReq := &userv1.UpdateUserRequest{
+			Id:         uid,
+			Email:      req.Email,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "email"}},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path+"/"+uid+"?update_mask=name,%20email", req)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gReq).Return(nil, nil).Times(1)
+		h.UpdateUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("bad request - invalid update mask", func(t *testing.T) {
+		st, _ := status.New(codes.InvalidArgument, "Update mask has invalid paths: roles.").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "roles", Reason: "immutable_path", Description: "roles cannot be updated."}},
+		})
+
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path+"/"+uid+"?update_mask=roles", &UpdateUserRequest{})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gomock.Any()).Return(nil, st.Err()).Times(1)
+		h.UpdateUser(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"roles"`)
+		assert.Contains(t, rec.Body.String(), "immutable_path")
+	})
+
 	t.Run("bad request - path parameter is missing", func(t *testing.T) {
 		rec, ctx := setupTestRequest(t, http.MethodPatch, path, nil)
 		h.UpdateUser(ctx)
@@ -367,6 +480,190 @@ func TestUpdateUser(t *testing.T) {
 	})
 }
 
+func TestReplaceUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users"
+	uid := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		req := &ReplaceUserRequest{
+			Name:  "test",
+			Email: "test@example.com",
+		}
+		gReq := &userv1.UpdateUserRequest{
+			Id:         uid,
+			Name:       ptr.String(req.Name),
+			Email:      ptr.String(req.Email),
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"*"}},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodPut, path, req)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gReq).Return(nil, nil).Times(1)
+		h.ReplaceUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res UpdateUserResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "Updated successfully", res.Message)
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPut, path, nil)
+		h.ReplaceUser(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "path parameter is missing.")
+	})
+
+	t.Run("bad request - missing field", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPut, path, &ReplaceUserRequest{Name: "test"})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		h.ReplaceUser(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "email is required.")
+	})
+
+	t.Run("not found - gRPC", func(t *testing.T) {
+		req := &ReplaceUserRequest{Name: "test", Email: "test@example.com"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPut, path, req)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gomock.Any()).Return(nil, status.Error(codes.NotFound, "User not found.")).Times(1)
+		h.ReplaceUser(ctx)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestIfMatch(t *testing.T) {
+	cases := map[string]string{
+		"":         "",
+		"*":        "",
+		`"5"`:      "5",
+		` "5" `:    "5",
+		"5":        invalidETag,
+		`W/"5"`:    invalidETag,
+		`""`:       invalidETag,
+		`"5", "6"`: invalidETag,
+	}
+	for header, want := range cases {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPatch, "/", nil)
+		ctx.Request.Header.Set("If-Match", header)
+
+		assert.Equal(t, want, ifMatch(ctx), "If-Match: %s", header)
+	}
+}
+
+func TestUserETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users"
+	uid := "686b6ce8dbf72bfc4d0fef95"
+	modified := status.Error(codes.Aborted, "User was modified by another request, get it again and retry.")
+
+	t.Run("get sets etag", func(t *testing.T) {
+		gRes := &userv1.GetUserResponse{
+			User: &userv1.User{Id: uid, Name: "test", Email: "test@example.com", Etag: "3"},
+		}
+
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().GetUser(ctx, &userv1.GetUserRequest{Id: uid}).Return(gRes, nil).Times(1)
+		h.GetUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("update with if-match", func(t *testing.T) {
+		req := &UpdateUserRequest{Name: ptr.String("renamed")}
+		gReq := &userv1.UpdateUserRequest{Id: uid, Name: req.Name, Etag: "3"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path, req)
+		ctx.Request.Header.Set("If-Match", `"3"`)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gReq).Return(&userv1.UpdateUserResponse{Etag: "4"}, nil).Times(1)
+		h.UpdateUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("update with any etag", func(t *testing.T) {
+		req := &UpdateUserRequest{Name: ptr.String("renamed")}
+		gReq := &userv1.UpdateUserRequest{Id: uid, Name: req.Name}
+
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path, req)
+		ctx.Request.Header.Set("If-Match", "*")
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gReq).Return(&userv1.UpdateUserResponse{Etag: "4"}, nil).Times(1)
+		h.UpdateUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("precondition failed - stale etag", func(t *testing.T) {
+		req := &ReplaceUserRequest{Name: "test", Email: "test@example.com"}
+
+		rec, ctx := setupTestRequest(t, http.MethodPut, path, req)
+		ctx.Request.Header.Set("If-Match", `"2"`)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gomock.Any()).Return(nil, modified).Times(1)
+		h.ReplaceUser(ctx)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("precondition failed - delete", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Request.Header.Set("If-Match", `W/"3"`)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().DeleteUser(ctx, &userv1.DeleteUserRequest{Id: uid, Etag: invalidETag}).Return(nil, modified).Times(1)
+		h.DeleteUser(ctx)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("precondition failed - unquoted etag", func(t *testing.T) {
+		req := &UpdateUserRequest{Name: ptr.String("renamed")}
+		gReq := &userv1.UpdateUserRequest{Id: uid, Name: req.Name, Etag: invalidETag}
+
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path, req)
+		ctx.Request.Header.Set("If-Match", "3")
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UpdateUser(ctx, gReq).Return(nil, modified).Times(1)
+		h.UpdateUser(ctx)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("conflict - without if-match", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().DeleteUser(ctx, &userv1.DeleteUserRequest{Id: uid}).Return(nil, modified).Times(1)
+		h.DeleteUser(ctx)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
 func TestDeleteUser(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ctrl := gomock.NewController(t)
@@ -419,3 +716,349 @@ func TestDeleteUser(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), msgerr.Error())
 	})
 }
+
+func TestGrantRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users/686b6ce8dbf72bfc4d0fef95/roles"
+	uid := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &GrantRoleRequest{Role: "support"})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().GrantRole(ctx, &userv1.GrantRoleRequest{UserId: uid, Role: "support"}).
+			Return(&userv1.GrantRoleResponse{Roles: []string{"user", "support"}}, nil).Times(1)
+		h.GrantRole(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res RoleResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user", "support"}, res.Roles)
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &GrantRoleRequest{Role: "support"})
+		h.GrantRole(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "path parameter is missing.")
+	})
+
+	t.Run("bad request - role is required", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &GrantRoleRequest{})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		h.GrantRole(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("bad request - invalid role", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &GrantRoleRequest{Role: "root"})
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().GrantRole(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.InvalidArgument, "Role is invalid.")).Times(1)
+		h.GrantRole(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Role is invalid.")
+	})
+}
+
+func TestRevokeRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users/686b6ce8dbf72bfc4d0fef95/roles/support"
+	uid := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid}, gin.Param{Key: "role", Value: "support"})
+		musc.EXPECT().RevokeRole(ctx, &userv1.RevokeRoleRequest{UserId: uid, Role: "support"}).
+			Return(&userv1.RevokeRoleResponse{Roles: []string{"user"}}, nil).Times(1)
+		h.RevokeRole(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res RoleResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user"}, res.Roles)
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		h.RevokeRole(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "path parameter is missing.")
+	})
+
+	t.Run("failed precondition", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid}, gin.Param{Key: "role", Value: "admin"})
+		musc.EXPECT().RevokeRole(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.FailedPrecondition, "Cannot revoke your own admin role.")).Times(1)
+		h.RevokeRole(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestUnlockUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users/686b6ce8dbf72bfc4d0fef95/unlock"
+	uid := "686b6ce8dbf72bfc4d0fef95"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UnlockUser(ctx, &userv1.UnlockUserRequest{UserId: uid}).
+			Return(&userv1.UnlockUserResponse{}, nil).Times(1)
+		h.UnlockUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "User unlocked successfully")
+	})
+
+	t.Run("bad request - path parameter is missing", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		h.UnlockUser(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "path parameter is missing.")
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, nil)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: uid})
+		musc.EXPECT().UnlockUser(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.PermissionDenied, "Permission denied.")).Times(1)
+		h.UnlockUser(ctx)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users/me"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		musc.EXPECT().GetCurrentUser(ctx, &userv1.GetCurrentUserRequest{}).Return(&userv1.GetCurrentUserResponse{
+			User: &userv1.User{Id: "686b6ce8dbf72bfc4d0fef95", Name: "test", Email: "test@example.com"},
+		}, nil).Times(1)
+		h.GetCurrentUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var res GetUserResponse
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		assert.NoError(t, err)
+		assert.Equal(t, "686b6ce8dbf72bfc4d0fef95", res.ID)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		musc.EXPECT().GetCurrentUser(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.Unauthenticated, "Unauthorized.")).Times(1)
+		h.GetCurrentUser(ctx)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestUserInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/userinfo"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		musc.EXPECT().GetCurrentUser(ctx, &userv1.GetCurrentUserRequest{}).Return(&userv1.GetCurrentUserResponse{
+			User: &userv1.User{
+				Id:              "686b6ce8dbf72bfc4d0fef95",
+				Name:            "test",
+				Email:           "test@example.com",
+				Roles:           []string{"user"},
+				EmailVerifiedAt: timestamppb.New(time.Unix(1735689600, 0)),
+				UpdatedAt:       timestamppb.New(time.Unix(1735689600, 0)),
+			},
+		}, nil).Times(1)
+		h.UserInfo(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"sub":"686b6ce8dbf72bfc4d0fef95","name":"test","email":"test@example.com","email_verified":true,"updated_at":1735689600,"roles":["user"]}`, rec.Body.String())
+	})
+
+	t.Run("client token", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodGet, path, nil)
+		musc.EXPECT().GetCurrentUser(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.PermissionDenied, "Permission denied.")).Times(1)
+		h.UserInfo(ctx)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestUpdateCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users/me"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path, &UpdateUserRequest{Name: ptr.String("new name")})
+		musc.EXPECT().UpdateCurrentUser(ctx, &userv1.UpdateCurrentUserRequest{Name: ptr.String("new name")}).
+			Return(&userv1.UpdateCurrentUserResponse{}, nil).Times(1)
+		h.UpdateCurrentUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Updated successfully")
+	})
+
+	t.Run("bad request - invalid body", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPatch, path, "invalid json")
+		h.UpdateCurrentUser(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestDeleteCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/users/me"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		musc.EXPECT().DeleteCurrentUser(ctx, &userv1.DeleteCurrentUserRequest{}).
+			Return(&userv1.DeleteCurrentUserResponse{}, nil).Times(1)
+		h.DeleteCurrentUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Deleted successfully")
+	})
+
+	t.Run("internal server error", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodDelete, path, nil)
+		msgerr := errors.New("internal server error")
+		musc.EXPECT().DeleteCurrentUser(ctx, gomock.Any()).Return(nil, msgerr).Times(1)
+		h.DeleteCurrentUser(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), msgerr.Error())
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/email/verify"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &VerifyEmailRequest{Token: "verify-token"})
+		musc.EXPECT().VerifyEmail(ctx, &userv1.VerifyEmailRequest{Token: "verify-token"}).
+			Return(&userv1.VerifyEmailResponse{}, nil).Times(1)
+		h.VerifyEmail(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Email verified successfully")
+	})
+
+	t.Run("bad request - token is required", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &VerifyEmailRequest{})
+		h.VerifyEmail(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token is required.")
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &VerifyEmailRequest{Token: "verify-token"})
+		musc.EXPECT().VerifyEmail(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.InvalidArgument, "Verification token is invalid or expired.")).Times(1)
+		h.VerifyEmail(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	musc := pbmock.NewMockUserServiceClient(ctrl)
+	h := &Handler{
+		begotc: musc,
+	}
+
+	path := "/api/v1/email/verify/resend"
+
+	t.Run("success", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ResendVerificationEmailRequest{Email: "test@example.com"})
+		musc.EXPECT().ResendVerificationEmail(ctx, &userv1.ResendVerificationEmailRequest{Email: "test@example.com"}).
+			Return(&userv1.ResendVerificationEmailResponse{}, nil).Times(1)
+		h.ResendVerificationEmail(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("too many requests", func(t *testing.T) {
+		rec, ctx := setupTestRequest(t, http.MethodPost, path, &ResendVerificationEmailRequest{Email: "test@example.com"})
+		musc.EXPECT().ResendVerificationEmail(ctx, gomock.Any()).
+			Return(nil, status.Error(codes.ResourceExhausted, "Too many verification emails, try again later.")).Times(1)
+		h.ResendVerificationEmail(ctx)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/nuea/backend-golang-test/cmd/http/internal/handler"
 	"github.com/nuea/backend-golang-test/internal/middleware"
+	"github.com/nuea/backend-golang-test/internal/rbac"
 
 	_ "github.com/nuea/backend-golang-test/cmd/http/internal/docs"
 	swaggerFiles "github.com/swaggo/files"
@@ -12,16 +13,55 @@ import (
 
 func registerRouter(gin *gin.Engine, h *handler.Handlers, m *middleware.Middleware) {
 	gin.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	gin.GET("/.well-known/jwks.json", h.AuthHandler.JWKS)
+	gin.POST("/oauth/token", h.AuthHandler.Token)
+	gin.POST("/oauth/introspect", m.Auth.Middleware(), m.Permission.Require(rbac.PermissionTokensIntrospect), h.AuthHandler.Introspect)
+	gin.GET("/userinfo", m.Auth.Middleware(), h.UserHandler.UserInfo)
 
 	router := *gin.Group("/api/v1")
 	{
 		router.POST("/login", h.AuthHandler.Login)
+		router.POST("/login/mfa", h.AuthHandler.VerifyMFA)
+		router.GET("/oidc/login", h.AuthHandler.BeginOIDCLogin)
+		router.GET("/oidc/callback", h.AuthHandler.CompleteOIDCLogin)
+		router.POST("/token/refresh", h.AuthHandler.RefreshToken)
+		router.POST("/password/forgot", h.AuthHandler.ForgotPassword)
+		router.POST("/password/reset", h.AuthHandler.ResetPassword)
 		router.POST("/users", h.UserHandler.CreateUser)
+		router.POST("/email/verify", h.UserHandler.VerifyEmail)
+		router.POST("/email/verify/resend", h.UserHandler.ResendVerificationEmail)
 
 		router.Use(m.Auth.Middleware())
-		router.GET("/users", h.UserHandler.GetUsers)
-		router.GET("/users/:id", h.UserHandler.GetUser)
-		router.PATCH("/users/:id", h.UserHandler.UpdateUser)
-		router.DELETE("/users/:id", h.UserHandler.DeleteUser)
+		router.POST("/logout", h.AuthHandler.Logout)
+		router.POST("/password/change", h.AuthHandler.ChangePassword)
+		router.POST("/mfa/enroll", h.AuthHandler.EnrollMFA)
+		router.POST("/mfa/confirm", h.AuthHandler.ConfirmMFA)
+		router.POST("/mfa/disable", h.AuthHandler.DisableMFA)
+		router.GET("/sessions", h.AuthHandler.ListSessions)
+		router.DELETE("/sessions", h.AuthHandler.RevokeAllSessions)
+		router.DELETE("/sessions/:id", h.AuthHandler.RevokeSession)
+		router.GET("/users", m.Permission.Require(rbac.PermissionUsersRead), h.UserHandler.GetUsers)
+		router.GET("/users/me", m.Permission.RequireScope(rbac.PermissionUsersRead), h.UserHandler.GetCurrentUser)
+		router.PATCH("/users/me", m.Permission.RequireScope(rbac.PermissionUsersWrite), h.UserHandler.UpdateCurrentUser)
+		router.DELETE("/users/me", m.Permission.RequireScope(rbac.PermissionUsersDelete), h.UserHandler.DeleteCurrentUser)
+		router.GET("/users/:id", m.Permission.RequireOrOwner(rbac.PermissionUsersRead, "id"), h.UserHandler.GetUser)
+		router.PATCH("/users/:id", m.Permission.RequireOrOwner(rbac.PermissionUsersWrite, "id"), h.UserHandler.UpdateUser)
+		router.PUT("/users/:id", m.Permission.RequireOrOwner(rbac.PermissionUsersWrite, "id"), h.UserHandler.ReplaceUser)
+		router.DELETE("/users/:id", m.Permission.RequireOrOwner(rbac.PermissionUsersDelete, "id"), h.UserHandler.DeleteUser)
+		router.POST("/users/:id/roles", m.Permission.Require(rbac.PermissionRolesManage), h.UserHandler.GrantRole)
+		router.DELETE("/users/:id/roles/:role", m.Permission.Require(rbac.PermissionRolesManage), h.UserHandler.RevokeRole)
+		router.POST("/users/:id/unlock", m.Permission.Require(rbac.PermissionUsersUnlock), h.UserHandler.UnlockUser)
+		router.POST("/users/:id/impersonate", m.Permission.Require(rbac.PermissionUsersImpersonate), h.AuthHandler.ImpersonateUser)
+		router.GET("/users/:id/sessions", m.Permission.RequireOrOwner(rbac.PermissionSessionsManage, "id"), h.AuthHandler.ListUserSessions)
+		router.DELETE("/users/:id/sessions", m.Permission.RequireOrOwner(rbac.PermissionSessionsManage, "id"), h.AuthHandler.RevokeUserSessions)
+		router.POST("/api-keys", h.APIKeyHandler.CreateAPIKey)
+		router.GET("/api-keys", h.APIKeyHandler.ListAPIKeys)
+		router.DELETE("/api-keys/:id", h.APIKeyHandler.RevokeAPIKey)
+		router.POST("/service-accounts", m.Permission.Require(rbac.PermissionServiceAccountsManage), h.APIKeyHandler.CreateServiceAccount)
+		router.GET("/service-accounts", m.Permission.Require(rbac.PermissionServiceAccountsManage), h.APIKeyHandler.ListServiceAccounts)
+		router.DELETE("/service-accounts/:id", m.Permission.Require(rbac.PermissionServiceAccountsManage), h.APIKeyHandler.DeleteServiceAccount)
+		router.POST("/oauth-clients", m.Permission.Require(rbac.PermissionOAuthClientsManage), h.APIKeyHandler.CreateOAuthClient)
+		router.GET("/oauth-clients", m.Permission.Require(rbac.PermissionOAuthClientsManage), h.APIKeyHandler.ListOAuthClients)
+		router.DELETE("/oauth-clients/:id", m.Permission.Require(rbac.PermissionOAuthClientsManage), h.APIKeyHandler.DeleteOAuthClient)
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/nuea/backend-golang-test/cmd/http/internal/handler"
 	"github.com/nuea/backend-golang-test/internal/config"
 	"github.com/nuea/backend-golang-test/internal/middleware"
+	"github.com/nuea/backend-golang-test/internal/util"
 	"github.com/oklog/run"
 )
 
@@ -58,6 +59,7 @@ func WithRequestLoggerServer() gin.HandlerFunc {
 	}
 }
 
+// WithResponseLoggerServer logs every response, without its secrets.
 func WithResponseLoggerServer() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		wrapWriter := &ResponseBodyWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -68,7 +70,7 @@ func WithResponseLoggerServer() gin.HandlerFunc {
 			if err := json.Unmarshal(wrapWriter.body.Bytes(), &body); err != nil {
 				log.Println("HTTP response -", "method:", c.Request.Method, ", path:", c.Request.URL.Path, ", http_status:", c.Writer.Status())
 			} else {
-				log.Println("HTTP response - ", "method", c.Request.Method, ", path", c.Request.URL.Path, ", http_status: ", c.Writer.Status(), ", response_body", body)
+				log.Println("HTTP response - ", "method", c.Request.Method, ", path", c.Request.URL.Path, ", http_status: ", c.Writer.Status(), ", response_body", util.RedactJSON(body))
 			}
 		}
 	}
@@ -78,17 +80,32 @@ func (s *HTTPServer) load(h *handler.Handlers, m *middleware.Middleware) {
 	registerRouter(s.gin, h, m)
 }
 
-func ProvideHTTPServer(cfg *config.AppConfig, h *handler.Handlers, m *middleware.Middleware) *HTTPServer {
+// newEngine returns the gin engine with the middlewares of every route. The
+// client IP throttles logins, it is only read from X-Forwarded-For when the
+// request comes from a trusted proxy.
+func newEngine(cfg *config.AppConfig) (*gin.Engine, error) {
+	e := gin.New()
+	if err := e.SetTrustedProxies(cfg.HTTPConfig.TrustedProxies); err != nil {
+		return nil, err
+	}
+	e.Use(WithRequestLoggerServer())
+	e.Use(WithResponseLoggerServer())
+	e.Use(gin.Recovery())
+	return e, nil
+}
+
+func ProvideHTTPServer(cfg *config.AppConfig, h *handler.Handlers, m *middleware.Middleware) (*HTTPServer, error) {
+	e, err := newEngine(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	sv := &HTTPServer{
 		cfg: cfg,
-		gin: gin.New(),
+		gin: e,
 		srv: &http.Server{},
 	}
-	sv.gin.Use(WithRequestLoggerServer())
-	sv.gin.Use(WithResponseLoggerServer())
-	sv.gin.Use(gin.Recovery())
-
 	sv.load(h, m)
 
-	return sv
+	return sv, nil
 }
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clientIP := func(t *testing.T, proxies []string, remoteAddr string) string {
+		e, err := newEngine(&config.AppConfig{HTTPConfig: config.HTTPConfig{TrustedProxies: proxies}})
+		require.NoError(t, err)
+		e.GET("/", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, identity.ClientIPFromContext(ctx))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	t.Run("spoofed header without trusted proxies", func(t *testing.T) {
+		assert.Equal(t, "192.0.2.7", clientIP(t, nil, "192.0.2.7:50000"))
+	})
+
+	t.Run("spoofed header from an untrusted peer", func(t *testing.T) {
+		assert.Equal(t, "192.0.2.7", clientIP(t, []string{"10.0.0.0/8"}, "192.0.2.7:50000"))
+	})
+
+	t.Run("forwarded by a trusted proxy", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.9", clientIP(t, []string{"10.0.0.0/8"}, "10.0.0.2:50000"))
+	})
+
+	t.Run("invalid proxy", func(t *testing.T) {
+		_, err := newEngine(&config.AppConfig{HTTPConfig: config.HTTPConfig{TrustedProxies: []string{"nope"}}})
+		assert.Error(t, err)
+	})
+}
@@ -18,6 +18,10 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.basic ClientBasicAuth
 // @securityDefinitions.apikey DeviceID
 // @in header
 // @name X-Device-Id
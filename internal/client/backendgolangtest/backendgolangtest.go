@@ -7,14 +7,17 @@ import (
 	"time"
 
 	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 type BackendGolangTestGRPCService struct {
 	userv1.UserServiceClient
 	userv1.AuthServiceClient
+	userv1.APIKeyServiceClient
 }
 
 type APIClient struct {
@@ -41,15 +44,48 @@ func NewDefaultGRPCClient(target string, du time.Duration, opts ...grpc.DialOpti
 	return grpc.NewClient(target, append(baseOpts, opts...)...)
 }
 
+// WithRequestLoggerUnaryClient logs every request, without its secrets.
 func WithRequestLoggerUnaryClient() grpc.DialOption {
 	return grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		log.Println("GRPC request client - ", "request:", req, "method:", method)
+		log.Println("GRPC request client - ", "request:", redact(req), "method:", method)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+}
+
+// WithAccessTokenUnaryClient forwards the access token or the API key of the
+// caller, as stored by the auth middleware, to the gRPC server.
+func WithAccessTokenUnaryClient() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if ac := identity.AccessTokenFromContext(ctx); ac != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+ac)
+		} else if key := identity.APIKeyFromContext(ctx); key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", key)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+}
+
+// WithClientInfoUnaryClient forwards the IP, the device id and the user agent
+// of the HTTP client, so the gRPC server can throttle by the real client
+// rather than by the gateway and record the device of a session.
+func WithClientInfoUnaryClient() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if ip := identity.ClientIPFromContext(ctx); ip != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", ip)
+		}
+		deviceID, userAgent := identity.DeviceFromContext(ctx)
+		if deviceID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-device-id", deviceID)
+		}
+		if userAgent != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-forwarded-user-agent", userAgent)
+		}
 		return invoker(ctx, method, req, reply, cc, opts...)
 	})
 }
 
 func ProvideBackendGolangTestServiceGRPC(cfg *config.AppConfig) *APIClient {
-	conn, err := NewDefaultGRPCClient(cfg.BackendGoTest.GRPCTarget, cfg.BackendGoTest.RequestTimeout, WithRequestLoggerUnaryClient())
+	conn, err := NewDefaultGRPCClient(cfg.BackendGoTest.GRPCTarget, cfg.BackendGoTest.RequestTimeout, WithRequestLoggerUnaryClient(), WithAccessTokenUnaryClient(), WithClientInfoUnaryClient())
 	if err != nil {
 		panic(err)
 	}
@@ -66,3 +102,7 @@ func ProvideUserServiceClient(client *APIClient) userv1.UserServiceClient {
 func ProvideAuthServiceClient(client *APIClient) userv1.AuthServiceClient {
 	return userv1.NewAuthServiceClient(client.conn)
 }
+
+func ProvideAPIKeyServiceClient(client *APIClient) userv1.APIKeyServiceClient {
+	return userv1.NewAPIKeyServiceClient(client.conn)
+}
@@ -0,0 +1,37 @@
+package backendgolangtest
+
+import (
+	"github.com/nuea/backend-golang-test/internal/util"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// redact returns a copy of the request with the fields of
+// util.IsSensitiveField replaced.
+func redact(req any) any {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return req
+	}
+	m = proto.Clone(m)
+	redactMessage(m.ProtoReflect())
+	return m
+}
+
+func redactMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch sensitive := util.IsSensitiveField(string(fd.Name())); {
+		case sensitive && fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated:
+			m.Set(fd, protoreflect.ValueOfString(util.Redacted))
+		case sensitive:
+			m.Clear(fd)
+		case fd.IsList() && fd.Message() != nil:
+			for i := 0; i < v.List().Len(); i++ {
+				redactMessage(v.List().Get(i).Message())
+			}
+		case fd.Message() != nil && !fd.IsMap():
+			redactMessage(v.Message())
+		}
+		return true
+	})
+}
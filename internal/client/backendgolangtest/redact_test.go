@@ -0,0 +1,72 @@
+package backendgolangtest
+
+import (
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/util"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func assertProtoEqual(t *testing.T, want proto.Message, got any) {
+	t.Helper()
+	assert.True(t, proto.Equal(want, got.(proto.Message)), "want %v, got %v", want, got)
+}
+
+func TestRedact(t *testing.T) {
+	t.Run("api key", func(t *testing.T) {
+		req := &userv1.VerifyAPIKeyRequest{Key: "bgt_secret"}
+
+		got := redact(req).(*userv1.VerifyAPIKeyRequest)
+
+		assert.Equal(t, util.Redacted, got.Key)
+		assert.Equal(t, "bgt_secret", req.Key, "the request itself is not changed")
+	})
+
+	t.Run("client secret", func(t *testing.T) {
+		got := redact(&userv1.VerifyClientCredentialsRequest{ClientId: "client-1", ClientSecret: "s3cret"})
+
+		assertProtoEqual(t, &userv1.VerifyClientCredentialsRequest{ClientId: "client-1", ClientSecret: util.Redacted}, got)
+	})
+
+	t.Run("oidc code", func(t *testing.T) {
+		got := redact(&userv1.CompleteOIDCLoginRequest{State: "state", Code: "code"})
+
+		assertProtoEqual(t, &userv1.CompleteOIDCLoginRequest{State: util.Redacted, Code: util.Redacted}, got)
+	})
+
+	t.Run("passwords", func(t *testing.T) {
+		got := redact(&userv1.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"})
+
+		assertProtoEqual(t, &userv1.ChangePasswordRequest{CurrentPassword: util.Redacted, NewPassword: util.Redacted}, got)
+	})
+
+	t.Run("reset token", func(t *testing.T) {
+		got := redact(&userv1.ResetPasswordRequest{Token: "token", NewPassword: "new"})
+
+		assertProtoEqual(t, &userv1.ResetPasswordRequest{Token: util.Redacted, NewPassword: util.Redacted}, got)
+	})
+
+	t.Run("refresh token", func(t *testing.T) {
+		got := redact(&userv1.RefreshTokenRequest{RefreshToken: "token"})
+
+		assertProtoEqual(t, &userv1.RefreshTokenRequest{RefreshToken: util.Redacted}, got)
+	})
+
+	t.Run("mfa code", func(t *testing.T) {
+		got := redact(&userv1.VerifyMFARequest{MfaToken: "token", Code: "123456"})
+
+		assertProtoEqual(t, &userv1.VerifyMFARequest{MfaToken: util.Redacted, Code: util.Redacted}, got)
+	})
+
+	t.Run("other fields are kept", func(t *testing.T) {
+		req := &userv1.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:read"}}
+
+		assertProtoEqual(t, req, redact(req))
+	})
+
+	t.Run("not a message", func(t *testing.T) {
+		assert.Equal(t, "request", redact("request"))
+	})
+}
@@ -3,7 +3,9 @@ package client
 import (
 	"github.com/google/wire"
 	begot "github.com/nuea/backend-golang-test/internal/client/backendgolangtest"
+	"github.com/nuea/backend-golang-test/internal/client/mailer"
 	"github.com/nuea/backend-golang-test/internal/client/mongodb"
+	"github.com/nuea/backend-golang-test/internal/client/oidc"
 )
 
 type GRPCClients struct {
@@ -12,13 +14,18 @@ type GRPCClients struct {
 
 type Clients struct {
 	MongoDB mongodb.MongoDB
+	Mailer  mailer.Mailer
+	OIDC    oidc.Provider
 }
 
 var ClientSet = wire.NewSet(
 	mongodb.ProvideMongoDBClient,
+	mailer.ProvideMailer,
+	oidc.ProvideOIDCProvider,
 	begot.ProvideBackendGolangTestServiceGRPC,
 	begot.ProvideUserServiceClient,
 	begot.ProvideAuthServiceClient,
+	begot.ProvideAPIKeyServiceClient,
 
 	wire.Struct(new(begot.BackendGolangTestGRPCService), "*"),
 	wire.Struct(new(GRPCClients), "*"),
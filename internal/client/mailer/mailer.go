@@ -0,0 +1,74 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails. Only local backends exist for now,
+// an SMTP or provider backend can be added behind the same interface.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+func ProvideMailer(cfg *config.AppConfig) (Mailer, error) {
+	switch cfg.Mailer.Driver {
+	case "stdout":
+		return &writerMailer{from: cfg.Mailer.From, w: os.Stdout}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.Mailer.Dir, 0o700); err != nil {
+			return nil, err
+		}
+		return &fileMailer{from: cfg.Mailer.From, dir: cfg.Mailer.Dir}, nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown driver %q, expected stdout or file", cfg.Mailer.Driver)
+	}
+}
+
+// writerMailer prints every message, used for local development.
+type writerMailer struct {
+	mu   sync.Mutex
+	from string
+	w    io.Writer
+}
+
+func (m *writerMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return write(m.w, m.from, msg)
+}
+
+// fileMailer writes every message to its own .eml file in dir.
+type fileMailer struct {
+	from string
+	dir  string
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg *Message) error {
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+	f, err := os.OpenFile(filepath.Join(m.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(f, m.from, msg)
+}
+
+func write(w io.Writer, from string, msg *Message) error {
+	_, err := fmt.Fprintf(w, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		from, msg.To, msg.Subject, time.Now().UTC().Format(time.RFC1123Z), msg.Body)
+	return err
+}
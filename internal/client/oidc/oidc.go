@@ -0,0 +1,366 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/config"
+)
+
+var (
+	ErrNotConfigured  = errors.New("oidc: provider is not configured")
+	ErrInvalidGrant   = errors.New("oidc: authorization code is invalid or expired")
+	ErrInvalidIDToken = errors.New("oidc: id token is invalid")
+)
+
+const (
+	wellKnownPath = "/.well-known/openid-configuration"
+
+	// clockSkew is tolerated between the clocks of the issuer and this server.
+	clockSkew = time.Minute
+
+	maxResponseSize = 1 << 20
+)
+
+// HMAC is left out on purpose, the client secret must never verify a token.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Claims are the claims of a verified ID token.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider runs the authorization code flow with PKCE against the OpenID
+// Connect issuer described by the discovery document. The document and the
+// signing keys are fetched on first use and cached, so the server starts
+// while the issuer is unreachable.
+type Provider interface {
+	// AuthCodeURL returns the URL of the authorization endpoint to redirect
+	// the user to. The caller keeps codeVerifier and nonce for Exchange.
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	// Exchange redeems code at the token endpoint and returns the claims of
+	// the verified ID token, whose nonce must match.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error)
+}
+
+type provider struct {
+	cfg    *config.OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func ProvideOIDCProvider(cfg *config.AppConfig) Provider {
+	return &provider{
+		cfg:    &cfg.OIDC,
+		client: &http.Client{Timeout: cfg.OIDC.RequestTimeout},
+	}
+}
+
+// CodeChallenge returns the S256 PKCE challenge of verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.scopes(), " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func (p *provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.cfg.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// RFC 6749 section 2.3.1, the credentials are form encoded first.
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(&body)
+
+	if res.StatusCode != http.StatusOK {
+		if body.Error == "invalid_grant" {
+			return nil, ErrInvalidGrant
+		}
+		return nil, fmt.Errorf("oidc: token endpoint returned %s: %s %s", res.Status, body.Error, body.ErrorDescription)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("oidc: token response: %w", decodeErr)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing from the token response", ErrInvalidIDToken)
+	}
+
+	return p.verify(ctx, d, body.IDToken, nonce)
+}
+
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   boolean  `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+// Valid is not used, the claims are checked by validate.
+func (c *idTokenClaims) Valid() error {
+	return nil
+}
+
+// validate follows OpenID Connect Core 1.0 section 3.1.3.7.
+func (c *idTokenClaims) validate(issuer, clientID, nonce string, now time.Time) error {
+	switch {
+	case c.Issuer != issuer:
+		return fmt.Errorf("%w: issuer %q is not %q", ErrInvalidIDToken, c.Issuer, issuer)
+	case !slices.Contains(c.Audience, clientID):
+		return fmt.Errorf("%w: audience does not contain the client id", ErrInvalidIDToken)
+	case len(c.Audience) > 1 && c.AuthorizedParty != clientID:
+		return fmt.Errorf("%w: authorized party is not the client id", ErrInvalidIDToken)
+	case c.Subject == "":
+		return fmt.Errorf("%w: subject is empty", ErrInvalidIDToken)
+	case c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)):
+		return fmt.Errorf("%w: token is expired", ErrInvalidIDToken)
+	case c.IssuedAt != 0 && time.Unix(c.IssuedAt, 0).After(now.Add(clockSkew)):
+		return fmt.Errorf("%w: token is issued in the future", ErrInvalidIDToken)
+	case subtle.ConstantTimeCompare([]byte(c.Nonce), []byte(nonce)) != 1:
+		return fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+	return nil
+}
+
+func (p *provider) verify(ctx context.Context, d *discovery, raw, nonce string) (*Claims, error) {
+	var c idTokenClaims
+	parser := &jwt.Parser{ValidMethods: signingMethods, SkipClaimsValidation: true}
+	if _, err := parser.ParseWithClaims(raw, &c, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.verificationKey(ctx, d, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if err := c.validate(d.Issuer, p.cfg.ClientID, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &Claims{
+		Issuer:        c.Issuer,
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: bool(c.EmailVerified),
+		Name:          c.Name,
+	}, nil
+}
+
+// verificationKey returns the key kid of the issuer. The keys are fetched
+// again when kid is unknown, as the issuer may have rotated them.
+func (p *provider) verificationKey(ctx context.Context, d *discovery, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	var set jsonWebKeySet
+	if err := p.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	p.keys = set.publicKeys()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// lookupKey also accepts a token without kid when the issuer has one key.
+func (p *provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	if p.cfg.DiscoveryURL == "" {
+		return nil, ErrNotConfigured
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d discovery
+	if err := p.getJSON(ctx, p.cfg.DiscoveryURL, &d); err != nil {
+		return nil, err
+	}
+	if d.Issuer == "" || d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is incomplete")
+	}
+
+	// OpenID Connect Discovery 1.0 section 4.3, the document must belong to
+	// the issuer it was fetched from.
+	if want, ok := strings.CutSuffix(p.cfg.DiscoveryURL, wellKnownPath); ok && strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(want, "/") {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", d.Issuer, want)
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s returned %s", url, res.Status)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(v)
+}
+
+func (p *provider) scopes() []string {
+	scopes := make([]string, 0, len(p.cfg.Scopes)+1)
+	for _, s := range p.cfg.Scopes {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return scopes
+}
+
+// audience is a single string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// boolean also accepts "true" and "false", some issuers send email_verified
+// as a string.
+type boolean bool
+
+func (b *boolean) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = s == "true"
+		return nil
+	}
+
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = boolean(v)
+	return nil
+}
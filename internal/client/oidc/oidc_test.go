@@ -0,0 +1,176 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/client/oidc"
+	"github.com/nuea/backend-golang-test/internal/client/oidc/oidctest"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:8080/api/v1/oidc/callback"
+
+func newProvider(idp *oidctest.Server, secret string) oidc.Provider {
+	return oidc.ProvideOIDCProvider(&config.AppConfig{
+		OIDC: config.OIDCConfig{
+			DiscoveryURL:   idp.DiscoveryURL(),
+			ClientID:       idp.ClientID,
+			ClientSecret:   secret,
+			RedirectURL:    redirectURL,
+			Scopes:         []string{"email", "profile"},
+			RequestTimeout: 5 * time.Second,
+		},
+	})
+}
+
+// login runs the authorization code flow and returns the claims.
+func login(t *testing.T, idp *oidctest.Server, p oidc.Provider, nonce string) (*oidc.Claims, error) {
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state", nonce, "verifier-verifier-verifier-verifier-verifier")
+	assert.NoError(t, err)
+
+	code, state, err := idp.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "state", state)
+
+	return p.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier", nonce)
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+
+	t.Run("success", func(t *testing.T) {
+		authURL, err := newProvider(idp, "secret").AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+
+		assert.NoError(t, err)
+		u, err := url.Parse(authURL)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(authURL, idp.URL+"/authorize?"))
+		q := u.Query()
+		assert.Equal(t, "code", q.Get("response_type"))
+		assert.Equal(t, "client", q.Get("client_id"))
+		assert.Equal(t, redirectURL, q.Get("redirect_uri"))
+		assert.Equal(t, "openid email profile", q.Get("scope"))
+		assert.Equal(t, "state", q.Get("state"))
+		assert.Equal(t, "nonce", q.Get("nonce"))
+		assert.Equal(t, oidc.CodeChallenge("verifier"), q.Get("code_challenge"))
+		assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		p := oidc.ProvideOIDCProvider(&config.AppConfig{})
+
+		authURL, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+
+		assert.Empty(t, authURL)
+		assert.ErrorIs(t, err, oidc.ErrNotConfigured)
+	})
+
+	t.Run("discovery unreachable", func(t *testing.T) {
+		p := oidc.ProvideOIDCProvider(&config.AppConfig{
+			OIDC: config.OIDCConfig{DiscoveryURL: idp.URL + "/missing/.well-known/openid-configuration"},
+		})
+
+		_, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+
+		assert.ErrorContains(t, err, "404")
+	})
+}
+
+func TestExchange(t *testing.T) {
+	idp := oidctest.NewServer("client", "secret")
+	defer idp.Close()
+
+	t.Run("success", func(t *testing.T) {
+		claims, err := login(t, idp, newProvider(idp, "secret"), "nonce")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &oidc.Claims{
+			Issuer:        idp.Issuer(),
+			Subject:       "oidctest-user",
+			Email:         "oidctest@example.com",
+			EmailVerified: true,
+			Name:          "OIDC Test",
+		}, claims)
+	})
+
+	t.Run("code used twice", func(t *testing.T) {
+		ctx := context.Background()
+		p := newProvider(idp, "secret")
+		authURL, _ := p.AuthCodeURL(ctx, "state", "nonce", "verifier-verifier-verifier-verifier-verifier")
+		code, _, _ := idp.Authorize(authURL)
+
+		_, err := p.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier", "nonce")
+		assert.NoError(t, err)
+
+		claims, err := p.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier", "nonce")
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, oidc.ErrInvalidGrant)
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		ctx := context.Background()
+		p := newProvider(idp, "secret")
+		authURL, _ := p.AuthCodeURL(ctx, "state", "nonce", "verifier-verifier-verifier-verifier-verifier")
+		code, _, _ := idp.Authorize(authURL)
+
+		claims, err := p.Exchange(ctx, code, "other-verifier", "nonce")
+
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, oidc.ErrInvalidGrant)
+	})
+
+	t.Run("wrong client secret", func(t *testing.T) {
+		claims, err := login(t, idp, newProvider(idp, "other"), "nonce")
+
+		assert.Nil(t, claims)
+		assert.ErrorContains(t, err, "invalid_client")
+		assert.NotErrorIs(t, err, oidc.ErrInvalidGrant)
+	})
+
+	invalid := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+	}{
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "https://other.example.com" }},
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = []string{"other"} }},
+		{"other authorized party", func(c jwt.MapClaims) { c["aud"] = []string{"client", "other"}; c["azp"] = "other" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }},
+		{"other nonce", func(c jwt.MapClaims) { c["nonce"] = "other" }},
+		{"without subject", func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.ModifyClaims = tt.modify
+			defer func() { idp.ModifyClaims = nil }()
+
+			claims, err := login(t, idp, newProvider(idp, "secret"), "nonce")
+
+			assert.Nil(t, claims)
+			assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+		})
+	}
+
+	t.Run("audience list and string email_verified", func(t *testing.T) {
+		idp.ModifyClaims = func(c jwt.MapClaims) {
+			c["aud"] = []string{"client", "other"}
+			c["azp"] = "client"
+			c["email_verified"] = "false"
+		}
+		defer func() { idp.ModifyClaims = nil }()
+
+		claims, err := login(t, idp, newProvider(idp, "secret"), "nonce")
+
+		assert.NoError(t, err)
+		assert.False(t, claims.EmailVerified)
+	})
+}
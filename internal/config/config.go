@@ -14,6 +14,9 @@ import (
 type HTTPConfig struct {
 	HTTPPort    string `envconfig:"APP_HTTP_PORT" default:"8080"`
 	ServiceName string `envconfig:"SERVICE_NAME" default:"backend-golang-test"`
+	// TrustedProxies are the addresses, or CIDRs, of the load balancers whose
+	// X-Forwarded-For header is believed. None by default.
+	TrustedProxies []string `envconfig:"APP_HTTP_TRUSTED_PROXIES"`
 }
 
 type GRPCConfig struct {
@@ -21,11 +24,89 @@ type GRPCConfig struct {
 	GRPCReflectionEnabled   bool   `envconfig:"APP_GRPC_REFLECTION_ENABLED" default:"false"`
 	GRPCHealthcheckDisabled bool   `envconfig:"APP_GRPC_HEALTHCHECK_DISABLED" default:"false"`
 	ServiceName             string `envconfig:"SERVICE_NAME" default:"backend-golang-test"`
+	MetricsPort             string `envconfig:"APP_GRPC_METRICS_PORT"`
 }
 
 type AuthConfig struct {
-	SecretKey            string        `envconfig:"AUTH_SECRET_KEY"`
-	AccessTokenExpireTTL time.Duration `envconfig:"AUTH_ACCESS_TOKEN_EXPIRE_TTL" default:"5m"`
+	SecretKey             string        `envconfig:"AUTH_SECRET_KEY"`
+	KeyringFile           string        `envconfig:"AUTH_KEYRING_FILE"`
+	KeyringReloadInterval time.Duration `envconfig:"AUTH_KEYRING_RELOAD_INTERVAL" default:"1m"`
+	AccessTokenExpireTTL  time.Duration `envconfig:"AUTH_ACCESS_TOKEN_EXPIRE_TTL" default:"5m"`
+	RefreshTokenExpireTTL time.Duration `envconfig:"AUTH_REFRESH_TOKEN_EXPIRE_TTL" default:"720h"`
+	TokenIssuer           string        `envconfig:"AUTH_TOKEN_ISSUER" default:"backend-golang-test"`
+	TokenAudience         string        `envconfig:"AUTH_TOKEN_AUDIENCE" default:"backend-golang-test"`
+	TokenClockSkew        time.Duration `envconfig:"AUTH_TOKEN_CLOCK_SKEW" default:"30s"`
+	AcceptLegacyTokens    bool          `envconfig:"AUTH_ACCEPT_LEGACY_TOKENS" default:"true"`
+	ImpersonationTTL      time.Duration `envconfig:"AUTH_IMPERSONATION_TTL" default:"15m"`
+	PasswordResetTTL      time.Duration `envconfig:"AUTH_PASSWORD_RESET_TTL" default:"30m"`
+	PasswordResetURL      string        `envconfig:"AUTH_PASSWORD_RESET_URL" default:"http://localhost:8080/reset-password"`
+	// LegacyTokensUntil ends the migration window of AcceptLegacyTokens.
+	// Unset, it ends one AccessTokenExpireTTL after the server started.
+	LegacyTokensUntil time.Time `envconfig:"AUTH_LEGACY_TOKENS_UNTIL"`
+
+	CookieName     string `envconfig:"AUTH_COOKIE_NAME" default:"_uac"`
+	CookieDomain   string `envconfig:"AUTH_COOKIE_DOMAIN"`
+	CookieSecure   bool   `envconfig:"AUTH_COOKIE_SECURE" default:"true"`
+	CookieSameSite string `envconfig:"AUTH_COOKIE_SAMESITE" default:"lax"`
+	CSRFCookieName string `envconfig:"AUTH_CSRF_COOKIE_NAME" default:"_csrf"`
+	CSRFHeaderName string `envconfig:"AUTH_CSRF_HEADER_NAME" default:"X-CSRF-Token"`
+
+	RequireVerifiedEmail          bool          `envconfig:"AUTH_REQUIRE_VERIFIED_EMAIL" default:"false"`
+	EmailVerificationTTL          time.Duration `envconfig:"AUTH_EMAIL_VERIFICATION_TTL" default:"24h"`
+	EmailVerificationURL          string        `envconfig:"AUTH_EMAIL_VERIFICATION_URL" default:"http://localhost:8080/verify-email"`
+	EmailVerificationResendLimit  int64         `envconfig:"AUTH_EMAIL_VERIFICATION_RESEND_LIMIT" default:"3"`
+	EmailVerificationResendWindow time.Duration `envconfig:"AUTH_EMAIL_VERIFICATION_RESEND_WINDOW" default:"1h"`
+
+	LoginLockoutThreshold   int64         `envconfig:"AUTH_LOGIN_LOCKOUT_THRESHOLD" default:"5"`
+	LoginLockoutIPThreshold int64         `envconfig:"AUTH_LOGIN_LOCKOUT_IP_THRESHOLD" default:"20"`
+	LoginLockoutDuration    time.Duration `envconfig:"AUTH_LOGIN_LOCKOUT_DURATION" default:"1m"`
+	LoginLockoutMaxDuration time.Duration `envconfig:"AUTH_LOGIN_LOCKOUT_MAX_DURATION" default:"1h"`
+	LoginAttemptWindow      time.Duration `envconfig:"AUTH_LOGIN_ATTEMPT_WINDOW" default:"15m"`
+	// TrustedProxies are the addresses, or CIDRs, of the HTTP servers whose
+	// x-forwarded-for metadata is believed.
+	TrustedProxies []string `envconfig:"AUTH_TRUSTED_PROXIES" default:"127.0.0.1/32,::1/128"`
+
+	MFAIssuer            string        `envconfig:"AUTH_MFA_ISSUER" default:"backend-golang-test"`
+	MFAChallengeTTL      time.Duration `envconfig:"AUTH_MFA_CHALLENGE_TTL" default:"5m"`
+	MFARecoveryCodeCount int           `envconfig:"AUTH_MFA_RECOVERY_CODE_COUNT" default:"10"`
+
+	APIKeyLastUsedInterval    time.Duration `envconfig:"AUTH_API_KEY_LAST_USED_INTERVAL" default:"1m"`
+	SessionLastActiveInterval time.Duration `envconfig:"AUTH_SESSION_LAST_ACTIVE_INTERVAL" default:"1m"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength        int      `envconfig:"PASSWORD_MIN_LENGTH" default:"8"`
+	MaxLength        int      `envconfig:"PASSWORD_MAX_LENGTH" default:"72"`
+	CharacterClasses []string `envconfig:"PASSWORD_CHARACTER_CLASSES"`
+	BreachedListFile string   `envconfig:"PASSWORD_BREACHED_LIST_FILE"`
+	HistorySize      int      `envconfig:"PASSWORD_HISTORY_SIZE" default:"3"`
+}
+
+type PasswordHashConfig struct {
+	Algorithm         string `envconfig:"PASSWORD_HASH_ALGORITHM" default:"argon2id"`
+	Argon2Memory      uint32 `envconfig:"PASSWORD_HASH_ARGON2_MEMORY" default:"65536"`
+	Argon2Iterations  uint32 `envconfig:"PASSWORD_HASH_ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism uint8  `envconfig:"PASSWORD_HASH_ARGON2_PARALLELISM" default:"2"`
+	BcryptCost        int    `envconfig:"PASSWORD_HASH_BCRYPT_COST" default:"12"`
+	Workers           int    `envconfig:"PASSWORD_HASH_WORKERS"`
+	QueueSize         int    `envconfig:"PASSWORD_HASH_QUEUE_SIZE" default:"64"`
+}
+
+type MailerConfig struct {
+	Driver string `envconfig:"MAILER_DRIVER" default:"stdout"`
+	Dir    string `envconfig:"MAILER_DIR" default:".mail"`
+	From   string `envconfig:"MAILER_FROM" default:"no-reply@backend-golang-test.local"`
+}
+
+type OIDCConfig struct {
+	DiscoveryURL    string        `envconfig:"OIDC_DISCOVERY_URL"`
+	ClientID        string        `envconfig:"OIDC_CLIENT_ID"`
+	ClientSecret    string        `envconfig:"OIDC_CLIENT_SECRET"`
+	RedirectURL     string        `envconfig:"OIDC_REDIRECT_URL" default:"http://localhost:8080/api/v1/oidc/callback"`
+	Scopes          []string      `envconfig:"OIDC_SCOPES" default:"openid,email,profile"`
+	RequestTimeout  time.Duration `envconfig:"OIDC_REQUEST_TIMEOUT" default:"10s"`
+	StateTTL        time.Duration `envconfig:"OIDC_STATE_TTL" default:"10m"`
+	StateCookieName string        `envconfig:"OIDC_STATE_COOKIE_NAME" default:"_oidc_state"`
 }
 
 type MongoDBConfig struct {
@@ -44,19 +125,27 @@ type BackendGolangTestGRPCConfig struct {
 }
 
 type AppConfig struct {
-	HTTPConfig    HTTPConfig
-	GRPCConfig    GRPCConfig
-	MongoDB       MongoDBConfig
-	BackendGoTest BackendGolangTestGRPCConfig
-	Auth          AuthConfig
+	HTTPConfig     HTTPConfig
+	GRPCConfig     GRPCConfig
+	MongoDB        MongoDBConfig
+	BackendGoTest  BackendGolangTestGRPCConfig
+	Auth           AuthConfig
+	PasswordPolicy PasswordPolicyConfig
+	PasswordHash   PasswordHashConfig
+	Mailer         MailerConfig
+	OIDC           OIDCConfig
 }
 
 func (cfg *AppConfig) load() {
 	envconfig.MustProcess("", &cfg.HTTPConfig)
 	envconfig.MustProcess("", &cfg.GRPCConfig)
 	envconfig.MustProcess("", &cfg.Auth)
+	envconfig.MustProcess("", &cfg.PasswordPolicy)
+	envconfig.MustProcess("", &cfg.PasswordHash)
 	envconfig.MustProcess("", &cfg.MongoDB)
 	envconfig.MustProcess("", &cfg.BackendGoTest)
+	envconfig.MustProcess("", &cfg.Mailer)
+	envconfig.MustProcess("", &cfg.OIDC)
 }
 
 func ProvideCofig() *AppConfig {
@@ -0,0 +1,82 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+)
+
+const (
+	accessTokenKey = "access_token"
+	apiKeyKey      = "api_key"
+	claimsKey      = "claims"
+)
+
+// SetAccessToken stores the verified access token of the current request so
+// handlers and outgoing gRPC calls behind the auth middleware can use it.
+func SetAccessToken(ctx *gin.Context, accessToken string) {
+	ctx.Set(accessTokenKey, accessToken)
+}
+
+func AccessTokenFromContext(ctx context.Context) string {
+	accessToken, _ := ctx.Value(accessTokenKey).(string)
+	return accessToken
+}
+
+// SetAPIKey stores the verified API key of the current request, it is
+// forwarded to the gRPC server in place of an access token.
+func SetAPIKey(ctx *gin.Context, key string) {
+	ctx.Set(apiKeyKey, key)
+}
+
+func APIKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyKey).(string)
+	return key
+}
+
+// SetClaims stores the verified claims of the current gin request.
+func SetClaims(ctx *gin.Context, claims *token.JwtToken) {
+	ctx.Set(claimsKey, claims)
+}
+
+// WithClaims returns a copy of ctx carrying the verified claims, used by the
+// gRPC interceptors where there is no gin context.
+func WithClaims(ctx context.Context, claims *token.JwtToken) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// UserIDFromContext returns the id of the authenticated caller, or an empty
+// string when the request is not authenticated.
+func UserIDFromContext(ctx context.Context) string {
+	if claims := ClaimsFromContext(ctx); claims != nil {
+		return claims.UserID
+	}
+	return ""
+}
+
+// ClaimsFromContext returns the claims stored by SetClaims or WithClaims, or
+// nil when the request is not authenticated.
+func ClaimsFromContext(ctx context.Context) *token.JwtToken {
+	claims, _ := ctx.Value(claimsKey).(*token.JwtToken)
+	return claims
+}
+
+// ClientIPFromContext returns the IP of the HTTP client when ctx is, or is
+// derived from, a gin request context.
+func ClientIPFromContext(ctx context.Context) string {
+	if gctx, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok {
+		return gctx.ClientIP()
+	}
+	return ""
+}
+
+// DeviceFromContext returns the device id sent by the HTTP client in the
+// X-Device-Id header and its user agent, when ctx is, or is derived from, a
+// gin request context.
+func DeviceFromContext(ctx context.Context) (deviceID, userAgent string) {
+	if gctx, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok {
+		return gctx.GetHeader("X-Device-Id"), gctx.Request.UserAgent()
+	}
+	return "", ""
+}
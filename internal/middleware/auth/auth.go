@@ -1,26 +1,34 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"errors"
+	"log"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
 	"github.com/nuea/backend-golang-test/internal/service"
 	"github.com/nuea/backend-golang-test/internal/service/auth"
+	"google.golang.org/grpc/status"
 )
 
+var ErrInvalidCSRFToken = errors.New("CSRF token is invalid.")
+
 type AuthMiddleware interface {
 	Middleware() gin.HandlerFunc
 }
 
 type authMiddleware struct {
+	cfg    *config.AuthConfig
 	authsv auth.AuthService
 }
 
-func ProvideAuthMiddleware(sv *service.Service) AuthMiddleware {
+func ProvideAuthMiddleware(cfg *config.AppConfig, sv *service.Service) AuthMiddleware {
 	return &authMiddleware{
+		cfg:    &cfg.Auth,
 		authsv: sv.AuthService,
 	}
 }
@@ -28,7 +36,11 @@ func ProvideAuthMiddleware(sv *service.Service) AuthMiddleware {
 func (m *authMiddleware) Middleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		if err := m.authentication(ctx); err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			code := http.StatusUnauthorized
+			if errors.Is(err, ErrInvalidCSRFToken) {
+				code = http.StatusForbidden
+			}
+			ctx.AbortWithStatusJSON(code, gin.H{
 				"error": err.Error(),
 			})
 			return
@@ -37,10 +49,21 @@ func (m *authMiddleware) Middleware() gin.HandlerFunc {
 	}
 }
 
+// authentication accepts, in order, a bearer access token, an API key in
+// the X-API-Key header, or the access token cookie.
 func (m *authMiddleware) authentication(ctx *gin.Context) error {
 	var ac string
 	if acs := strings.Fields(ctx.GetHeader("Authorization")); len(acs) > 1 && acs[0] == "Bearer" {
 		ac = acs[1]
+	} else if key := ctx.GetHeader("X-API-Key"); key != "" {
+		return m.apiKeyAuthentication(ctx, key)
+	} else if cookie, err := ctx.Cookie(m.cfg.CookieName); err == nil && cookie != "" {
+		// The browser sends the cookie on its own, so the request must
+		// prove that it was made by our page.
+		if err := m.checkCSRF(ctx); err != nil {
+			return err
+		}
+		ac = cookie
 	} else {
 		return errors.New("Unauthorized.")
 	}
@@ -50,8 +73,51 @@ func (m *authMiddleware) authentication(ctx *gin.Context) error {
 		return err
 	}
 
-	if claims.ExpiresAt < time.Now().Local().UnixMilli() {
-		return errors.New("Unauthorized.")
+	if claims.Id != "" || claims.SessionID != "" {
+		revoked, err := m.authsv.IsTokenRevoked(ctx, claims.Id, claims.SessionID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return errors.New("Access token has been revoked.")
+		}
+	}
+
+	if claims.IsImpersonated() {
+		log.Printf("Impersonated request to %s %s by %s as %s", ctx.Request.Method, ctx.Request.URL.Path, claims.Actor.Subject, claims.UserID)
+	}
+
+	identity.SetAccessToken(ctx, ac)
+	identity.SetClaims(ctx, claims)
+	return nil
+}
+
+// apiKeyAuthentication verifies the key with the gRPC server, which also
+// records its last use. The key is not sent by the browser on its own, so no
+// CSRF check is needed.
+func (m *authMiddleware) apiKeyAuthentication(ctx *gin.Context, key string) error {
+	claims, err := m.authsv.VerifyAPIKey(ctx, key)
+	if err != nil {
+		return errors.New(status.Convert(err).Message())
+	}
+
+	identity.SetAPIKey(ctx, key)
+	identity.SetClaims(ctx, claims)
+	return nil
+}
+
+// checkCSRF compares the CSRF header with the CSRF cookie set at login
+// (double-submit). Safe methods do not change state and are not checked.
+func (m *authMiddleware) checkCSRF(ctx *gin.Context) error {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	header := ctx.GetHeader(m.cfg.CSRFHeaderName)
+	cookie, err := ctx.Cookie(m.cfg.CSRFCookieName)
+	if err != nil || header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(cookie)) != 1 {
+		return ErrInvalidCSRFToken
 	}
 	return nil
 }
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/service/auth"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockAuthService struct {
+	mock.Mock
+	auth.AuthService
+}
+
+func (m *mockAuthService) VerifyAccessToken(accessToken string) (*token.JwtToken, error) {
+	args := m.Called(accessToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*token.JwtToken), args.Error(1)
+}
+
+func (m *mockAuthService) IsTokenRevoked(ctx context.Context, tokenID, sessionID string) (bool, error) {
+	args := m.Called(tokenID, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockAuthService) VerifyAPIKey(ctx context.Context, key string) (*token.JwtToken, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*token.JwtToken), args.Error(1)
+}
+
+var testcfg = &config.AuthConfig{
+	CookieName:     "_uac",
+	CSRFCookieName: "_csrf",
+	CSRFHeaderName: "X-CSRF-Token",
+}
+
+func serve(m *authMiddleware, req *http.Request) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, identity.AccessTokenFromContext(ctx))
+	}
+	r.GET("/", m.Middleware(), handler)
+	r.POST("/", m.Middleware(), handler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	claims := &token.JwtToken{
+		StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: time.Now().Add(time.Minute).Unix()},
+		UserID:         "uid",
+		SessionID:      "sid",
+	}
+
+	newService := func() *mockAuthService {
+		sv := new(mockAuthService)
+		sv.On("VerifyAccessToken", "access-token").Return(claims, nil)
+		sv.On("IsTokenRevoked", "jti", "sid").Return(false, nil)
+		return sv
+	}
+
+	t.Run("bearer token", func(t *testing.T) {
+		m := &authMiddleware{cfg: testcfg, authsv: newService()}
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer access-token")
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "access-token", rec.Body.String())
+	})
+
+	t.Run("cookie on safe method", func(t *testing.T) {
+		m := &authMiddleware{cfg: testcfg, authsv: newService()}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "_uac", Value: "access-token"})
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "access-token", rec.Body.String())
+	})
+
+	t.Run("cookie with csrf token", func(t *testing.T) {
+		m := &authMiddleware{cfg: testcfg, authsv: newService()}
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "_uac", Value: "access-token"})
+		req.AddCookie(&http.Cookie{Name: "_csrf", Value: "csrf-token"})
+		req.Header.Set("X-CSRF-Token", "csrf-token")
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("cookie without csrf token", func(t *testing.T) {
+		sv := newService()
+		m := &authMiddleware{cfg: testcfg, authsv: sv}
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "_uac", Value: "access-token"})
+		req.AddCookie(&http.Cookie{Name: "_csrf", Value: "csrf-token"})
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "CSRF token is invalid.")
+		sv.AssertNotCalled(t, "VerifyAccessToken", mock.Anything)
+	})
+
+	t.Run("cookie with wrong csrf token", func(t *testing.T) {
+		m := &authMiddleware{cfg: testcfg, authsv: newService()}
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "_uac", Value: "access-token"})
+		req.AddCookie(&http.Cookie{Name: "_csrf", Value: "csrf-token"})
+		req.Header.Set("X-CSRF-Token", "other-token")
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		m := &authMiddleware{cfg: testcfg, authsv: newService()}
+
+		rec := serve(m, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		sv := new(mockAuthService)
+		m := &authMiddleware{cfg: testcfg, authsv: sv}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "_uac", Value: "access-token"})
+
+		sv.On("VerifyAccessToken", "access-token").Return(claims, nil).Once()
+		sv.On("IsTokenRevoked", "jti", "sid").Return(true, nil).Once()
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Access token has been revoked.")
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		sv := new(mockAuthService)
+		m := &authMiddleware{cfg: testcfg, authsv: sv}
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-API-Key", "bgt_key")
+
+		sv.On("VerifyAPIKey", "bgt_key").Return(&token.JwtToken{UserID: "uid", APIKeyID: "kid"}, nil).Once()
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		sv.AssertNotCalled(t, "VerifyAccessToken", mock.Anything)
+	})
+
+	t.Run("invalid api key", func(t *testing.T) {
+		sv := new(mockAuthService)
+		m := &authMiddleware{cfg: testcfg, authsv: sv}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "bgt_bad")
+
+		sv.On("VerifyAPIKey", "bgt_bad").Return(nil, status.Error(codes.Unauthenticated, "API key is invalid.")).Once()
+
+		rec := serve(m, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"API key is invalid."`)
+	})
+}
@@ -3,14 +3,17 @@ package middleware
 import (
 	"github.com/google/wire"
 	"github.com/nuea/backend-golang-test/internal/middleware/auth"
+	"github.com/nuea/backend-golang-test/internal/middleware/permission"
 )
 
 type Middleware struct {
-	Auth auth.AuthMiddleware
+	Auth       auth.AuthMiddleware
+	Permission permission.PermissionMiddleware
 }
 
 var MiddlewareSet = wire.NewSet(
 	auth.ProvideAuthMiddleware,
+	permission.ProvidePermissionMiddleware,
 
 	wire.Struct(new(Middleware), "*"),
 )
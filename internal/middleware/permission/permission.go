@@ -0,0 +1,89 @@
+package permission
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+)
+
+type PermissionMiddleware interface {
+	Require(perm rbac.Permission) gin.HandlerFunc
+	RequireOrOwner(perm rbac.Permission, param string) gin.HandlerFunc
+	RequireScope(perm rbac.Permission) gin.HandlerFunc
+}
+
+type permissionMiddleware struct{}
+
+func ProvidePermissionMiddleware() PermissionMiddleware {
+	return &permissionMiddleware{}
+}
+
+// Require must be registered after the auth middleware, it reads the roles
+// from the verified claims.
+func (m *permissionMiddleware) Require(perm rbac.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims := identity.ClaimsFromContext(ctx)
+		if claims == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized.",
+			})
+			return
+		}
+
+		if !claims.HasPermission(perm) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Permission denied.",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireOrOwner behaves like Require, but also lets the caller through when
+// the path parameter is their own user id.
+func (m *permissionMiddleware) RequireOrOwner(perm rbac.Permission, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims := identity.ClaimsFromContext(ctx)
+		if claims == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized.",
+			})
+			return
+		}
+
+		owner := ctx.Param(param) == claims.UserID && claims.HasScope(perm)
+		if !claims.HasPermission(perm) && !owner {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Permission denied.",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireScope guards the routes on the caller's own account, like
+// /users/me. Users always pass, an API key only with perm in its scopes, and
+// a client token, which has no account, never.
+func (m *permissionMiddleware) RequireScope(perm rbac.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims := identity.ClaimsFromContext(ctx)
+		if claims == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized.",
+			})
+			return
+		}
+
+		if claims.ClientID != "" || !claims.HasScope(perm) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Permission denied.",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package permission
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(claims *token.JwtToken, h gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PATCH("/users/me", func(ctx *gin.Context) {
+		if claims != nil {
+			identity.SetClaims(ctx, claims)
+		}
+	}, h, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/users/me", nil))
+	return rec
+}
+
+func TestRequireScope(t *testing.T) {
+	m := ProvidePermissionMiddleware()
+	h := m.RequireScope(rbac.PermissionUsersWrite)
+
+	t.Run("user", func(t *testing.T) {
+		rec := serve(&token.JwtToken{UserID: "uid", Roles: []string{"user"}}, h)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("api key within scopes", func(t *testing.T) {
+		rec := serve(&token.JwtToken{UserID: "uid", APIKeyID: "kid", Scopes: []string{"users:write"}}, h)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("read-only api key", func(t *testing.T) {
+		rec := serve(&token.JwtToken{UserID: "uid", APIKeyID: "kid", Scopes: []string{"users:read"}}, h)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("client token", func(t *testing.T) {
+		rec := serve(&token.JwtToken{ClientID: "client", Scopes: []string{"users:write"}}, h)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		rec := serve(nil, h)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
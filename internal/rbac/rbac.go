@@ -0,0 +1,100 @@
+package rbac
+
+import (
+	"errors"
+	"slices"
+)
+
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleSupport Role = "support"
+	RoleUser    Role = "user"
+)
+
+type Permission string
+
+const (
+	PermissionUsersRead   Permission = "users:read"
+	PermissionUsersWrite  Permission = "users:write"
+	PermissionUsersDelete Permission = "users:delete"
+	PermissionRolesManage Permission = "roles:manage"
+	PermissionUsersUnlock Permission = "users:unlock"
+
+	PermissionUsersImpersonate Permission = "users:impersonate"
+
+	PermissionSessionsManage   Permission = "sessions:manage"
+	PermissionTokensIntrospect Permission = "tokens:introspect"
+
+	PermissionServiceAccountsManage Permission = "service_accounts:manage"
+	PermissionOAuthClientsManage    Permission = "oauth_clients:manage"
+)
+
+// rolePermissions is the single source of truth for what each role may do.
+// The user role has no management permissions, regular users can only act
+// on their own account.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionUsersDelete,
+		PermissionRolesManage,
+		PermissionUsersUnlock,
+		PermissionUsersImpersonate,
+		PermissionSessionsManage,
+		PermissionTokensIntrospect,
+		PermissionServiceAccountsManage,
+		PermissionOAuthClientsManage,
+	},
+	RoleSupport: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+	},
+	RoleUser: {},
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", errors.New("Role is invalid.")
+	}
+	return role, nil
+}
+
+// ParsePermission accepts any permission granted by at least one role, it is
+// used to validate API key and OAuth client scopes.
+func ParsePermission(s string) (Permission, error) {
+	perm := Permission(s)
+	for _, perms := range rolePermissions {
+		if slices.Contains(perms, perm) {
+			return perm, nil
+		}
+	}
+	return "", errors.New("Permission is invalid.")
+}
+
+// HasPermission reports whether any of the roles grants perm. Unknown roles
+// grant nothing.
+func HasPermission(roles []string, perm Permission) bool {
+	for _, r := range roles {
+		if slices.Contains(rolePermissions[Role(r)], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns every permission granted by the roles, without
+// duplicates. Unknown roles grant nothing.
+func Permissions(roles []string) []Permission {
+	var perms []Permission
+	for _, r := range roles {
+		for _, p := range rolePermissions[Role(r)] {
+			if !slices.Contains(perms, p) {
+				perms = append(perms, p)
+			}
+		}
+	}
+	return perms
+}
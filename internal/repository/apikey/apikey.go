@@ -0,0 +1,127 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKeyRepository interface {
+	InsertOne(ctx context.Context, key *APIKey) error
+	FindByKey(ctx context.Context, key string) (*APIKey, error)
+	FindByID(ctx context.Context, id string) (*APIKey, error)
+	FindByOwner(ctx context.Context, ownerType, ownerID string) ([]*APIKey, error)
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+	RevokeByOwner(ctx context.Context, ownerType, ownerID string) error
+	TouchLastUsed(ctx context.Context, id primitive.ObjectID, interval time.Duration) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideAPIKeyRepository(c *client.Clients) APIKeyRepository {
+	collection := c.MongoDB.GetCollection("api_key")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "secret_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "owner_type", Value: 1}, {Key: "owner_id", Value: 1}},
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, key *APIKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r *repository) FindByKey(ctx context.Context, key string) (k *APIKey, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"secret_hash": util.HashToken(key)}).Decode(&k)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id string) (k *APIKey, err error) {
+	objid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objid}).Decode(&k)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *repository) FindByOwner(ctx context.Context, ownerType, ownerID string) (keys []*APIKey, err error) {
+	cur, err := r.collection.Find(ctx, bson.M{"owner_type": ownerType, "owner_id": ownerID})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	if err = cur.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *repository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
+
+// RevokeByOwner revokes every key of the owner, e.g. when a service account
+// is deleted.
+func (r *repository) RevokeByOwner(ctx context.Context, ownerType, ownerID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"owner_type": ownerType, "owner_id": ownerID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
+
+// TouchLastUsed records that the key was used. A busy key is written at most
+// once per interval, the filter skips the update when last_used_at is recent.
+func (r *repository) TouchLastUsed(ctx context.Context, id primitive.ObjectID, interval time.Duration) error {
+	now := time.Now().UTC()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "$or": bson.A{
+			bson.M{"last_used_at": nil},
+			bson.M{"last_used_at": bson.M{"$lte": now.Add(-interval)}},
+		}},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	)
+	return err
+}
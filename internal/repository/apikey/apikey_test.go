@@ -0,0 +1,255 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideAPIKeyRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideAPIKeyRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestNewAPIKey(t *testing.T) {
+	k, key, err := NewAPIKey("batch", "owner", OwnerTypeUser, []string{"users:read"})
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(key, KeyPrefix))
+	assert.Equal(t, key[:12], k.Prefix)
+	assert.Equal(t, util.HashToken(key), k.SecretHash)
+	assert.NotContains(t, k.SecretHash, key)
+	assert.False(t, k.IsExpired())
+	assert.False(t, k.IsRevoked())
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	k, _, _ := NewAPIKey("batch", "owner", OwnerTypeUser, nil)
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), k)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "some other write error"
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    1,
+			Message: msg,
+		}))
+
+		err := repo.InsertOne(context.Background(), k)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestFindByKey(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.api_key", mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: id},
+				{Key: "owner_id", Value: "owner"},
+				{Key: "owner_type", Value: OwnerTypeUser},
+				{Key: "secret_hash", Value: util.HashToken("bgt_key")},
+			}))
+
+		k, err := repo.FindByKey(context.Background(), "bgt_key")
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, k.ID)
+		assert.Equal(t, "owner", k.OwnerID)
+	})
+
+	mt.Run("api key not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.api_key", mtest.FirstBatch))
+
+		k, err := repo.FindByKey(context.Background(), "bgt_key")
+
+		assert.Nil(t, k)
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		k, err := repo.FindByKey(context.Background(), "bgt_key")
+
+		assert.Nil(t, k)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestFindByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.api_key", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "batch"}}))
+
+		k, err := repo.FindByID(context.Background(), id.Hex())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "batch", k.Name)
+	})
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		k, err := repo.FindByID(context.Background(), "invalid")
+
+		assert.Nil(t, k)
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+
+	mt.Run("api key not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.api_key", mtest.FirstBatch))
+
+		k, err := repo.FindByID(context.Background(), id.Hex())
+
+		assert.Nil(t, k)
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+}
+
+func TestFindByOwner(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		first := mtest.CreateCursorResponse(1, "test.api_key", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "first"}})
+		second := mtest.CreateCursorResponse(1, "test.api_key", mtest.NextBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "second"}})
+		killCursors := mtest.CreateCursorResponse(0, "test.api_key", mtest.NextBatch)
+		mt.AddMockResponses(first, second, killCursors)
+
+		keys, err := repo.FindByOwner(context.Background(), OwnerTypeUser, "owner")
+
+		assert.Nil(t, err)
+		assert.Len(t, keys, 2)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		keys, err := repo.FindByOwner(context.Background(), OwnerTypeUser, "owner")
+
+		assert.Nil(t, keys)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestRevoke(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Revoke(context.Background(), primitive.NewObjectID())
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.Revoke(context.Background(), primitive.NewObjectID())
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestRevokeByOwner(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+
+		err := repo.RevokeByOwner(context.Background(), OwnerTypeServiceAccount, "sa")
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestTouchLastUsed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.TouchLastUsed(context.Background(), primitive.NewObjectID(), time.Minute)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("recently used", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.TouchLastUsed(context.Background(), primitive.NewObjectID(), time.Minute)
+
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	OwnerTypeUser           = "user"
+	OwnerTypeServiceAccount = "service_account"
+
+	// KeyPrefix marks our keys, so that secret scanners can recognize a
+	// leaked one.
+	KeyPrefix = "bgt_"
+
+	prefixLength = 12
+)
+
+// APIKey is a long-lived credential for scripts and batch jobs. Only the
+// hash of the key is stored, Prefix is kept in clear so the owner can tell
+// their keys apart.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Name       string             `bson:"name"`
+	Prefix     string             `bson:"prefix"`
+	SecretHash string             `bson:"secret_hash"`
+	OwnerID    string             `bson:"owner_id"`
+	OwnerType  string             `bson:"owner_type"`
+	Scopes     []string           `bson:"scopes"`
+	CreatedBy  string             `bson:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	ExpiresAt  *time.Time         `bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+// NewAPIKey returns the key to persist and the key to show to the caller
+// once.
+func NewAPIKey(name, ownerID, ownerType string, scopes []string) (*APIKey, string, error) {
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	key := KeyPrefix + secret
+
+	return &APIKey{
+		Name:       name,
+		Prefix:     key[:prefixLength],
+		SecretHash: util.HashToken(key),
+		OwnerID:    ownerID,
+		OwnerType:  ownerType,
+		Scopes:     scopes,
+		CreatedAt:  time.Now().UTC(),
+	}, key, nil
+}
+
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().UTC().After(*k.ExpiresAt)
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,43 @@
+package auditlog
+
+import (
+	"context"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AuditLogRepository interface {
+	InsertOne(ctx context.Context, l *AuditLog) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+// ProvideAuditLogRepository keeps the audit trail without expiry, it is
+// searched by the affected user or by the actor.
+func ProvideAuditLogRepository(c *client.Clients) AuditLogRepository {
+	collection := c.MongoDB.GetCollection("audit_log")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+			{
+				Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, l *AuditLog) error {
+	_, err := r.collection.InsertOne(ctx, l)
+	return err
+}
@@ -0,0 +1,60 @@
+package auditlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideAuditLogRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideAuditLogRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	l := NewAuditLog(ActionImpersonationStart, "admin", "uid")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), l)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "some other write error"
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    1,
+			Message: msg,
+		}))
+
+		err := repo.InsertOne(context.Background(), l)
+
+		assert.Error(t, err, msg)
+	})
+}
@@ -0,0 +1,34 @@
+package auditlog
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ActionImpersonationStart   = "impersonation.start"
+	ActionImpersonationRequest = "impersonation.request"
+)
+
+// AuditLog records an action taken by ActorID on the account of UserID.
+type AuditLog struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Action    string             `bson:"action"`
+	ActorID   string             `bson:"actor_id"`
+	UserID    string             `bson:"user_id"`
+	Method    string             `bson:"method,omitempty"`
+	Reason    string             `bson:"reason,omitempty"`
+	IP        string             `bson:"ip,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+func NewAuditLog(action, actorID, userID string) *AuditLog {
+	return &AuditLog{
+		ID:        primitive.NewObjectID(),
+		Action:    action,
+		ActorID:   actorID,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,93 @@
+package emailverification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrEmailVerificationTokenNotFound = errors.New("email verification token not found")
+	ErrEmailVerificationTokenUsed     = errors.New("email verification token already used")
+)
+
+type EmailVerificationRepository interface {
+	InsertOne(ctx context.Context, token *EmailVerificationToken) error
+	FindByToken(ctx context.Context, token string) (*EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID) error
+	CountSince(ctx context.Context, userID string, since time.Time) (int64, error)
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideEmailVerificationRepository(c *client.Clients) EmailVerificationRepository {
+	collection := c.MongoDB.GetCollection("email_verification_token")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}},
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, token *EmailVerificationToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *repository) FindByToken(ctx context.Context, token string) (evt *EmailVerificationToken, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"token_hash": util.HashToken(token)}).Decode(&evt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrEmailVerificationTokenNotFound
+		}
+		return nil, err
+	}
+	return evt, nil
+}
+
+func (r *repository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrEmailVerificationTokenUsed
+	}
+	return nil
+}
+
+// CountSince returns how many tokens were issued to the user since the given
+// time, used to rate limit verification emails.
+func (r *repository) CountSince(ctx context.Context, userID string, since time.Time) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"created_at": bson.M{"$gte": since},
+	})
+}
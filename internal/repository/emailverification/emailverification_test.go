@@ -0,0 +1,173 @@
+package emailverification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideEmailVerificationRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideEmailVerificationRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	evt := NewEmailVerificationToken("user", "test@example.com", "token", time.Hour)
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), evt)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "some other write error"
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    1,
+			Message: msg,
+		}))
+
+		err := repo.InsertOne(context.Background(), evt)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestFindByToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.email_verification_token", mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: id},
+				{Key: "user_id", Value: "user"},
+				{Key: "token_hash", Value: util.HashToken("token")},
+			}))
+
+		evt, err := repo.FindByToken(context.Background(), "token")
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, evt.ID)
+		assert.Equal(t, "user", evt.UserID)
+	})
+
+	mt.Run("email verification token not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.email_verification_token", mtest.FirstBatch))
+
+		evt, err := repo.FindByToken(context.Background(), "token")
+
+		assert.Nil(t, evt)
+		assert.ErrorIs(t, err, ErrEmailVerificationTokenNotFound)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		evt, err := repo.FindByToken(context.Background(), "token")
+
+		assert.Nil(t, evt)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestMarkUsed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("already used", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.ErrorIs(t, err, ErrEmailVerificationTokenUsed)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestCountSince(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.email_verification_token", mtest.FirstBatch,
+			bson.D{{Key: "n", Value: int32(2)}}))
+
+		count, err := repo.CountSince(context.Background(), "user", time.Now().Add(-time.Hour))
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		count, err := repo.CountSince(context.Background(), "user", time.Now().Add(-time.Hour))
+
+		assert.Equal(t, int64(0), count)
+		assert.Error(t, err, msg)
+	})
+}
@@ -0,0 +1,36 @@
+package emailverification
+
+import (
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailVerificationToken is bound to the address it was sent to, so a token
+// stops working once the user changes their email.
+type EmailVerificationToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    string             `bson:"user_id"`
+	Email     types.Email        `bson:"email"`
+	TokenHash string             `bson:"token_hash"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+}
+
+func NewEmailVerificationToken(userID string, email types.Email, token string, ttl time.Duration) *EmailVerificationToken {
+	now := time.Now().UTC()
+	return &EmailVerificationToken{
+		UserID:    userID,
+		Email:     email,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+func (t *EmailVerificationToken) IsExpired() bool {
+	return time.Now().UTC().After(t.ExpiresAt)
+}
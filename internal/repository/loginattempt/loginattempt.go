@@ -0,0 +1,89 @@
+package loginattempt
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrLoginAttemptNotFound = errors.New("login attempt not found")
+
+type LoginAttemptRepository interface {
+	FindByKey(ctx context.Context, key string) (*LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, expiresAt time.Time) (*LoginAttempt, error)
+	Lock(ctx context.Context, key string, lockedUntil, expiresAt time.Time) error
+	DeleteByKey(ctx context.Context, key string) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+// ProvideLoginAttemptRepository stores the failed login counters in MongoDB so
+// that a lockout survives a restart of the server.
+func ProvideLoginAttemptRepository(c *client.Clients) LoginAttemptRepository {
+	collection := c.MongoDB.GetCollection("login_attempt")
+	collection.Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) FindByKey(ctx context.Context, key string) (attempt *LoginAttempt, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&attempt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrLoginAttemptNotFound
+		}
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// RecordFailure increments the failure counter of key, creating it when
+// needed, and returns the updated counter.
+func (r *repository) RecordFailure(ctx context.Context, key string, expiresAt time.Time) (attempt *LoginAttempt, err error) {
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$inc": bson.M{"failures": 1},
+			"$set": bson.M{
+				"last_failed_at": time.Now().UTC(),
+				"expires_at":     expiresAt.UTC(),
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+func (r *repository) Lock(ctx context.Context, key string, lockedUntil, expiresAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{"$set": bson.M{
+			"locked_until": lockedUntil.UTC(),
+			"expires_at":   expiresAt.UTC(),
+		}},
+	)
+	return err
+}
+
+func (r *repository) DeleteByKey(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,172 @@
+package loginattempt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideLoginAttemptRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideLoginAttemptRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestFindByKey(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	key := UserKey("user")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.login_attempt", mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: key},
+				{Key: "failures", Value: int64(3)},
+			}))
+
+		attempt, err := repo.FindByKey(context.Background(), key)
+
+		assert.Nil(t, err)
+		assert.Equal(t, key, attempt.Key)
+		assert.Equal(t, int64(3), attempt.Failures)
+	})
+
+	mt.Run("login attempt not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.login_attempt", mtest.FirstBatch))
+
+		attempt, err := repo.FindByKey(context.Background(), key)
+
+		assert.Nil(t, attempt)
+		assert.ErrorIs(t, err, ErrLoginAttemptNotFound)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		attempt, err := repo.FindByKey(context.Background(), key)
+
+		assert.Nil(t, attempt)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestRecordFailure(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	key := IPKey("127.0.0.1")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: bson.D{
+				{Key: "_id", Value: key},
+				{Key: "failures", Value: int64(1)},
+			}},
+		})
+
+		attempt, err := repo.RecordFailure(context.Background(), key, time.Now().Add(time.Minute))
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), attempt.Failures)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		attempt, err := repo.RecordFailure(context.Background(), key, time.Now().Add(time.Minute))
+
+		assert.Nil(t, attempt)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestLock(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	key := UserKey("user")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Lock(context.Background(), key, time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.Lock(context.Background(), key, time.Now().Add(time.Minute), time.Now().Add(time.Hour))
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestDeleteByKey(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	key := UserKey("user")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
+
+		err := repo.DeleteByKey(context.Background(), key)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.DeleteByKey(context.Background(), key)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Zero(t, (&LoginAttempt{}).RetryAfter())
+
+	past := time.Now().Add(-time.Minute)
+	assert.Zero(t, (&LoginAttempt{LockedUntil: &past}).RetryAfter())
+
+	future := time.Now().Add(time.Minute)
+	assert.Greater(t, (&LoginAttempt{LockedUntil: &future}).RetryAfter(), 59*time.Second)
+}
@@ -0,0 +1,44 @@
+package loginattempt
+
+import "time"
+
+// LoginAttempt counts the failed logins of one key, either an account or a
+// client IP. The document is removed once ExpiresAt passes without another
+// failure, which resets the counter.
+type LoginAttempt struct {
+	Key          string     `bson:"_id"`
+	Failures     int64      `bson:"failures"`
+	LastFailedAt time.Time  `bson:"last_failed_at"`
+	LockedUntil  *time.Time `bson:"locked_until,omitempty"`
+	ExpiresAt    time.Time  `bson:"expires_at"`
+}
+
+func UserKey(userID string) string {
+	return "user:" + userID
+}
+
+// EmailKey counts the failed logins to an email without an account, so they
+// are locked like the logins to an account.
+func EmailKey(email string) string {
+	return "email:" + email
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (a *LoginAttempt) IsExpired() bool {
+	return time.Now().UTC().After(a.ExpiresAt)
+}
+
+// RetryAfter returns how long the key stays locked, or zero when it is not
+// locked.
+func (a *LoginAttempt) RetryAfter() time.Duration {
+	if a.LockedUntil == nil {
+		return 0
+	}
+	if d := time.Until(*a.LockedUntil); d > 0 {
+		return d
+	}
+	return 0
+}
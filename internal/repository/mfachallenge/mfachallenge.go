@@ -0,0 +1,82 @@
+package mfachallenge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrMFAChallengeNotFound = errors.New("mfa challenge not found")
+	ErrMFAChallengeUsed     = errors.New("mfa challenge already used")
+)
+
+type MFAChallengeRepository interface {
+	InsertOne(ctx context.Context, token *MFAChallenge) error
+	FindByToken(ctx context.Context, token string) (*MFAChallenge, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideMFAChallengeRepository(c *client.Clients) MFAChallengeRepository {
+	collection := c.MongoDB.GetCollection("mfa_challenge")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, token *MFAChallenge) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *repository) FindByToken(ctx context.Context, token string) (challenge *MFAChallenge, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"token_hash": util.HashToken(token)}).Decode(&challenge)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMFAChallengeNotFound
+		}
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// MarkUsed consumes the challenge, so one password check completes at most
+// one login.
+func (r *repository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrMFAChallengeUsed
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package mfachallenge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideMFAChallengeRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideMFAChallengeRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	challenge := NewMFAChallenge("user", "token", time.Hour)
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), challenge)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "some other write error"
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    1,
+			Message: msg,
+		}))
+
+		err := repo.InsertOne(context.Background(), challenge)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestFindByToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.mfa_challenge", mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: id},
+				{Key: "user_id", Value: "user"},
+				{Key: "token_hash", Value: util.HashToken("token")},
+			}))
+
+		challenge, err := repo.FindByToken(context.Background(), "token")
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, challenge.ID)
+		assert.Equal(t, "user", challenge.UserID)
+	})
+
+	mt.Run("mfa challenge not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.mfa_challenge", mtest.FirstBatch))
+
+		challenge, err := repo.FindByToken(context.Background(), "token")
+
+		assert.Nil(t, challenge)
+		assert.ErrorIs(t, err, ErrMFAChallengeNotFound)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		challenge, err := repo.FindByToken(context.Background(), "token")
+
+		assert.Nil(t, challenge)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestMarkUsed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("already used", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.ErrorIs(t, err, ErrMFAChallengeUsed)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.Error(t, err, msg)
+	})
+}
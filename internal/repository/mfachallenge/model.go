@@ -0,0 +1,33 @@
+package mfachallenge
+
+import (
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MFAChallenge is issued by Login once the password is checked, and is
+// exchanged together with a second factor for the token pair.
+type MFAChallenge struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    string             `bson:"user_id"`
+	TokenHash string             `bson:"token_hash"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+}
+
+func NewMFAChallenge(userID, token string, ttl time.Duration) *MFAChallenge {
+	now := time.Now().UTC()
+	return &MFAChallenge{
+		UserID:    userID,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+func (t *MFAChallenge) IsExpired() bool {
+	return time.Now().UTC().After(t.ExpiresAt)
+}
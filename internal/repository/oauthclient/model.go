@@ -0,0 +1,53 @@
+package oauthclient
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SecretPrefix marks our client secrets, so that secret scanners can
+// recognize a leaked one.
+const SecretPrefix = "bgtcs_"
+
+// OAuthClient is a machine client of the client_credentials grant. Only the
+// hash of the secret is stored, Scopes are the permissions it may request.
+type OAuthClient struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	ClientID   string             `bson:"client_id"`
+	Name       string             `bson:"name"`
+	SecretHash string             `bson:"secret_hash"`
+	Scopes     []string           `bson:"scopes"`
+	CreatedBy  string             `bson:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+// NewOAuthClient returns the client to persist and the secret to show to
+// the caller once.
+func NewOAuthClient(name string, scopes []string, createdBy string) (*OAuthClient, string, error) {
+	clientID, err := util.RandomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	secret = SecretPrefix + secret
+
+	return &OAuthClient{
+		ClientID:   clientID,
+		Name:       name,
+		SecretHash: util.HashToken(secret),
+		Scopes:     scopes,
+		CreatedBy:  createdBy,
+		CreatedAt:  time.Now().UTC(),
+	}, secret, nil
+}
+
+func (c *OAuthClient) VerifySecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(util.HashToken(secret)), []byte(c.SecretHash)) == 1
+}
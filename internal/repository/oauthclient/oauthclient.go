@@ -0,0 +1,85 @@
+package oauthclient
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+type OAuthClientRepository interface {
+	InsertOne(ctx context.Context, c *OAuthClient) error
+	FindByClientID(ctx context.Context, clientID string) (*OAuthClient, error)
+	Find(ctx context.Context) ([]*OAuthClient, error)
+	DeleteByID(ctx context.Context, id string) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideOAuthClientRepository(c *client.Clients) OAuthClientRepository {
+	collection := c.MongoDB.GetCollection("oauth_client")
+	collection.Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "client_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, c *OAuthClient) error {
+	_, err := r.collection.InsertOne(ctx, c)
+	return err
+}
+
+func (r *repository) FindByClientID(ctx context.Context, clientID string) (c *OAuthClient, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&c)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *repository) Find(ctx context.Context) (clients []*OAuthClient, err error) {
+	cur, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	if err = cur.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (r *repository) DeleteByID(ctx context.Context, id string) error {
+	objid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrOAuthClientNotFound
+	}
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": objid})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
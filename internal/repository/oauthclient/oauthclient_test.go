@@ -0,0 +1,137 @@
+package oauthclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideOAuthClientRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideOAuthClientRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestNewOAuthClient(t *testing.T) {
+	c, secret, err := NewOAuthClient("reporting", []string{"users:read"}, "admin")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, c.ClientID)
+	assert.True(t, strings.HasPrefix(secret, SecretPrefix))
+	assert.NotContains(t, c.SecretHash, secret)
+	assert.True(t, c.VerifySecret(secret))
+	assert.False(t, c.VerifySecret(secret+"x"))
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	c, _, _ := NewOAuthClient("reporting", []string{"users:read"}, "admin")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), c)
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestFindByClientID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.oauth_client", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "client_id", Value: "client"}, {Key: "name", Value: "reporting"}}))
+
+		c, err := repo.FindByClientID(context.Background(), "client")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "reporting", c.Name)
+	})
+
+	mt.Run("oauth client not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.oauth_client", mtest.FirstBatch))
+
+		c, err := repo.FindByClientID(context.Background(), "client")
+
+		assert.Nil(t, c)
+		assert.ErrorIs(t, err, ErrOAuthClientNotFound)
+	})
+}
+
+func TestFind(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		first := mtest.CreateCursorResponse(1, "test.oauth_client", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "client_id", Value: "client"}})
+		killCursors := mtest.CreateCursorResponse(0, "test.oauth_client", mtest.NextBatch)
+		mt.AddMockResponses(first, killCursors)
+
+		clients, err := repo.Find(context.Background())
+
+		assert.Nil(t, err)
+		assert.Len(t, clients, 1)
+	})
+}
+
+func TestDeleteByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
+
+		err := repo.DeleteByID(context.Background(), id.Hex())
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("oauth client not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+
+		err := repo.DeleteByID(context.Background(), id.Hex())
+
+		assert.ErrorIs(t, err, ErrOAuthClientNotFound)
+	})
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		err := repo.DeleteByID(context.Background(), "invalid")
+
+		assert.ErrorIs(t, err, ErrOAuthClientNotFound)
+	})
+}
@@ -0,0 +1,36 @@
+package oidcstate
+
+import (
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCState is kept between the redirect to the identity provider and the
+// callback. The state itself is only stored as a hash, the nonce and the
+// PKCE code verifier are useless without the authorization code.
+type OIDCState struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	StateHash    string             `bson:"state_hash"`
+	Nonce        string             `bson:"nonce"`
+	CodeVerifier string             `bson:"code_verifier"`
+	ExpiresAt    time.Time          `bson:"expires_at"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	UsedAt       *time.Time         `bson:"used_at,omitempty"`
+}
+
+func NewOIDCState(state, nonce, codeVerifier string, ttl time.Duration) *OIDCState {
+	now := time.Now().UTC()
+	return &OIDCState{
+		StateHash:    util.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(ttl),
+		CreatedAt:    now,
+	}
+}
+
+func (s *OIDCState) IsExpired() bool {
+	return time.Now().UTC().After(s.ExpiresAt)
+}
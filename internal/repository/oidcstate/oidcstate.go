@@ -0,0 +1,82 @@
+package oidcstate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrOIDCStateNotFound = errors.New("oidc state not found")
+	ErrOIDCStateUsed     = errors.New("oidc state already used")
+)
+
+type OIDCStateRepository interface {
+	InsertOne(ctx context.Context, state *OIDCState) error
+	FindByState(ctx context.Context, state string) (*OIDCState, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideOIDCStateRepository(c *client.Clients) OIDCStateRepository {
+	collection := c.MongoDB.GetCollection("oidc_state")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "state_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, state *OIDCState) error {
+	_, err := r.collection.InsertOne(ctx, state)
+	return err
+}
+
+func (r *repository) FindByState(ctx context.Context, state string) (s *OIDCState, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"state_hash": util.HashToken(state)}).Decode(&s)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOIDCStateNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// MarkUsed consumes the state, so one authorization completes at most one
+// login.
+func (r *repository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrOIDCStateUsed
+	}
+	return nil
+}
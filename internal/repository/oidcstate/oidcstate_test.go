@@ -0,0 +1,147 @@
+package oidcstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideOIDCStateRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideOIDCStateRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	state := NewOIDCState("state", "nonce", "verifier", time.Hour)
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), state)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "some other write error"
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    1,
+			Message: msg,
+		}))
+
+		err := repo.InsertOne(context.Background(), state)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestFindByState(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.oidc_state", mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: id},
+				{Key: "state_hash", Value: util.HashToken("state")},
+				{Key: "nonce", Value: "nonce"},
+				{Key: "code_verifier", Value: "verifier"},
+			}))
+
+		s, err := repo.FindByState(context.Background(), "state")
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, s.ID)
+		assert.Equal(t, "nonce", s.Nonce)
+		assert.Equal(t, "verifier", s.CodeVerifier)
+	})
+
+	mt.Run("oidc state not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.oidc_state", mtest.FirstBatch))
+
+		s, err := repo.FindByState(context.Background(), "state")
+
+		assert.Nil(t, s)
+		assert.ErrorIs(t, err, ErrOIDCStateNotFound)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		s, err := repo.FindByState(context.Background(), "state")
+
+		assert.Nil(t, s)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestMarkUsed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("already used", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.ErrorIs(t, err, ErrOIDCStateUsed)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.MarkUsed(context.Background(), id)
+
+		assert.Error(t, err, msg)
+	})
+}
@@ -0,0 +1,31 @@
+package passwordreset
+
+import (
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PasswordResetToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    string             `bson:"user_id"`
+	TokenHash string             `bson:"token_hash"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty"`
+}
+
+func NewPasswordResetToken(userID, token string, ttl time.Duration) *PasswordResetToken {
+	now := time.Now().UTC()
+	return &PasswordResetToken{
+		UserID:    userID,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+func (t *PasswordResetToken) IsExpired() bool {
+	return time.Now().UTC().After(t.ExpiresAt)
+}
@@ -0,0 +1,82 @@
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+	ErrPasswordResetTokenUsed     = errors.New("password reset token already used")
+)
+
+type PasswordResetRepository interface {
+	InsertOne(ctx context.Context, token *PasswordResetToken) error
+	FindByToken(ctx context.Context, token string) (*PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvidePasswordResetRepository(c *client.Clients) PasswordResetRepository {
+	collection := c.MongoDB.GetCollection("password_reset_token")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, token *PasswordResetToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *repository) FindByToken(ctx context.Context, token string) (prt *PasswordResetToken, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"token_hash": util.HashToken(token)}).Decode(&prt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPasswordResetTokenNotFound
+		}
+		return nil, err
+	}
+	return prt, nil
+}
+
+// MarkUsed consumes the token. Like refresh tokens, the filter on used_at
+// makes sure a reset link can only succeed once.
+func (r *repository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrPasswordResetTokenUsed
+	}
+	return nil
+}
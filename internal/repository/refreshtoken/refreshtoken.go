@@ -0,0 +1,109 @@
+package refreshtoken
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenUsed     = errors.New("refresh token already used")
+)
+
+type RefreshTokenRepository interface {
+	InsertOne(ctx context.Context, token *RefreshToken) error
+	FindByToken(ctx context.Context, token string) (*RefreshToken, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeByUserID(ctx context.Context, userID string) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideRefreshTokenRepository(c *client.Clients) RefreshTokenRepository {
+	collection := c.MongoDB.GetCollection("refresh_token")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "family_id", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}},
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, token *RefreshToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *repository) FindByToken(ctx context.Context, token string) (rt *RefreshToken, err error) {
+	err = r.collection.FindOne(ctx, bson.M{"token_hash": util.HashToken(token)}).Decode(&rt)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+	return rt, nil
+}
+
+// MarkUsed flags the token as consumed. The filter on used_at makes the
+// update atomic, so two concurrent refreshes with the same token cannot both
+// succeed; the loser gets ErrRefreshTokenUsed.
+func (r *repository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrRefreshTokenUsed
+	}
+	return nil
+}
+
+func (r *repository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"family_id": familyID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
+
+// RevokeByUserID ends every session of the user, e.g. after a password
+// reset.
+func (r *repository) RevokeByUserID(ctx context.Context, userID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
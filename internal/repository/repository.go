@@ -2,15 +2,51 @@ package repository
 
 import (
 	"github.com/google/wire"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/auditlog"
+	"github.com/nuea/backend-golang-test/internal/repository/emailverification"
+	"github.com/nuea/backend-golang-test/internal/repository/loginattempt"
+	"github.com/nuea/backend-golang-test/internal/repository/mfachallenge"
+	"github.com/nuea/backend-golang-test/internal/repository/oauthclient"
+	"github.com/nuea/backend-golang-test/internal/repository/oidcstate"
+	"github.com/nuea/backend-golang-test/internal/repository/passwordreset"
+	"github.com/nuea/backend-golang-test/internal/repository/refreshtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/revokedtoken"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	"github.com/nuea/backend-golang-test/internal/repository/session"
 	"github.com/nuea/backend-golang-test/internal/repository/user"
 )
 
 type Repository struct {
 	user.UserRepository
+	refreshtoken.RefreshTokenRepository
+	revokedtoken.RevokedTokenRepository
+	passwordreset.PasswordResetRepository
+	emailverification.EmailVerificationRepository
+	loginattempt.LoginAttemptRepository
+	mfachallenge.MFAChallengeRepository
+	apikey.APIKeyRepository
+	serviceaccount.ServiceAccountRepository
+	oidcstate.OIDCStateRepository
+	oauthclient.OAuthClientRepository
+	session.SessionRepository
+	auditlog.AuditLogRepository
 }
 
 var RepositorySet = wire.NewSet(
 	user.ProvideUserRepository,
+	refreshtoken.ProvideRefreshTokenRepository,
+	revokedtoken.ProvideRevokedTokenRepository,
+	passwordreset.ProvidePasswordResetRepository,
+	emailverification.ProvideEmailVerificationRepository,
+	loginattempt.ProvideLoginAttemptRepository,
+	mfachallenge.ProvideMFAChallengeRepository,
+	apikey.ProvideAPIKeyRepository,
+	serviceaccount.ProvideServiceAccountRepository,
+	oidcstate.ProvideOIDCStateRepository,
+	oauthclient.ProvideOAuthClientRepository,
+	session.ProvideSessionRepository,
+	auditlog.ProvideAuditLogRepository,
 
 	wire.Struct(new(Repository), "*"),
 )
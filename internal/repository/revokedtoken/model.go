@@ -0,0 +1,19 @@
+package revokedtoken
+
+import "time"
+
+type RevokedToken struct {
+	TokenID   string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	RevokedAt time.Time `bson:"revoked_at"`
+}
+
+func NewRevokedToken(tokenID, userID string, expiresAt time.Time) *RevokedToken {
+	return &RevokedToken{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ExpiresAt: expiresAt.UTC(),
+		RevokedAt: time.Now().UTC(),
+	}
+}
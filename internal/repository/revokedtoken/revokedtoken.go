@@ -0,0 +1,59 @@
+package revokedtoken
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RevokedTokenRepository interface {
+	InsertOne(ctx context.Context, token *RevokedToken) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+// ProvideRevokedTokenRepository keeps revoked access token ids only for as
+// long as the token itself would have been valid; the TTL index on
+// expires_at removes the entry once the token has expired on its own.
+func ProvideRevokedTokenRepository(c *client.Clients) RevokedTokenRepository {
+	collection := c.MongoDB.GetCollection("revoked_token")
+	collection.Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, token *RevokedToken) error {
+	if _, err := r.collection.InsertOne(ctx, token); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *repository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	err := r.collection.FindOne(ctx, bson.M{"_id": tokenID}).Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
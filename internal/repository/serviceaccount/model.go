@@ -0,0 +1,28 @@
+package serviceaccount
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServiceAccount is a principal for machines. It cannot log in, it only
+// authenticates with its API keys, and it holds roles like a user.
+type ServiceAccount struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description,omitempty"`
+	Roles       []string           `bson:"roles,omitempty"`
+	CreatedBy   string             `bson:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at"`
+}
+
+func NewServiceAccount(name, description string, roles []string, createdBy string) *ServiceAccount {
+	return &ServiceAccount{
+		Name:        name,
+		Description: description,
+		Roles:       roles,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
@@ -0,0 +1,99 @@
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrServiceAccountExists   = errors.New("service account already exists")
+)
+
+type ServiceAccountRepository interface {
+	InsertOne(ctx context.Context, sa *ServiceAccount) error
+	FindByID(ctx context.Context, id string) (*ServiceAccount, error)
+	Find(ctx context.Context) ([]*ServiceAccount, error)
+	DeleteByID(ctx context.Context, id string) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideServiceAccountRepository(c *client.Clients) ServiceAccountRepository {
+	collection := c.MongoDB.GetCollection("service_account")
+	collection.Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, sa *ServiceAccount) error {
+	if _, err := r.collection.InsertOne(ctx, sa); err != nil {
+		if mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "name") {
+			return ErrServiceAccountExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id string) (sa *ServiceAccount, err error) {
+	objid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrServiceAccountNotFound
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objid}).Decode(&sa)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	return sa, nil
+}
+
+func (r *repository) Find(ctx context.Context) (accounts []*ServiceAccount, err error) {
+	cur, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	if err = cur.All(ctx, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *repository) DeleteByID(ctx context.Context, id string) error {
+	objid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrServiceAccountNotFound
+	}
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": objid})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrServiceAccountNotFound
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package serviceaccount
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideServiceAccountRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideServiceAccountRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	sa := NewServiceAccount("batch", "", []string{"support"}, "admin")
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), sa)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("duplicate name", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error collection: test.service_account index: name_1",
+		}))
+
+		err := repo.InsertOne(context.Background(), sa)
+
+		assert.ErrorIs(t, err, ErrServiceAccountExists)
+	})
+}
+
+func TestFindByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.service_account", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "batch"}}))
+
+		sa, err := repo.FindByID(context.Background(), id.Hex())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "batch", sa.Name)
+	})
+
+	mt.Run("service account not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.service_account", mtest.FirstBatch))
+
+		sa, err := repo.FindByID(context.Background(), id.Hex())
+
+		assert.Nil(t, sa)
+		assert.ErrorIs(t, err, ErrServiceAccountNotFound)
+	})
+}
+
+func TestFind(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		first := mtest.CreateCursorResponse(1, "test.service_account", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "batch"}})
+		killCursors := mtest.CreateCursorResponse(0, "test.service_account", mtest.NextBatch)
+		mt.AddMockResponses(first, killCursors)
+
+		accounts, err := repo.Find(context.Background())
+
+		assert.Nil(t, err)
+		assert.Len(t, accounts, 1)
+	})
+}
+
+func TestDeleteByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
+
+		err := repo.DeleteByID(context.Background(), id.Hex())
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("service account not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+
+		err := repo.DeleteByID(context.Background(), id.Hex())
+
+		assert.ErrorIs(t, err, ErrServiceAccountNotFound)
+	})
+}
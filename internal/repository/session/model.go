@@ -0,0 +1,57 @@
+package session
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxDeviceIDLength  = 128
+	maxUserAgentLength = 512
+)
+
+// Session is created on every login and lives as long as the refresh tokens
+// issued from it, the hex of its id is their family id. The device id, the
+// user agent and the IP are reported by the client and only help the user
+// tell their sessions apart.
+type Session struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	UserID       string             `bson:"user_id"`
+	DeviceID     string             `bson:"device_id,omitempty"`
+	UserAgent    string             `bson:"user_agent,omitempty"`
+	IP           string             `bson:"ip,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	LastActiveAt time.Time          `bson:"last_active_at"`
+	ExpiresAt    time.Time          `bson:"expires_at"`
+	RevokedAt    *time.Time         `bson:"revoked_at,omitempty"`
+}
+
+func NewSession(userID, deviceID, userAgent, ip string, ttl time.Duration) *Session {
+	now := time.Now().UTC()
+	return &Session{
+		ID:           primitive.NewObjectID(),
+		UserID:       userID,
+		DeviceID:     truncate(deviceID, maxDeviceIDLength),
+		UserAgent:    truncate(userAgent, maxUserAgentLength),
+		IP:           ip,
+		CreatedAt:    now,
+		LastActiveAt: now,
+		ExpiresAt:    now.Add(ttl),
+	}
+}
+
+func (s *Session) IsExpired() bool {
+	return time.Now().UTC().After(s.ExpiresAt)
+}
+
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+func truncate(v string, n int) string {
+	if len(v) <= n {
+		return v
+	}
+	return v[:n]
+}
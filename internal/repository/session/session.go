@@ -0,0 +1,133 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type SessionRepository interface {
+	InsertOne(ctx context.Context, s *Session) error
+	FindByID(ctx context.Context, id string) (*Session, error)
+	FindActiveByUserID(ctx context.Context, userID string) ([]*Session, error)
+	Extend(ctx context.Context, id primitive.ObjectID, ip string, expiresAt time.Time) error
+	TouchLastActive(ctx context.Context, id primitive.ObjectID, interval time.Duration) error
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+	RevokeByUserID(ctx context.Context, userID string) error
+}
+
+type repository struct {
+	collection *mongo.Collection
+}
+
+func ProvideSessionRepository(c *client.Clients) SessionRepository {
+	collection := c.MongoDB.GetCollection("session")
+	collection.Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_active_at", Value: -1}},
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+		},
+	)
+
+	return &repository{
+		collection: collection,
+	}
+}
+
+func (r *repository) InsertOne(ctx context.Context, s *Session) error {
+	_, err := r.collection.InsertOne(ctx, s)
+	return err
+}
+
+func (r *repository) FindByID(ctx context.Context, id string) (s *Session, err error) {
+	objid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objid}).Decode(&s)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// FindActiveByUserID returns the sessions of the user that are neither
+// revoked nor expired, the most recently active first.
+func (r *repository) FindActiveByUserID(ctx context.Context, userID string) (sessions []*Session, err error) {
+	cur, err := r.collection.Find(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil, "expires_at": bson.M{"$gt": time.Now().UTC()}},
+		options.Find().SetSort(bson.D{{Key: "last_active_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	if err = cur.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Extend moves the expiry of the session along with its refresh token and
+// records the IP it was refreshed from.
+func (r *repository) Extend(ctx context.Context, id primitive.ObjectID, ip string, expiresAt time.Time) error {
+	set := bson.M{"last_active_at": time.Now().UTC(), "expires_at": expiresAt}
+	if ip != "" {
+		set["ip"] = ip
+	}
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": nil},
+		bson.M{"$set": set},
+	)
+	return err
+}
+
+// TouchLastActive records that an access token of the session was used. A
+// busy session is written at most once per interval, the filter skips the
+// update when last_active_at is recent.
+func (r *repository) TouchLastActive(ctx context.Context, id primitive.ObjectID, interval time.Duration) error {
+	now := time.Now().UTC()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "last_active_at": bson.M{"$lte": now.Add(-interval)}},
+		bson.M{"$set": bson.M{"last_active_at": now}},
+	)
+	return err
+}
+
+func (r *repository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
+
+// RevokeByUserID ends every session of the user, e.g. after a password
+// reset.
+func (r *repository) RevokeByUserID(ctx context.Context, userID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return err
+}
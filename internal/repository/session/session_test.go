@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type mockMongoDB struct {
+	mt *mtest.T
+}
+
+func (m *mockMongoDB) GetCollection(name string) *mongo.Collection {
+	return m.mt.Coll
+}
+
+func TestProvideSessionRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := ProvideSessionRepository(&client.Clients{
+			MongoDB: &mockMongoDB{mt: mt},
+		})
+		assert.NotNil(t, repo)
+	})
+}
+
+func TestNewSession(t *testing.T) {
+	s := NewSession("uid", strings.Repeat("d", 200), "Mozilla/5.0", "10.0.0.1", time.Hour)
+
+	assert.False(t, s.ID.IsZero())
+	assert.Equal(t, "uid", s.UserID)
+	assert.Len(t, s.DeviceID, maxDeviceIDLength)
+	assert.Equal(t, "Mozilla/5.0", s.UserAgent)
+	assert.Equal(t, s.CreatedAt, s.LastActiveAt)
+	assert.False(t, s.IsExpired())
+	assert.False(t, s.IsRevoked())
+}
+
+func TestInsertOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	s := NewSession("uid", "device", "", "", time.Hour)
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.InsertOne(context.Background(), s)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "some other write error"
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    1,
+			Message: msg,
+		}))
+
+		err := repo.InsertOne(context.Background(), s)
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestFindByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.session", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: "uid"}, {Key: "device_id", Value: "device"}}))
+
+		s, err := repo.FindByID(context.Background(), id.Hex())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "uid", s.UserID)
+		assert.Equal(t, "device", s.DeviceID)
+	})
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		s, err := repo.FindByID(context.Background(), "invalid")
+
+		assert.Nil(t, s)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	mt.Run("session not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.session", mtest.FirstBatch))
+
+		s, err := repo.FindByID(context.Background(), id.Hex())
+
+		assert.Nil(t, s)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestFindActiveByUserID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		first := mtest.CreateCursorResponse(1, "test.session", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "user_id", Value: "uid"}})
+		second := mtest.CreateCursorResponse(1, "test.session", mtest.NextBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "user_id", Value: "uid"}})
+		killCursors := mtest.CreateCursorResponse(0, "test.session", mtest.NextBatch)
+		mt.AddMockResponses(first, second, killCursors)
+
+		sessions, err := repo.FindActiveByUserID(context.Background(), "uid")
+
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 2)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		sessions, err := repo.FindActiveByUserID(context.Background(), "uid")
+
+		assert.Nil(t, sessions)
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestExtend(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Extend(context.Background(), primitive.NewObjectID(), "10.0.0.1", time.Now().Add(time.Hour))
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestTouchLastActive(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.TouchLastActive(context.Background(), primitive.NewObjectID(), time.Minute)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("recently active", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.TouchLastActive(context.Background(), primitive.NewObjectID(), time.Minute)
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestRevoke(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Revoke(context.Background(), primitive.NewObjectID())
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		err := repo.Revoke(context.Background(), primitive.NewObjectID())
+
+		assert.Error(t, err, msg)
+	})
+}
+
+func TestRevokeByUserID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+
+		err := repo.RevokeByUserID(context.Background(), "uid")
+
+		assert.Nil(t, err)
+	})
+}
@@ -1,34 +1,123 @@
 package user
 
 import (
+	"crypto/rand"
+	"encoding/base32"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/totp"
 	"github.com/nuea/backend-golang-test/internal/types"
+	"github.com/nuea/backend-golang-test/internal/util"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Name      string             `bson:"name"`
-	Email     types.Email        `bson:"email"`
-	Password  string             `bson:"password"`
-	CreatedBy *string            `bson:"created_by,omitempty"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
-	DeletedAt *time.Time         `bson:"deleted_at,omitempty"`
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	Name            string             `bson:"name"`
+	Email           types.Email        `bson:"email"`
+	Password        string             `bson:"password"`
+	PasswordHistory []string           `bson:"password_history,omitempty"`
+	Roles           []string           `bson:"roles,omitempty"`
+	EmailVerifiedAt *time.Time         `bson:"email_verified_at,omitempty"`
+	MFA             *MFA               `bson:"mfa,omitempty"`
+	Identities      []Identity         `bson:"identities,omitempty"`
+	CreatedBy       *string            `bson:"created_by,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at"`
+	DeletedAt       *time.Time         `bson:"deleted_at,omitempty"`
+	// Version is incremented by every ReplaceOne. Users stored before it
+	// was introduced are read as version 0.
+	Version int64 `bson:"version"`
 }
 
 func NewUser() *User {
 	return &User{
+		Version:   1,
 		CreatedBy: nil,
+		Roles:     []string{string(rbac.RoleUser)},
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
 }
 
+func (u *User) MFAEnabled() bool {
+	return u.MFA != nil && u.MFA.EnabledAt != nil
+}
+
+// MFA is the TOTP enrollment of a user. It stays pending until the first
+// code is confirmed and EnabledAt is set.
+type MFA struct {
+	Secret        string     `bson:"secret"`
+	EnabledAt     *time.Time `bson:"enabled_at,omitempty"`
+	LastUsedStep  int64      `bson:"last_used_step,omitempty"`
+	RecoveryCodes []string   `bson:"recovery_codes,omitempty"`
+}
+
+// Verify accepts a TOTP code or one of the recovery codes. Either is
+// consumed on success, so the caller must persist m afterwards.
+func (m *MFA) Verify(code string, now time.Time) bool {
+	if step, ok := totp.Validate(m.Secret, code, now); ok && step > m.LastUsedStep {
+		m.LastUsedStep = step
+		return true
+	}
+
+	hash := util.HashToken(normalizeRecoveryCode(code))
+	if i := slices.Index(m.RecoveryCodes, hash); i >= 0 {
+		m.RecoveryCodes = slices.Delete(m.RecoveryCodes, i, i+1)
+		return true
+	}
+	return false
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewRecoveryCodes returns n recovery codes to show to the user once, and the
+// hashes to store.
+func NewRecoveryCodes(n int) (codes []string, hashes []string, err error) {
+	for range n {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, util.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// Identity links the user to an account at an external OpenID Connect
+// issuer. Users created by an external login have no password.
+type Identity struct {
+	Issuer   string    `bson:"issuer"`
+	Subject  string    `bson:"subject"`
+	LinkedAt time.Time `bson:"linked_at"`
+}
+
+// UserFilter matches the users whose fields equal the ones set in User, and
+// every search condition that is set. Ranges include After and exclude
+// Before.
 type UserFilter struct {
 	User
+	NamePrefix    string
+	NameContains  string
+	EmailPrefix   string
+	EmailContains string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	// Query is searched in the text index on name and email.
+	Query string
 }
 
 func (f *UserFilter) Filter() bson.D {
@@ -44,5 +133,109 @@ func (f *UserFilter) Filter() bson.D {
 	if f.Email != "" {
 		filter = append(filter, bson.E{Key: "email", Value: f.Email})
 	}
+	if f.CreatedBy != nil {
+		filter = append(filter, bson.E{Key: "created_by", Value: *f.CreatedBy})
+	}
+	if r := timeRange(f.CreatedAfter, f.CreatedBefore); r != nil {
+		filter = append(filter, bson.E{Key: "created_at", Value: r})
+	}
+	if r := timeRange(f.UpdatedAfter, f.UpdatedBefore); r != nil {
+		filter = append(filter, bson.E{Key: "updated_at", Value: r})
+	}
+	if f.Query != "" {
+		filter = append(filter, bson.E{Key: "$text", Value: bson.M{"$search": f.Query}})
+	}
+
+	// Conditions on the same field cannot share one key of the filter.
+	var patterns bson.A
+	for _, p := range []struct{ field, prefix, contains string }{
+		{"name", f.NamePrefix, f.NameContains},
+		{"email", f.EmailPrefix, f.EmailContains},
+	} {
+		// An anchored, case sensitive regex is answered from the index.
+		if p.prefix != "" {
+			patterns = append(patterns, bson.M{p.field: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(p.prefix)}})
+		}
+		if p.contains != "" {
+			patterns = append(patterns, bson.M{p.field: primitive.Regex{Pattern: regexp.QuoteMeta(p.contains), Options: "i"}})
+		}
+	}
+	if len(patterns) > 0 {
+		filter = append(filter, bson.E{Key: "$and", Value: patterns})
+	}
 	return filter
 }
+
+func timeRange(after, before *time.Time) bson.M {
+	if after == nil && before == nil {
+		return nil
+	}
+
+	r := bson.M{}
+	if after != nil {
+		r["$gte"] = *after
+	}
+	if before != nil {
+		r["$lt"] = *before
+	}
+	return r
+}
+
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByID        SortField = "_id"
+)
+
+// Page selects the users after a cursor in the order of SortBy. Users with
+// the same created_at are ordered by _id, so every user has exactly one
+// position and a cursor never skips or repeats a user.
+type Page struct {
+	Size   int64
+	SortBy SortField
+	Desc   bool
+	After  *Cursor
+}
+
+// Cursor is the position of the last user of a page.
+type Cursor struct {
+	ID        primitive.ObjectID
+	CreatedAt time.Time
+}
+
+// CursorOf returns the cursor of the page that ends with u.
+func CursorOf(u *User) *Cursor {
+	return &Cursor{ID: u.ID, CreatedAt: u.CreatedAt}
+}
+
+func (p *Page) Sort() bson.D {
+	dir := 1
+	if p.Desc {
+		dir = -1
+	}
+	if p.SortBy == SortByCreatedAt {
+		return bson.D{{Key: "created_at", Value: dir}, {Key: "_id", Value: dir}}
+	}
+	return bson.D{{Key: "_id", Value: dir}}
+}
+
+// Filter returns the condition that only matches users after the cursor, or
+// nil on the first page.
+func (p *Page) Filter() bson.D {
+	if p.After == nil {
+		return nil
+	}
+
+	op := "$gt"
+	if p.Desc {
+		op = "$lt"
+	}
+	if p.SortBy == SortByCreatedAt {
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.M{"created_at": bson.M{op: p.After.CreatedAt}},
+			bson.M{"created_at": p.After.CreatedAt, "_id": bson.M{op: p.After.ID}},
+		}}}
+	}
+	return bson.D{{Key: "_id", Value: bson.M{op: p.After.ID}}}
+}
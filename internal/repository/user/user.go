@@ -13,13 +13,20 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrVersionConflict = errors.New("user was modified by another request")
+)
+
 type UserRepository interface {
 	InsertOne(ctx context.Context, user *User) error
 	FindByID(ctx context.Context, id string) (user *User, err error)
 	FindByEmail(ctx context.Context, email types.Email) (user *User, err error)
-	Find(ctx context.Context, filter *UserFilter) (users []*User, err error)
+	FindByIdentity(ctx context.Context, issuer, subject string) (user *User, err error)
+	Find(ctx context.Context, filter *UserFilter, page *Page) (users []*User, err error)
 	ReplaceOne(ctx context.Context, id string, user *User) error
 	Count(ctx context.Context) (int64, error)
+	CountByFilter(ctx context.Context, filter *UserFilter) (int64, error)
 }
 
 type repository struct {
@@ -28,11 +35,33 @@ type repository struct {
 
 func ProvideUserRepository(c *client.Clients) UserRepository {
 	collection := c.MongoDB.GetCollection("user")
-	collection.Indexes().CreateOne(
+	collection.Indexes().CreateMany(
 		context.Background(),
-		mongo.IndexModel{
-			Keys:    bson.D{{Key: "email", Value: 1}},
-			Options: options.Index().SetUnique(true),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "identities.issuer", Value: 1}, {Key: "identities.subject", Value: 1}},
+				Options: options.Index().SetUnique(true).
+					SetPartialFilterExpression(bson.M{"identities": bson.M{"$exists": true}}),
+			},
+			{
+				Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "name", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "updated_at", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "created_by", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "name", Value: "text"}, {Key: "email", Value: "text"}},
+			},
 		},
 	)
 
@@ -60,7 +89,7 @@ func (r *repository) FindByID(ctx context.Context, id string) (user *User, err e
 	err = r.collection.FindOne(ctx, bson.M{"_id": objid}).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -71,15 +100,33 @@ func (r *repository) FindByEmail(ctx context.Context, email types.Email) (user *
 	err = r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, err
+}
+
+func (r *repository) FindByIdentity(ctx context.Context, issuer, subject string) (user *User, err error) {
+	err = r.collection.FindOne(ctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"issuer": issuer, "subject": subject}},
+	}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 	return user, err
 }
 
-func (r *repository) Find(ctx context.Context, filter *UserFilter) (users []*User, err error) {
-	cur, err := r.collection.Find(ctx, filter.Filter())
+// Find returns at most page.Size users matching the filter, starting after
+// page.After.
+func (r *repository) Find(ctx context.Context, filter *UserFilter, page *Page) (users []*User, err error) {
+	cur, err := r.collection.Find(ctx,
+		append(filter.Filter(), page.Filter()...),
+		options.Find().SetSort(page.Sort()).SetLimit(page.Size),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -92,18 +139,39 @@ func (r *repository) Find(ctx context.Context, filter *UserFilter) (users []*Use
 	return users, nil
 }
 
+// ReplaceOne stores the user only if it is still at the version it was read
+// with, and increments user.Version on success. Otherwise nothing is written
+// and ErrVersionConflict is returned.
 func (r *repository) ReplaceOne(ctx context.Context, id string, user *User) error {
 	objid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	if _, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objid}, user); err != nil {
+	expected := user.Version
+	user.Version++
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": objid, "version": versionFilter(expected)}, user)
+	if err == nil && res.MatchedCount == 0 {
+		err = ErrVersionConflict
+	}
+	if err != nil {
+		user.Version = expected
 		return err
 	}
 	return nil
 }
 
+func versionFilter(version int64) any {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
 func (r *repository) Count(ctx context.Context) (int64, error) {
 	return r.collection.CountDocuments(ctx, bson.M{"deleted_at": nil})
 }
+
+func (r *repository) CountByFilter(ctx context.Context, filter *UserFilter) (int64, error) {
+	return r.collection.CountDocuments(ctx, filter.Filter())
+}
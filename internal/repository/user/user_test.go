@@ -4,8 +4,11 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gotidy/ptr"
 	"github.com/nuea/backend-golang-test/internal/client"
+	"github.com/nuea/backend-golang-test/internal/totp"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -199,6 +202,54 @@ func TestFindByEmail(t *testing.T) {
 	})
 }
 
+func TestFindByIdentity(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	linkedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.user", mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: id},
+				{Key: "email", Value: "test@example.com"},
+				{Key: "identities", Value: bson.A{
+					bson.D{{Key: "issuer", Value: "https://idp.example.com"}, {Key: "subject", Value: "sub"}, {Key: "linked_at", Value: linkedAt}},
+				}},
+			}))
+
+		res, err := repo.FindByIdentity(context.Background(), "https://idp.example.com", "sub")
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, res.ID)
+		assert.Equal(t, []Identity{{Issuer: "https://idp.example.com", Subject: "sub", LinkedAt: linkedAt}}, res.Identities)
+	})
+
+	mt.Run("user not found", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.user", mtest.FirstBatch))
+
+		user, err := repo.FindByIdentity(context.Background(), "https://idp.example.com", "sub")
+
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	mt.Run("other error", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		msg := "internal server error"
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
+
+		user, err := repo.FindByIdentity(context.Background(), "https://idp.example.com", "sub")
+
+		assert.Nil(t, user)
+		assert.Error(t, err, msg)
+	})
+}
+
 func TestFind(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	expuser := []*User{
@@ -230,7 +281,7 @@ func TestFind(t *testing.T) {
 
 		mt.AddMockResponses(curone, curtwo, endcur)
 
-		users, err := repo.Find(context.Background(), f)
+		users, err := repo.Find(context.Background(), f, &Page{Size: 10, SortBy: SortByCreatedAt})
 
 		assert.Nil(t, err)
 		assert.Equal(t, len(expuser), len(users))
@@ -244,7 +295,7 @@ func TestFind(t *testing.T) {
 
 		mt.AddMockResponses(curone, curtwo, endcur)
 
-		users, err := repo.Find(context.Background(), f)
+		users, err := repo.Find(context.Background(), f, &Page{Size: 10, SortBy: SortByCreatedAt})
 
 		assert.Nil(t, err)
 		assert.Equal(t, len(expuser), len(users))
@@ -257,7 +308,7 @@ func TestFind(t *testing.T) {
 
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg}))
 
-		user, err := repo.Find(context.Background(), &UserFilter{})
+		user, err := repo.Find(context.Background(), &UserFilter{}, &Page{Size: 10})
 
 		assert.Nil(t, user)
 		assert.NotNil(t, err)
@@ -272,7 +323,7 @@ func TestFind(t *testing.T) {
 		curerr := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: msg})
 		mt.AddMockResponses(curone, curerr)
 
-		users, err := repo.Find(context.Background(), filter)
+		users, err := repo.Find(context.Background(), filter, &Page{Size: 10})
 
 		assert.Nil(t, users)
 		assert.NotNil(t, err)
@@ -293,16 +344,22 @@ func TestReplaceOne(t *testing.T) {
 	mt.Run("success", func(mt *mtest.T) {
 		repo := &repository{collection: mt.Coll}
 
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.user", mtest.FirstBatch,
-			bson.D{
-				{Key: "_id", Value: user.ID},
-				{Key: "name", Value: user.Name},
-				{Key: "email", Value: user.Email},
-				{Key: "password", Value: user.Password},
-			}))
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
 		err := repo.ReplaceOne(context.Background(), user.ID.Hex(), user)
 
 		assert.Nil(t, err)
+		assert.Equal(t, int64(1), user.Version)
+	})
+
+	mt.Run("version conflict", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		u := &User{ID: primitive.NewObjectID(), Version: 3}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		err := repo.ReplaceOne(context.Background(), u.ID.Hex(), u)
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Equal(t, int64(3), u.Version)
 	})
 
 	mt.Run("invalid id", func(mt *mtest.T) {
@@ -359,3 +416,114 @@ func TestCount(t *testing.T) {
 	})
 
 }
+
+func TestCountByFilter(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &repository{collection: mt.Coll}
+		f := &UserFilter{}
+		f.Name = "test"
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.user", mtest.FirstBatch, bson.D{{Key: "n", Value: 3}}))
+
+		count, err := repo.CountByFilter(context.Background(), f)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+}
+
+func TestUserFilter(t *testing.T) {
+	t.Run("exact", func(t *testing.T) {
+		f := &UserFilter{}
+		f.Name = "test"
+
+		assert.Equal(t, bson.D{{Key: "deleted_at", Value: nil}, {Key: "name", Value: "test"}}, f.Filter())
+	})
+
+	t.Run("search", func(t *testing.T) {
+		after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		before := after.AddDate(0, 1, 0)
+		f := &UserFilter{
+			NamePrefix:    "Jo",
+			EmailContains: "a.b",
+			CreatedAfter:  &after,
+			CreatedBefore: &before,
+			UpdatedAfter:  &after,
+			Query:         "john",
+		}
+		f.CreatedBy = ptr.String("admin")
+
+		assert.Equal(t, bson.D{
+			{Key: "deleted_at", Value: nil},
+			{Key: "created_by", Value: "admin"},
+			{Key: "created_at", Value: bson.M{"$gte": after, "$lt": before}},
+			{Key: "updated_at", Value: bson.M{"$gte": after}},
+			{Key: "$text", Value: bson.M{"$search": "john"}},
+			{Key: "$and", Value: bson.A{
+				bson.M{"name": primitive.Regex{Pattern: "^Jo"}},
+				bson.M{"email": primitive.Regex{Pattern: `a\.b`, Options: "i"}},
+			}},
+		}, f.Filter())
+	})
+}
+
+func TestPage(t *testing.T) {
+	cursor := &Cursor{ID: primitive.NewObjectID(), CreatedAt: time.Now()}
+
+	t.Run("first page", func(t *testing.T) {
+		page := &Page{SortBy: SortByCreatedAt}
+
+		assert.Equal(t, bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}, page.Sort())
+		assert.Nil(t, page.Filter())
+	})
+
+	t.Run("after cursor by created_at descending", func(t *testing.T) {
+		page := &Page{SortBy: SortByCreatedAt, Desc: true, After: cursor}
+
+		assert.Equal(t, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, page.Sort())
+		assert.Equal(t, bson.D{{Key: "$or", Value: bson.A{
+			bson.M{"created_at": bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{"created_at": cursor.CreatedAt, "_id": bson.M{"$lt": cursor.ID}},
+		}}}, page.Filter())
+	})
+
+	t.Run("after cursor by id", func(t *testing.T) {
+		page := &Page{SortBy: SortByID, After: cursor}
+
+		assert.Equal(t, bson.D{{Key: "_id", Value: 1}}, page.Sort())
+		assert.Equal(t, bson.D{{Key: "_id", Value: bson.M{"$gt": cursor.ID}}}, page.Filter())
+	})
+}
+
+func TestMFAVerify(t *testing.T) {
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+
+	t.Run("totp code", func(t *testing.T) {
+		mfa := &MFA{Secret: secret}
+		code, _ := totp.Code(secret, totp.Step(now))
+
+		assert.True(t, mfa.Verify(code, now))
+		assert.Equal(t, totp.Step(now), mfa.LastUsedStep)
+		assert.False(t, mfa.Verify(code, now), "a code is accepted only once")
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		codes, hashes, err := NewRecoveryCodes(3)
+		assert.NoError(t, err)
+		assert.Len(t, codes, 3)
+		mfa := &MFA{Secret: secret, RecoveryCodes: hashes}
+
+		assert.True(t, mfa.Verify(strings.ToUpper(codes[1]), now))
+		assert.Len(t, mfa.RecoveryCodes, 2)
+		assert.False(t, mfa.Verify(codes[1], now), "a recovery code is accepted only once")
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		mfa := &MFA{Secret: secret}
+
+		assert.False(t, mfa.Verify("000000x", now))
+	})
+}
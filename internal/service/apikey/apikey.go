@@ -0,0 +1,103 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ErrInvalidAPIKey = status.Error(codes.Unauthenticated, "API key is invalid.")
+
+// APIKeyService authenticates the callers that present an API key instead
+// of an access token.
+type APIKeyService interface {
+	// Authenticate returns the claims of the key owner, restricted to the
+	// scopes of the key, and records that the key was used.
+	Authenticate(ctx context.Context, key string) (*token.JwtToken, error)
+}
+
+type apiKeyService struct {
+	cfg      *config.AuthConfig
+	keyrepo  apikey.APIKeyRepository
+	userrepo user.UserRepository
+	sarepo   serviceaccount.ServiceAccountRepository
+}
+
+func ProvideAPIKeyService(cfg *config.AppConfig, repo *repository.Repository) APIKeyService {
+	return &apiKeyService{
+		cfg:      &cfg.Auth,
+		keyrepo:  repo.APIKeyRepository,
+		userrepo: repo.UserRepository,
+		sarepo:   repo.ServiceAccountRepository,
+	}
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (*token.JwtToken, error) {
+	if !strings.HasPrefix(key, apikey.KeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	k, err := s.keyrepo.FindByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if k.IsRevoked() {
+		return nil, status.Error(codes.Unauthenticated, "API key has been revoked.")
+	}
+	if k.IsExpired() {
+		return nil, status.Error(codes.Unauthenticated, "API key has expired.")
+	}
+
+	claims := &token.JwtToken{
+		APIKeyID: k.ID.Hex(),
+		Scopes:   k.Scopes,
+	}
+
+	// The roles are read on every call, so that revoking a role or deleting
+	// the owner takes effect on its keys at once.
+	switch k.OwnerType {
+	case apikey.OwnerTypeUser:
+		u, err := s.userrepo.FindByID(ctx, k.OwnerID)
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				return nil, ErrInvalidAPIKey
+			}
+			return nil, err
+		}
+		if u.DeletedAt != nil {
+			return nil, ErrInvalidAPIKey
+		}
+		claims.UserID = k.OwnerID
+		claims.Roles = u.Roles
+	case apikey.OwnerTypeServiceAccount:
+		sa, err := s.sarepo.FindByID(ctx, k.OwnerID)
+		if err != nil {
+			if errors.Is(err, serviceaccount.ErrServiceAccountNotFound) {
+				return nil, ErrInvalidAPIKey
+			}
+			return nil, err
+		}
+		claims.ServiceAccountID = k.OwnerID
+		claims.Roles = sa.Roles
+	default:
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.keyrepo.TouchLastUsed(ctx, k.ID, s.cfg.APIKeyLastUsedInterval); err != nil {
+		log.Println("Unable to record API key usage:", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,200 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/repository"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/repository/serviceaccount"
+	"github.com/nuea/backend-golang-test/internal/repository/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockAPIKeyRepository struct {
+	mock.Mock
+	apikey.APIKeyRepository
+}
+
+func (m *mockAPIKeyRepository) FindByKey(ctx context.Context, key string) (*apikey.APIKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.APIKey), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) TouchLastUsed(ctx context.Context, id primitive.ObjectID, interval time.Duration) error {
+	args := m.Called(ctx, id, interval)
+	return args.Error(0)
+}
+
+type mockUserRepository struct {
+	mock.Mock
+	user.UserRepository
+}
+
+func (m *mockUserRepository) FindByID(ctx context.Context, id string) (*user.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+type mockServiceAccountRepository struct {
+	mock.Mock
+	serviceaccount.ServiceAccountRepository
+}
+
+func (m *mockServiceAccountRepository) FindByID(ctx context.Context, id string) (*serviceaccount.ServiceAccount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceaccount.ServiceAccount), args.Error(1)
+}
+
+func TestProvideAPIKeyService(t *testing.T) {
+	sv := ProvideAPIKeyService(&config.AppConfig{}, &repository.Repository{})
+	assert.NotNil(t, sv)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.AuthConfig{APIKeyLastUsedInterval: time.Minute}
+	uid := primitive.NewObjectID().Hex()
+	said := primitive.NewObjectID().Hex()
+
+	newService := func() (*apiKeyService, *mockAPIKeyRepository, *mockUserRepository, *mockServiceAccountRepository) {
+		keyrepo := new(mockAPIKeyRepository)
+		userrepo := new(mockUserRepository)
+		sarepo := new(mockServiceAccountRepository)
+		return &apiKeyService{cfg: cfg, keyrepo: keyrepo, userrepo: userrepo, sarepo: sarepo}, keyrepo, userrepo, sarepo
+	}
+	newKey := func(ownerType, ownerID string) *apikey.APIKey {
+		return &apikey.APIKey{ID: primitive.NewObjectID(), OwnerID: ownerID, OwnerType: ownerType, Scopes: []string{"users:read"}}
+	}
+
+	t.Run("user key", func(t *testing.T) {
+		sv, keyrepo, userrepo, _ := newService()
+		k := newKey(apikey.OwnerTypeUser, uid)
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+		userrepo.On("FindByID", ctx, uid).Return(&user.User{Roles: []string{"support"}}, nil).Once()
+		keyrepo.On("TouchLastUsed", ctx, k.ID, time.Minute).Return(nil).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uid, claims.UserID)
+		assert.Equal(t, []string{"support"}, claims.Roles)
+		assert.Equal(t, k.ID.Hex(), claims.APIKeyID)
+		assert.Equal(t, []string{"users:read"}, claims.Scopes)
+		keyrepo.AssertExpectations(t)
+	})
+
+	t.Run("service account key", func(t *testing.T) {
+		sv, keyrepo, _, sarepo := newService()
+		k := newKey(apikey.OwnerTypeServiceAccount, said)
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+		sarepo.On("FindByID", ctx, said).Return(&serviceaccount.ServiceAccount{Roles: []string{"admin"}}, nil).Once()
+		keyrepo.On("TouchLastUsed", ctx, k.ID, time.Minute).Return(nil).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.NoError(t, err)
+		assert.Empty(t, claims.UserID)
+		assert.Equal(t, said, claims.ServiceAccountID)
+		assert.Equal(t, []string{"admin"}, claims.Roles)
+	})
+
+	t.Run("touch fails", func(t *testing.T) {
+		sv, keyrepo, userrepo, _ := newService()
+		k := newKey(apikey.OwnerTypeUser, uid)
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+		userrepo.On("FindByID", ctx, uid).Return(&user.User{}, nil).Once()
+		keyrepo.On("TouchLastUsed", ctx, k.ID, time.Minute).Return(errors.New("write failed")).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.NoError(t, err)
+		assert.NotNil(t, claims)
+	})
+
+	t.Run("not our key", func(t *testing.T) {
+		sv, keyrepo, _, _ := newService()
+
+		claims, err := sv.Authenticate(ctx, "other")
+
+		assert.Nil(t, claims)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+		keyrepo.AssertNotCalled(t, "FindByKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		sv, keyrepo, _, _ := newService()
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(nil, apikey.ErrAPIKeyNotFound).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.Nil(t, claims)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		sv, keyrepo, _, _ := newService()
+		k := newKey(apikey.OwnerTypeUser, uid)
+		k.RevokedAt = ptr.Time(time.Now())
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.Nil(t, claims)
+		assert.Equal(t, status.Error(codes.Unauthenticated, "API key has been revoked."), err)
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		sv, keyrepo, _, _ := newService()
+		k := newKey(apikey.OwnerTypeUser, uid)
+		k.ExpiresAt = ptr.Time(time.Now().Add(-time.Minute))
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.Nil(t, claims)
+		assert.Equal(t, status.Error(codes.Unauthenticated, "API key has expired."), err)
+	})
+
+	t.Run("deleted owner", func(t *testing.T) {
+		sv, keyrepo, userrepo, _ := newService()
+		k := newKey(apikey.OwnerTypeUser, uid)
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+		userrepo.On("FindByID", ctx, uid).Return(&user.User{DeletedAt: ptr.Time(time.Now())}, nil).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.Nil(t, claims)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+		keyrepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted service account", func(t *testing.T) {
+		sv, keyrepo, _, sarepo := newService()
+		k := newKey(apikey.OwnerTypeServiceAccount, said)
+		keyrepo.On("FindByKey", ctx, "bgt_key").Return(k, nil).Once()
+		sarepo.On("FindByID", ctx, said).Return(nil, serviceaccount.ErrServiceAccountNotFound).Once()
+
+		claims, err := sv.Authenticate(ctx, "bgt_key")
+
+		assert.Nil(t, claims)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+}
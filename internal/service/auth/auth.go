@@ -2,92 +2,421 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 	"github.com/nuea/backend-golang-test/internal/client"
 	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/identity"
+	"github.com/nuea/backend-golang-test/internal/repository/apikey"
+	"github.com/nuea/backend-golang-test/internal/service/token"
+	"github.com/nuea/backend-golang-test/internal/util"
 	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// oidcCookiePath limits the OIDC state cookie to the login endpoints.
+const oidcCookiePath = "/api/v1/oidc"
+
 type AuthService interface {
-	Login(ctx context.Context, req *userv1.LoginRequest) (accessToken string, err error)
-	GenerateAccessToken(userID string) (string, error)
-	VerifyAccessToken(accessToken string) (*JwtToken, error)
+	token.TokenService
+	Login(ctx context.Context, req *userv1.LoginRequest) (*TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	// IsTokenRevoked reports whether the access token, or the session it was
+	// issued for, has been revoked.
+	IsTokenRevoked(ctx context.Context, tokenID, sessionID string) (bool, error)
+	ChangePassword(ctx context.Context, currentPassword, newPassword string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	VerifyMFA(ctx context.Context, mfaToken, code string) (*TokenPair, error)
+	EnrollMFA(ctx context.Context) (*userv1.EnrollMFAResponse, error)
+	ConfirmMFA(ctx context.Context, code string) ([]string, error)
+	DisableMFA(ctx context.Context, password, code string) error
+	// VerifyAPIKey returns the claims of the key owner, restricted to the
+	// scopes of the key.
+	VerifyAPIKey(ctx context.Context, key string) (*token.JwtToken, error)
+	// BeginOIDCLogin returns the URL of the identity provider to redirect
+	// the browser to.
+	BeginOIDCLogin(ctx context.Context) (string, error)
+	CompleteOIDCLogin(ctx context.Context, state, code string) (*TokenPair, error)
+	// ClientCredentials authenticates an OAuth client and issues an access
+	// token for the requested scopes, or for all of its scopes when none
+	// are requested.
+	ClientCredentials(ctx context.Context, clientID, clientSecret string, scopes []string) (*ClientToken, error)
+	// ListSessions, RevokeSession and RevokeAllSessions act on the sessions
+	// of the caller when userID is empty.
+	ListSessions(ctx context.Context, userID string) ([]*userv1.Session, error)
+	RevokeSession(ctx context.Context, id string) error
+	RevokeAllSessions(ctx context.Context, userID string) error
+	IntrospectToken(ctx context.Context, accessToken string) (*userv1.IntrospectTokenResponse, error)
+	// ImpersonateUser issues a short-lived access token to act as the user,
+	// without a refresh token or cookies.
+	ImpersonateUser(ctx context.Context, userID, reason string) (*userv1.ImpersonateUserResponse, error)
 }
 
 type authService struct {
-	cfg        *config.AuthConfig
-	authclient userv1.AuthServiceClient
+	token.TokenService
+	cfg          *config.AuthConfig
+	oidcCfg      *config.OIDCConfig
+	sameSite     http.SameSite
+	authclient   userv1.AuthServiceClient
+	apikeyclient userv1.APIKeyServiceClient
 }
 
-func ProvideAuthenticationService(cfg *config.AppConfig, c *client.GRPCClients) AuthService {
-	return &authService{
-		cfg:        &cfg.Auth,
-		authclient: c.BackendGolangTestGRPCService.AuthServiceClient,
+func ProvideAuthenticationService(cfg *config.AppConfig, c *client.GRPCClients, tokensv token.TokenService) (AuthService, error) {
+	sameSite, err := parseSameSite(cfg.Auth.CookieSameSite)
+	if err != nil {
+		return nil, err
 	}
+	if sameSite == http.SameSiteNoneMode && !cfg.Auth.CookieSecure {
+		return nil, errors.New("auth: SameSite=None cookies must be Secure")
+	}
+
+	return &authService{
+		TokenService: tokensv,
+		cfg:          &cfg.Auth,
+		oidcCfg:      &cfg.OIDC,
+		sameSite:     sameSite,
+		authclient:   c.BackendGolangTestGRPCService.AuthServiceClient,
+		apikeyclient: c.BackendGolangTestGRPCService.APIKeyServiceClient,
+	}, nil
 }
 
-type JwtToken struct {
-	jwt.StandardClaims
-	UserID string `json:"uid,omitempty"`
+// TokenPair is returned by a successful login. When MFA is enabled, Login
+// returns only MFARequired and MFAToken, to be exchanged with VerifyMFA.
+// CSRFToken is also set as a cookie, a browser sends it back in the CSRF
+// header.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	CSRFToken    string
+	SessionID    string
+	MFARequired  bool
+	MFAToken     string
 }
 
-func (s *authService) Login(ctx context.Context, req *userv1.LoginRequest) (accessToken string, err error) {
+// ClientToken is returned by the client_credentials grant. There is no
+// refresh token, the client authenticates again when it expires.
+type ClientToken struct {
+	AccessToken string
+	ExpiresIn   time.Duration
+	Scopes      []string
+}
+
+func (s *authService) Login(ctx context.Context, req *userv1.LoginRequest) (*TokenPair, error) {
 	res, err := s.authclient.Login(ctx, req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	accessToken, err = s.GenerateAccessToken(res.UserId)
+	if res.MfaRequired {
+		return &TokenPair{
+			MFARequired: true,
+			MFAToken:    res.MfaToken,
+		}, nil
+	}
+
+	return s.newTokenPair(ctx, res.UserId, res.SessionId, res.Roles, res.RefreshToken)
+}
+
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	res, err := s.authclient.RefreshToken(ctx, &userv1.RefreshTokenRequest{
+		RefreshToken: refreshToken,
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if accessToken != "" {
-		s.setCookies(ctx, accessToken)
+	return s.newTokenPair(ctx, res.UserId, res.SessionId, res.Roles, res.RefreshToken)
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	if _, err := s.authclient.Logout(ctx, &userv1.LogoutRequest{
+		AccessToken:  identity.AccessTokenFromContext(ctx),
+		RefreshToken: refreshToken,
+	}); err != nil {
+		return err
+	}
+
+	s.clearCookies(ctx)
+	return nil
+}
+
+func (s *authService) IsTokenRevoked(ctx context.Context, tokenID, sessionID string) (bool, error) {
+	res, err := s.authclient.IsTokenRevoked(ctx, &userv1.IsTokenRevokedRequest{
+		TokenId:   tokenID,
+		SessionId: sessionID,
+	})
+	if err != nil {
+		return false, err
 	}
+	return res.Revoked, nil
+}
+
+func (s *authService) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	_, err := s.authclient.ChangePassword(ctx, &userv1.ChangePasswordRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	})
+	return err
+}
 
-	return accessToken, nil
+func (s *authService) ForgotPassword(ctx context.Context, email string) error {
+	_, err := s.authclient.ForgotPassword(ctx, &userv1.ForgotPasswordRequest{
+		Email: email,
+	})
+	return err
 }
 
-func (s *authService) GenerateAccessToken(userID string) (string, error) {
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, &JwtToken{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Local().Add(s.cfg.AccessTokenExpireTTL).UnixMilli(),
-		},
-	}).SignedString([]byte(s.cfg.SecretKey))
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	_, err := s.authclient.ResetPassword(ctx, &userv1.ResetPasswordRequest{
+		Token:       token,
+		NewPassword: newPassword,
+	})
+	return err
 }
 
-func (s *authService) VerifyAccessToken(accessToken string) (*JwtToken, error) {
-	if accessToken == "" {
-		return nil, errors.New("Access token is empty.")
+func (s *authService) VerifyMFA(ctx context.Context, mfaToken, code string) (*TokenPair, error) {
+	res, err := s.authclient.VerifyMFA(ctx, &userv1.VerifyMFARequest{
+		MfaToken: mfaToken,
+		Code:     code,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newTokenPair(ctx, res.UserId, res.SessionId, res.Roles, res.RefreshToken)
+}
+
+func (s *authService) EnrollMFA(ctx context.Context) (*userv1.EnrollMFAResponse, error) {
+	return s.authclient.EnrollMFA(ctx, &userv1.EnrollMFARequest{})
+}
+
+func (s *authService) ConfirmMFA(ctx context.Context, code string) ([]string, error) {
+	res, err := s.authclient.ConfirmMFA(ctx, &userv1.ConfirmMFARequest{
+		Code: code,
+	})
+	if err != nil {
+		return nil, err
 	}
+	return res.RecoveryCodes, nil
+}
 
-	token, err := jwt.ParseWithClaims(accessToken, &JwtToken{},
-		func(t *jwt.Token) (interface{}, error) {
-			return []byte(s.cfg.SecretKey), nil
-		},
-	)
+func (s *authService) DisableMFA(ctx context.Context, password, code string) error {
+	_, err := s.authclient.DisableMFA(ctx, &userv1.DisableMFARequest{
+		Password: password,
+		Code:     code,
+	})
+	return err
+}
+
+func (s *authService) VerifyAPIKey(ctx context.Context, key string) (*token.JwtToken, error) {
+	res, err := s.apikeyclient.VerifyAPIKey(ctx, &userv1.VerifyAPIKeyRequest{Key: key})
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*JwtToken)
-	if !ok {
-		return nil, errors.New("Access token is invalid.")
+	claims := &token.JwtToken{
+		Roles:    res.Roles,
+		APIKeyID: res.ApiKeyId,
+		Scopes:   res.Scopes,
+	}
+	if res.OwnerType == apikey.OwnerTypeServiceAccount {
+		claims.ServiceAccountID = res.OwnerId
+	} else {
+		claims.UserID = res.OwnerId
 	}
 	return claims, nil
 }
 
-func (s *authService) setCookies(ctx context.Context, accessToken string) {
+// BeginOIDCLogin also sets the state in a cookie, so that the callback is
+// only accepted from the browser that started the login.
+func (s *authService) BeginOIDCLogin(ctx context.Context) (string, error) {
+	res, err := s.authclient.BeginOIDCLogin(ctx, &userv1.BeginOIDCLoginRequest{})
+	if err != nil {
+		return "", err
+	}
+
+	s.setOIDCStateCookie(ctx, res.State, int(s.oidcCfg.StateTTL/time.Second))
+	return res.AuthorizationUrl, nil
+}
+
+func (s *authService) CompleteOIDCLogin(ctx context.Context, state, code string) (*TokenPair, error) {
+	cookie, err := ctx.(*gin.Context).Cookie(s.oidcCfg.StateCookieName)
+	s.setOIDCStateCookie(ctx, "", -1)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		return nil, status.Error(codes.Unauthenticated, "Login state does not match.")
+	}
+
+	res, err := s.authclient.CompleteOIDCLogin(ctx, &userv1.CompleteOIDCLoginRequest{
+		State: state,
+		Code:  code,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.MfaRequired {
+		return &TokenPair{
+			MFARequired: true,
+			MFAToken:    res.MfaToken,
+		}, nil
+	}
+
+	return s.newTokenPair(ctx, res.UserId, res.SessionId, res.Roles, res.RefreshToken)
+}
+
+func (s *authService) ClientCredentials(ctx context.Context, clientID, clientSecret string, scopes []string) (*ClientToken, error) {
+	res, err := s.apikeyclient.VerifyClientCredentials(ctx, &userv1.VerifyClientCredentialsRequest{
+		ClientId:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.GenerateClientAccessToken(res.ClientId, res.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientToken{
+		AccessToken: accessToken,
+		ExpiresIn:   s.cfg.AccessTokenExpireTTL,
+		Scopes:      res.Scopes,
+	}, nil
+}
+
+func (s *authService) IntrospectToken(ctx context.Context, accessToken string) (*userv1.IntrospectTokenResponse, error) {
+	return s.authclient.IntrospectToken(ctx, &userv1.IntrospectTokenRequest{
+		AccessToken: accessToken,
+	})
+}
+
+func (s *authService) ImpersonateUser(ctx context.Context, userID, reason string) (*userv1.ImpersonateUserResponse, error) {
+	return s.authclient.ImpersonateUser(ctx, &userv1.ImpersonateUserRequest{
+		UserId: userID,
+		Reason: reason,
+	})
+}
+
+func (s *authService) ListSessions(ctx context.Context, userID string) ([]*userv1.Session, error) {
+	res, err := s.authclient.ListSessions(ctx, &userv1.ListSessionsRequest{
+		UserId: optionalUserID(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Sessions, nil
+}
+
+// RevokeSession also clears the cookies when the caller revokes their own
+// current session.
+func (s *authService) RevokeSession(ctx context.Context, id string) error {
+	if _, err := s.authclient.RevokeSession(ctx, &userv1.RevokeSessionRequest{
+		Id: id,
+	}); err != nil {
+		return err
+	}
+
+	if claims := identity.ClaimsFromContext(ctx); claims != nil && claims.SessionID == id {
+		s.clearCookies(ctx)
+	}
+	return nil
+}
+
+func (s *authService) RevokeAllSessions(ctx context.Context, userID string) error {
+	if _, err := s.authclient.RevokeAllSessions(ctx, &userv1.RevokeAllSessionsRequest{
+		UserId: optionalUserID(userID),
+	}); err != nil {
+		return err
+	}
+
+	if userID == "" || userID == identity.UserIDFromContext(ctx) {
+		s.clearCookies(ctx)
+	}
+	return nil
+}
+
+func (s *authService) newTokenPair(ctx context.Context, userID, sessionID string, roles []string, refreshToken string) (*TokenPair, error) {
+	accessToken, err := s.GenerateAccessToken(userID, sessionID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	var csrfToken string
+	if accessToken != "" {
+		csrfToken, err = util.RandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		s.setCookies(ctx, accessToken, csrfToken)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		CSRFToken:    csrfToken,
+		SessionID:    sessionID,
+	}, nil
+}
+
+// setCookies sets the access token in an HttpOnly cookie and the CSRF token
+// in a cookie readable by scripts, for the double-submit check of the auth
+// middleware.
+func (s *authService) setCookies(ctx context.Context, accessToken, csrfToken string) {
+	maxAge := int(s.cfg.AccessTokenExpireTTL / time.Second)
 	gCtx := ctx.(*gin.Context)
-	gCtx.SetSameSite(http.SameSiteDefaultMode)
-	gCtx.SetCookie("_uac", accessToken, int(s.cfg.AccessTokenExpireTTL/time.Second), "/", "localhost", false, true)
-	gCtx.SetCookie("_uac_s", accessToken, int(s.cfg.AccessTokenExpireTTL/time.Second), "/", "localhost", true, true)
+	gCtx.SetSameSite(s.sameSite)
+	gCtx.SetCookie(s.cfg.CookieName, accessToken, maxAge, "/", s.cfg.CookieDomain, s.cfg.CookieSecure, true)
+	gCtx.SetCookie(s.cfg.CSRFCookieName, csrfToken, maxAge, "/", s.cfg.CookieDomain, s.cfg.CookieSecure, false)
+}
+
+func (s *authService) clearCookies(ctx context.Context) {
+	gCtx := ctx.(*gin.Context)
+	gCtx.SetSameSite(s.sameSite)
+	gCtx.SetCookie(s.cfg.CookieName, "", -1, "/", s.cfg.CookieDomain, s.cfg.CookieSecure, true)
+	gCtx.SetCookie(s.cfg.CSRFCookieName, "", -1, "/", s.cfg.CookieDomain, s.cfg.CookieSecure, false)
+}
 
+// setOIDCStateCookie sets the state cookie, which has to be sent on the
+// redirect back from the identity provider. That redirect is a cross-site
+// navigation, so Strict is relaxed to Lax.
+func (s *authService) setOIDCStateCookie(ctx context.Context, state string, maxAge int) {
+	sameSite := s.sameSite
+	if sameSite == http.SameSiteStrictMode {
+		sameSite = http.SameSiteLaxMode
+	}
+
+	gCtx := ctx.(*gin.Context)
+	gCtx.SetSameSite(sameSite)
+	gCtx.SetCookie(s.oidcCfg.StateCookieName, state, maxAge, oidcCookiePath, s.cfg.CookieDomain, s.cfg.CookieSecure, true)
+}
+
+func optionalUserID(userID string) *string {
+	if userID == "" {
+		return nil
+	}
+	return &userID
+}
+
+func parseSameSite(s string) (http.SameSite, error) {
+	switch strings.ToLower(s) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("auth: unknown SameSite mode %q", s)
+	}
 }
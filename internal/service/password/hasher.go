@@ -0,0 +1,231 @@
+package password
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/nuea/backend-golang-test/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var ErrUnknownHash = errors.New("password hasher: unknown hash format")
+
+// Hasher hashes passwords into PHC strings, for example
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash> or $bcrypt$v=2a,r=12$<salt>$<hash>.
+// The work runs on a bounded worker pool, both methods fail with
+// ErrHasherBusy when it is saturated.
+type Hasher interface {
+	Hash(ctx context.Context, password string) (string, error)
+	// Verify reports whether password matches the encoded hash, and whether
+	// the hash should be replaced because it was made with other parameters
+	// than the configured ones. Legacy bcrypt hashes ($2a$...) are accepted
+	// and always need a rehash.
+	Verify(ctx context.Context, password, encoded string) (ok bool, rehash bool, err error)
+}
+
+type hasher struct {
+	cfg  *config.PasswordHashConfig
+	pool *pool
+}
+
+func ProvideHasher(cfg *config.AppConfig) (Hasher, func(), error) {
+	c := &cfg.PasswordHash
+	switch c.Algorithm {
+	case AlgorithmArgon2id:
+		if c.Argon2Memory == 0 || c.Argon2Iterations == 0 || c.Argon2Parallelism == 0 {
+			return nil, nil, errors.New("password hasher: argon2id memory, iterations and parallelism must be positive")
+		}
+	case AlgorithmBcrypt:
+		if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+			return nil, nil, fmt.Errorf("password hasher: bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, nil, fmt.Errorf("password hasher: unknown hash algorithm %q", c.Algorithm)
+	}
+
+	workers := c.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if c.QueueSize < 0 {
+		return nil, nil, errors.New("password hasher: queue size must not be negative")
+	}
+
+	p := newPool(workers, c.QueueSize)
+	return &hasher{cfg: c, pool: p}, p.stop, nil
+}
+
+func (h *hasher) Hash(ctx context.Context, password string) (string, error) {
+	var hash string
+	var err error
+	if perr := h.pool.run(ctx, func() { hash, err = h.hash(password) }); perr != nil {
+		return "", perr
+	}
+	return hash, err
+}
+
+func (h *hasher) Verify(ctx context.Context, password, encoded string) (bool, bool, error) {
+	// Accounts created before the password policy can have an empty hash,
+	// they never match and need no worker.
+	if encoded == "" {
+		return false, false, nil
+	}
+
+	var ok, rehash bool
+	var err error
+	if perr := h.pool.run(ctx, func() { ok, rehash, err = h.verify(password, encoded) }); perr != nil {
+		return false, false, perr
+	}
+	return ok, rehash, err
+}
+
+func (h *hasher) hash(password string) (string, error) {
+	if h.cfg.Algorithm == AlgorithmBcrypt {
+		return hashBcrypt(password, h.cfg.BcryptCost)
+	}
+	return hashArgon2id(password, h.cfg.Argon2Memory, h.cfg.Argon2Iterations, h.cfg.Argon2Parallelism)
+}
+
+func (h *hasher) verify(password, encoded string) (bool, bool, error) {
+	if strings.HasPrefix(encoded, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil, true, nil
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 2 || parts[0] != "" {
+		return false, false, ErrUnknownHash
+	}
+
+	switch parts[1] {
+	case AlgorithmArgon2id:
+		return h.verifyArgon2id(password, parts)
+	case AlgorithmBcrypt:
+		return h.verifyBcrypt(password, parts)
+	default:
+		return false, false, ErrUnknownHash
+	}
+}
+
+func hashArgon2id(password string, memory, iterations uint32, parallelism uint8) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, argon2KeyLength)
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, memory, iterations, parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyArgon2id checks $argon2id$v=19$m=...,t=...,p=...$<salt>$<hash>.
+func (h *hasher) verifyArgon2id(password string, parts []string) (bool, bool, error) {
+	if len(parts) != 6 {
+		return false, false, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrUnknownHash
+	}
+
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrUnknownHash
+	}
+
+	other := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	rehash := h.cfg.Algorithm != AlgorithmArgon2id ||
+		memory != h.cfg.Argon2Memory ||
+		iterations != h.cfg.Argon2Iterations ||
+		parallelism != h.cfg.Argon2Parallelism ||
+		len(salt) != argon2SaltLength ||
+		len(key) != argon2KeyLength
+	return true, rehash, nil
+}
+
+// hashBcrypt splits the bcrypt output $2a$12$<22 chars salt><31 chars hash>
+// into $bcrypt$v=2a,r=12$<salt>$<hash>. The salt and hash keep the bcrypt
+// alphabet.
+func hashBcrypt(password string, cost int) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", status.Error(codes.InvalidArgument, "Password must be at most 72 bytes.")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(string(b), "$")
+	if len(parts) != 4 || len(parts[3]) != 53 {
+		return "", ErrUnknownHash
+	}
+	return fmt.Sprintf("$%s$v=%s,r=%s$%s$%s", AlgorithmBcrypt, parts[1], parts[2], parts[3][:22], parts[3][22:]), nil
+}
+
+func (h *hasher) verifyBcrypt(password string, parts []string) (bool, bool, error) {
+	if len(parts) != 5 {
+		return false, false, ErrUnknownHash
+	}
+
+	var version string
+	var cost int
+	for _, param := range strings.Split(parts[2], ",") {
+		k, v, _ := strings.Cut(param, "=")
+		switch k {
+		case "v":
+			version = v
+		case "r":
+			if _, err := fmt.Sscanf(v, "%d", &cost); err != nil {
+				return false, false, ErrUnknownHash
+			}
+		}
+	}
+	if version == "" || cost == 0 {
+		return false, false, ErrUnknownHash
+	}
+
+	mcf := fmt.Sprintf("$%s$%02d$%s%s", version, cost, parts[3], parts[4])
+	if err := bcrypt.CompareHashAndPassword([]byte(mcf), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		return false, false, ErrUnknownHash
+	}
+
+	rehash := h.cfg.Algorithm != AlgorithmBcrypt || cost != h.cfg.BcryptCost
+	return true, rehash, nil
+}
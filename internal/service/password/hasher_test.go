@@ -0,0 +1,109 @@
+package password
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	testArgon2id = config.PasswordHashConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+	testBcrypt   = config.PasswordHashConfig{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+)
+
+func newHasher(t *testing.T, cfg config.PasswordHashConfig) Hasher {
+	h, cleanup, err := ProvideHasher(&config.AppConfig{PasswordHash: cfg})
+	assert.NoError(t, err)
+	t.Cleanup(cleanup)
+	return h
+}
+
+func TestProvideHasher(t *testing.T) {
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, _, err := ProvideHasher(&config.AppConfig{PasswordHash: config.PasswordHashConfig{Algorithm: "md5"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid bcrypt cost", func(t *testing.T) {
+		_, _, err := ProvideHasher(&config.AppConfig{PasswordHash: config.PasswordHashConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 1}})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid argon2id parameters", func(t *testing.T) {
+		_, _, err := ProvideHasher(&config.AppConfig{PasswordHash: config.PasswordHashConfig{Algorithm: AlgorithmArgon2id}})
+		assert.Error(t, err)
+	})
+}
+
+func TestHasher(t *testing.T) {
+	ctx := context.Background()
+	for _, cfg := range []config.PasswordHashConfig{testArgon2id, testBcrypt} {
+		t.Run(cfg.Algorithm, func(t *testing.T) {
+			h := newHasher(t, cfg)
+
+			hash, err := h.Hash(ctx, "password")
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, "$"+cfg.Algorithm+"$"))
+
+			ok, rehash, err := h.Verify(ctx, "password", hash)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.False(t, rehash)
+
+			ok, _, err = h.Verify(ctx, "wrong", hash)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestHasherVerify(t *testing.T) {
+	ctx := context.Background()
+	h := newHasher(t, testArgon2id)
+
+	t.Run("argon2id with other parameters", func(t *testing.T) {
+		other := testArgon2id
+		other.Argon2Iterations = 2
+		hash, _ := newHasher(t, other).Hash(ctx, "password")
+
+		ok, rehash, err := h.Verify(ctx, "password", hash)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, rehash)
+	})
+
+	t.Run("bcrypt", func(t *testing.T) {
+		hash, _ := newHasher(t, testBcrypt).Hash(ctx, "password")
+
+		ok, rehash, err := h.Verify(ctx, "password", hash)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, rehash)
+	})
+
+	t.Run("legacy bcrypt", func(t *testing.T) {
+		legacy, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+		ok, rehash, err := newHasher(t, testBcrypt).Verify(ctx, "password", string(legacy))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, rehash)
+	})
+
+	t.Run("empty hash", func(t *testing.T) {
+		ok, _, err := h.Verify(ctx, "", "")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("unknown hash", func(t *testing.T) {
+		for _, hash := range []string{"plain", "$md5$abc", "$argon2id$v=19$m=64$salt", "$bcrypt$v=2a$salt$hash"} {
+			_, _, err := h.Verify(ctx, "password", hash)
+			assert.ErrorIs(t, err, ErrUnknownHash, hash)
+		}
+	})
+}
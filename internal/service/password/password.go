@@ -0,0 +1,214 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nuea/backend-golang-test/internal/config"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:embed common-passwords.txt
+var commonPasswords string
+
+type CharacterClass string
+
+const (
+	ClassLower  CharacterClass = "lower"
+	ClassUpper  CharacterClass = "upper"
+	ClassDigit  CharacterClass = "digit"
+	ClassSymbol CharacterClass = "symbol"
+)
+
+var characterClasses = map[CharacterClass]struct {
+	match   func(r rune) bool
+	message string
+}{
+	ClassLower:  {unicode.IsLower, "Password must contain a lowercase letter."},
+	ClassUpper:  {unicode.IsUpper, "Password must contain an uppercase letter."},
+	ClassDigit:  {unicode.IsDigit, "Password must contain a digit."},
+	ClassSymbol: {func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) }, "Password must contain a symbol."},
+}
+
+type Violation struct {
+	Reason  string
+	Message string
+}
+
+// PolicyError lists every rule the password breaks, so that a client can
+// show them all at once.
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	return "Password does not meet the password policy."
+}
+
+// GRPCStatus returns the violations as BadRequest details, so handlers can
+// return the error as it is.
+func (e *PolicyError) GRPCStatus() *status.Status {
+	br := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       "password",
+			Reason:      v.Reason,
+			Description: v.Message,
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, e.Error())
+	if withDetails, err := st.WithDetails(br); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+type PasswordPolicy interface {
+	// Validate checks password against the policy. recentHashes are the
+	// current and previous password hashes of the user, newest first.
+	Validate(ctx context.Context, password string, recentHashes ...string) error
+	// Remember returns the password history to store once current is
+	// replaced.
+	Remember(current string, history []string) []string
+}
+
+type passwordPolicy struct {
+	cfg      *config.PasswordPolicyConfig
+	hasher   Hasher
+	classes  []CharacterClass
+	breached map[string]struct{}
+}
+
+func ProvidePasswordPolicy(cfg *config.AppConfig, hasher Hasher) (PasswordPolicy, error) {
+	if cfg.PasswordPolicy.HistorySize < 0 {
+		return nil, fmt.Errorf("password policy: history size %d is negative", cfg.PasswordPolicy.HistorySize)
+	}
+	if cfg.PasswordPolicy.MaxLength > 0 && cfg.PasswordPolicy.MinLength > cfg.PasswordPolicy.MaxLength {
+		return nil, fmt.Errorf("password policy: min length %d is greater than max length %d", cfg.PasswordPolicy.MinLength, cfg.PasswordPolicy.MaxLength)
+	}
+
+	var classes []CharacterClass
+	for _, c := range cfg.PasswordPolicy.CharacterClasses {
+		class := CharacterClass(strings.TrimSpace(c))
+		if _, ok := characterClasses[class]; !ok {
+			return nil, fmt.Errorf("password policy: unknown character class %q", c)
+		}
+		classes = append(classes, class)
+	}
+
+	var list io.Reader = strings.NewReader(commonPasswords)
+	if cfg.PasswordPolicy.BreachedListFile != "" {
+		f, err := os.Open(cfg.PasswordPolicy.BreachedListFile)
+		if err != nil {
+			return nil, fmt.Errorf("password policy: %w", err)
+		}
+		defer f.Close()
+		list = f
+	}
+
+	breached, err := readList(list)
+	if err != nil {
+		return nil, fmt.Errorf("password policy: %w", err)
+	}
+
+	return &passwordPolicy{
+		cfg:      &cfg.PasswordPolicy,
+		hasher:   hasher,
+		classes:  classes,
+		breached: breached,
+	}, nil
+}
+
+func (p *passwordPolicy) Validate(ctx context.Context, password string, recentHashes ...string) error {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		violations = append(violations, Violation{
+			Reason:  "min_length",
+			Message: fmt.Sprintf("Password must be at least %d characters.", p.cfg.MinLength),
+		})
+	}
+
+	// bcrypt only uses the first 72 bytes, the limit also keeps hashing of
+	// huge inputs cheap.
+	if p.cfg.MaxLength > 0 && len(password) > p.cfg.MaxLength {
+		violations = append(violations, Violation{
+			Reason:  "max_length",
+			Message: fmt.Sprintf("Password must be at most %d bytes.", p.cfg.MaxLength),
+		})
+	}
+
+	for _, class := range p.classes {
+		if !strings.ContainsFunc(password, characterClasses[class].match) {
+			violations = append(violations, Violation{
+				Reason:  "character_class_" + string(class),
+				Message: characterClasses[class].message,
+			})
+		}
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		violations = append(violations, Violation{
+			Reason:  "breached",
+			Message: "Password is too common.",
+		})
+	}
+
+	// The hashes are only compared when the password is otherwise valid,
+	// each comparison costs a full hash.
+	if len(violations) == 0 {
+		for _, hash := range recentHashes[:min(len(recentHashes), p.cfg.HistorySize)] {
+			ok, _, err := p.hasher.Verify(ctx, password, hash)
+			if err != nil && !errors.Is(err, ErrUnknownHash) {
+				return err
+			}
+			if ok {
+				violations = append(violations, Violation{
+					Reason:  "reused",
+					Message: fmt.Sprintf("Password must differ from the last %d passwords.", p.cfg.HistorySize),
+				})
+				break
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Remember keeps the previous hashes so that, together with the new current
+// hash, the last HistorySize passwords are known.
+func (p *passwordPolicy) Remember(current string, history []string) []string {
+	if p.cfg.HistorySize <= 1 || current == "" {
+		return nil
+	}
+	history = slices.Insert(slices.Clone(history), 0, current)
+	return history[:min(len(history), p.cfg.HistorySize-1)]
+}
+
+func readList(r io.Reader) (map[string]struct{}, error) {
+	list := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+	return list, scanner.Err()
+}
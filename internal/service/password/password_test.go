@@ -0,0 +1,126 @@
+package password
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newPolicy(t *testing.T, cfg config.PasswordPolicyConfig) PasswordPolicy {
+	p, err := ProvidePasswordPolicy(&config.AppConfig{PasswordPolicy: cfg}, newHasher(t, testBcrypt))
+	assert.NoError(t, err)
+	return p
+}
+
+func reasons(err error) []string {
+	var out []string
+	if pe, ok := err.(*PolicyError); ok {
+		for _, v := range pe.Violations {
+			out = append(out, v.Reason)
+		}
+	}
+	return out
+}
+
+func TestProvidePasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown character class", func(t *testing.T) {
+		_, err := ProvidePasswordPolicy(&config.AppConfig{PasswordPolicy: config.PasswordPolicyConfig{
+			CharacterClasses: []string{"emoji"},
+		}}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("negative history size", func(t *testing.T) {
+		_, err := ProvidePasswordPolicy(&config.AppConfig{PasswordPolicy: config.PasswordPolicyConfig{
+			HistorySize: -1,
+		}}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("min length greater than max length", func(t *testing.T) {
+		_, err := ProvidePasswordPolicy(&config.AppConfig{PasswordPolicy: config.PasswordPolicyConfig{
+			MinLength: 16,
+			MaxLength: 8,
+		}}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("breached list file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "breached.txt")
+		assert.NoError(t, os.WriteFile(file, []byte("# comment\nCorrectHorse\n"), 0o600))
+
+		p := newPolicy(t, config.PasswordPolicyConfig{BreachedListFile: file})
+
+		assert.Equal(t, []string{"breached"}, reasons(p.Validate(ctx, "correcthorse")))
+		assert.NoError(t, p.Validate(ctx, "password"))
+	})
+
+	t.Run("missing breached list file", func(t *testing.T) {
+		_, err := ProvidePasswordPolicy(&config.AppConfig{PasswordPolicy: config.PasswordPolicyConfig{
+			BreachedListFile: filepath.Join(t.TempDir(), "missing.txt"),
+		}}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	p := newPolicy(t, config.PasswordPolicyConfig{
+		MinLength:        8,
+		MaxLength:        16,
+		CharacterClasses: []string{"lower", "upper", "digit", "symbol"},
+		HistorySize:      2,
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, p.Validate(ctx, "Tr0ub4dor&3"))
+	})
+
+	t.Run("every violation is reported", func(t *testing.T) {
+		err := p.Validate(ctx, "")
+
+		assert.Equal(t, []string{"min_length", "character_class_lower", "character_class_upper", "character_class_digit", "character_class_symbol"}, reasons(err))
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		assert.Equal(t, []string{"max_length"}, reasons(p.Validate(ctx, "Tr0ub4dor&3-Tr0ub4dor&3")))
+	})
+
+	t.Run("common password", func(t *testing.T) {
+		p := newPolicy(t, config.PasswordPolicyConfig{MinLength: 8})
+
+		assert.Equal(t, []string{"breached"}, reasons(p.Validate(ctx, "Password123")))
+	})
+
+	t.Run("reused password", func(t *testing.T) {
+		current, _ := newHasher(t, testBcrypt).Hash(ctx, "Tr0ub4dor&3")
+
+		assert.Equal(t, []string{"reused"}, reasons(p.Validate(ctx, "Tr0ub4dor&3", current)))
+	})
+
+	t.Run("grpc status", func(t *testing.T) {
+		st := status.Convert(p.Validate(ctx, "short"))
+
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		br, ok := st.Details()[0].(*errdetails.BadRequest)
+		assert.True(t, ok)
+		assert.Equal(t, "min_length", br.FieldViolations[0].Reason)
+	})
+}
+
+func TestRemember(t *testing.T) {
+	p := newPolicy(t, config.PasswordPolicyConfig{HistorySize: 3})
+
+	assert.Equal(t, []string{"c"}, p.Remember("c", nil))
+	assert.Equal(t, []string{"c", "b"}, p.Remember("c", []string{"b", "a"}))
+	assert.Nil(t, newPolicy(t, config.PasswordPolicyConfig{HistorySize: 1}).Remember("c", []string{"b"}))
+}
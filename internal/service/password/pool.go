@@ -0,0 +1,122 @@
+package password
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrHasherBusy is returned instead of waiting when every worker is busy and
+// the queue is full.
+var ErrHasherBusy = status.Error(codes.Unavailable, "Server is busy, try again later.")
+
+type job struct {
+	ctx      context.Context
+	fn       func()
+	queuedAt time.Time
+	err      error
+	done     chan struct{}
+}
+
+// pool runs the hashing on a fixed number of workers, so that a burst of
+// logins cannot take every CPU from the other RPCs. At most workers +
+// queueSize jobs are accepted at a time. Its metrics are published with
+// expvar as "password_hasher".
+type pool struct {
+	slots   chan struct{}
+	jobs    chan *job
+	quit    chan struct{}
+	metrics *expvar.Map
+}
+
+func newPool(workers, queueSize int) *pool {
+	p := &pool{
+		slots:   make(chan struct{}, workers+queueSize),
+		jobs:    make(chan *job, workers+queueSize),
+		quit:    make(chan struct{}),
+		metrics: new(expvar.Map).Init(),
+	}
+	p.metrics.Add("workers", int64(workers))
+	p.metrics.Add("queue_size", int64(queueSize))
+	for _, key := range []string{"queued", "active", "completed", "rejected", "canceled"} {
+		p.metrics.Add(key, 0)
+	}
+	p.metrics.AddFloat("wait_seconds_total", 0)
+	p.metrics.AddFloat("run_seconds_total", 0)
+
+	if expvar.Get("password_hasher") == nil {
+		expvar.Publish("password_hasher", p.metrics)
+	}
+
+	for range workers {
+		go p.work()
+	}
+	return p
+}
+
+func (p *pool) work() {
+	for {
+		select {
+		case <-p.quit:
+			return
+		case j := <-p.jobs:
+			p.metrics.Add("queued", -1)
+			p.metrics.AddFloat("wait_seconds_total", time.Since(j.queuedAt).Seconds())
+
+			// The caller has already given up, skip the work.
+			if err := j.ctx.Err(); err != nil {
+				j.err = err
+			} else {
+				p.metrics.Add("active", 1)
+				start := time.Now()
+				j.fn()
+				p.metrics.AddFloat("run_seconds_total", time.Since(start).Seconds())
+				p.metrics.Add("active", -1)
+				p.metrics.Add("completed", 1)
+			}
+
+			close(j.done)
+			<-p.slots
+		}
+	}
+}
+
+// run queues fn and waits until a worker has run it. It fails fast with
+// ErrHasherBusy when the queue is full, and returns the context error when
+// ctx is done first.
+func (p *pool) run(ctx context.Context, fn func()) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.metrics.Add("rejected", 1)
+		return ErrHasherBusy
+	}
+
+	// A slot guarantees room in jobs, the send does not block.
+	j := &job{ctx: ctx, fn: fn, queuedAt: time.Now(), done: make(chan struct{})}
+	p.metrics.Add("queued", 1)
+	p.jobs <- j
+
+	select {
+	case <-j.done:
+		if j.err != nil {
+			p.metrics.Add("canceled", 1)
+			return status.FromContextError(j.err).Err()
+		}
+		return nil
+	case <-ctx.Done():
+		p.metrics.Add("canceled", 1)
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (p *pool) stop() {
+	close(p.quit)
+}
@@ -0,0 +1,83 @@
+package password
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func metric(p *pool, key string) string {
+	return p.metrics.Get(key).String()
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("run", func(t *testing.T) {
+		p := newPool(2, 2)
+		t.Cleanup(p.stop)
+
+		ran := false
+		assert.NoError(t, p.run(ctx, func() { ran = true }))
+		assert.True(t, ran)
+		assert.Equal(t, "1", metric(p, "completed"))
+	})
+
+	t.Run("saturated", func(t *testing.T) {
+		p := newPool(1, 1)
+		t.Cleanup(p.stop)
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go p.run(ctx, func() {
+			close(started)
+			<-release
+		})
+		<-started
+		go p.run(ctx, func() {})
+		assert.Eventually(t, func() bool { return metric(p, "queued") == "1" }, time.Second, time.Millisecond)
+
+		err := p.run(ctx, func() { t.Error("must not run") })
+
+		assert.ErrorIs(t, err, ErrHasherBusy)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, "1", metric(p, "rejected"))
+		close(release)
+	})
+
+	t.Run("canceled while queued", func(t *testing.T) {
+		p := newPool(1, 1)
+		t.Cleanup(p.stop)
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go p.run(ctx, func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		err := p.run(cctx, func() { t.Error("must not run") })
+
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Equal(t, "1", metric(p, "canceled"))
+		close(release)
+		assert.Eventually(t, func() bool { return metric(p, "queued") == "0" }, time.Second, time.Millisecond)
+	})
+
+	t.Run("context already done", func(t *testing.T) {
+		p := newPool(1, 1)
+		t.Cleanup(p.stop)
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		assert.Equal(t, codes.Canceled, status.Code(p.run(cctx, func() {})))
+	})
+}
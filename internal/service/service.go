@@ -2,7 +2,10 @@ package service
 
 import (
 	"github.com/google/wire"
+	"github.com/nuea/backend-golang-test/internal/service/apikey"
 	"github.com/nuea/backend-golang-test/internal/service/auth"
+	"github.com/nuea/backend-golang-test/internal/service/password"
+	"github.com/nuea/backend-golang-test/internal/service/token"
 )
 
 type Service struct {
@@ -10,7 +13,11 @@ type Service struct {
 }
 
 var ServiceSet = wire.NewSet(
+	token.ProvideTokenService,
 	auth.ProvideAuthenticationService,
+	password.ProvidePasswordPolicy,
+	password.ProvideHasher,
+	apikey.ProvideAPIKeyService,
 
 	wire.Struct(new(Service), "*"),
 )
@@ -0,0 +1,313 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/config"
+)
+
+// Key is a single entry of the keyring. Verify-only entries (public key
+// without private key) are allowed so that a process can trust keys it
+// never signs with.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	NotBefore  time.Time
+	ExpiresAt  time.Time
+	signingKey interface{}
+	verifyKey  interface{}
+}
+
+func (k *Key) canSign() bool {
+	return k.signingKey != nil
+}
+
+func (k *Key) isActive(now time.Time) bool {
+	return !now.Before(k.NotBefore) && !k.isExpired(now)
+}
+
+func (k *Key) isExpired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+func (k *Key) isSymmetric() bool {
+	_, ok := k.verifyKey.([]byte)
+	return ok
+}
+
+type keyringFile struct {
+	Keys []struct {
+		ID         string     `json:"kid"`
+		Algorithm  string     `json:"alg"`
+		PrivateKey string     `json:"private_key,omitempty"`
+		PublicKey  string     `json:"public_key,omitempty"`
+		NotBefore  time.Time  `json:"not_before"`
+		ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	} `json:"keys"`
+}
+
+// Keyring holds the asymmetric signing keys described by the keyring file.
+//
+// Rotation is driven by the schedule in the file: the signing key is the
+// newest key whose not_before has passed, while every key that has not
+// reached expires_at is still accepted for verification and published in
+// the JWKS. New keys should be added ahead of their not_before so that
+// verifiers pick them up before the first token is signed with them. The
+// file is re-read at most once per reload interval when it has changed.
+type Keyring struct {
+	mu             sync.RWMutex
+	path           string
+	reloadInterval time.Duration
+	checkedAt      time.Time
+	modTime        time.Time
+	keys           []*Key
+	legacy         *Key
+}
+
+func NewKeyring(cfg *config.AuthConfig) (*Keyring, error) {
+	kr := &Keyring{
+		path:           cfg.KeyringFile,
+		reloadInterval: cfg.KeyringReloadInterval,
+	}
+
+	if cfg.SecretKey != "" {
+		kr.legacy = &Key{
+			Method:     jwt.SigningMethodHS256,
+			signingKey: []byte(cfg.SecretKey),
+			verifyKey:  []byte(cfg.SecretKey),
+		}
+	}
+
+	if kr.path != "" {
+		if err := kr.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(kr.keys) == 0 && kr.legacy == nil {
+		return nil, errors.New("auth: no signing key configured, set AUTH_KEYRING_FILE or AUTH_SECRET_KEY")
+	}
+	return kr, nil
+}
+
+// SigningKey returns the key new tokens are signed with. The legacy shared
+// secret is only used when the keyring has no active private key.
+func (kr *Keyring) SigningKey() (*Key, error) {
+	kr.reload()
+
+	now := time.Now()
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	var active *Key
+	for _, k := range kr.keys {
+		if k.canSign() && k.isActive(now) && (active == nil || k.NotBefore.After(active.NotBefore)) {
+			active = k
+		}
+	}
+	if active != nil {
+		return active, nil
+	}
+	if kr.legacy != nil {
+		return kr.legacy, nil
+	}
+	return nil, errors.New("No active signing key.")
+}
+
+// VerificationKey looks up the key a token claims to be signed with. The
+// algorithm in the token header must match the key, otherwise an attacker
+// could present an RSA public key as an HMAC secret.
+func (kr *Keyring) VerificationKey(kid, alg string) (interface{}, error) {
+	kr.reload()
+
+	now := time.Now()
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	var key *Key
+	if kid == "" {
+		key = kr.legacy
+	} else {
+		for _, k := range kr.keys {
+			if k.ID == kid {
+				key = k
+				break
+			}
+		}
+	}
+
+	if key == nil || key.isExpired(now) {
+		return nil, errors.New("Access token is signed with an unknown key.")
+	}
+	if key.Method.Alg() != alg {
+		return nil, errors.New("Access token signing method is invalid.")
+	}
+	return key.verifyKey, nil
+}
+
+// PublicKeys returns every asymmetric key that can still verify tokens,
+// including keys scheduled for future use.
+func (kr *Keyring) PublicKeys() []*Key {
+	kr.reload()
+
+	now := time.Now()
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(kr.keys))
+	for _, k := range kr.keys {
+		if !k.isSymmetric() && !k.isExpired(now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (kr *Keyring) reload() {
+	if kr.path == "" || kr.reloadInterval <= 0 {
+		return
+	}
+
+	kr.mu.RLock()
+	due := time.Since(kr.checkedAt) >= kr.reloadInterval
+	kr.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	if err := kr.load(); err != nil {
+		log.Println("Unable to reload the keyring, keeping the current keys:", err)
+	}
+}
+
+func (kr *Keyring) load() error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	kr.checkedAt = time.Now()
+	info, err := os.Stat(kr.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(kr.modTime) && kr.keys != nil {
+		return nil
+	}
+
+	b, err := os.ReadFile(kr.path)
+	if err != nil {
+		return err
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("keyring %s: %w", kr.path, err)
+	}
+
+	keys := make([]*Key, 0, len(f.Keys))
+	seen := make(map[string]bool)
+	for _, entry := range f.Keys {
+		if entry.ID == "" {
+			return fmt.Errorf("keyring %s: kid is required", kr.path)
+		}
+		if seen[entry.ID] {
+			return fmt.Errorf("keyring %s: duplicate kid %q", kr.path, entry.ID)
+		}
+		seen[entry.ID] = true
+
+		key, err := parseKey(filepath.Dir(kr.path), entry.Algorithm, entry.PrivateKey, entry.PublicKey)
+		if err != nil {
+			return fmt.Errorf("keyring %s: kid %q: %w", kr.path, entry.ID, err)
+		}
+		key.ID = entry.ID
+		key.NotBefore = entry.NotBefore
+		if entry.ExpiresAt != nil {
+			key.ExpiresAt = *entry.ExpiresAt
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].NotBefore.Before(keys[j].NotBefore) })
+
+	kr.keys = keys
+	kr.modTime = info.ModTime()
+	return nil
+}
+
+func parseKey(dir, alg, privatePath, publicPath string) (*Key, error) {
+	if privatePath == "" && publicPath == "" {
+		return nil, errors.New("private_key or public_key is required")
+	}
+
+	read := func(p string) ([]byte, error) {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		return os.ReadFile(p)
+	}
+
+	key := &Key{}
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+		if privatePath != "" {
+			b, err := read(privatePath)
+			if err != nil {
+				return nil, err
+			}
+			pk, err := jwt.ParseRSAPrivateKeyFromPEM(b)
+			if err != nil {
+				return nil, err
+			}
+			key.signingKey, key.verifyKey = pk, &pk.PublicKey
+		} else {
+			b, err := read(publicPath)
+			if err != nil {
+				return nil, err
+			}
+			if key.verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(b); err != nil {
+				return nil, err
+			}
+		}
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+		if privatePath != "" {
+			b, err := read(privatePath)
+			if err != nil {
+				return nil, err
+			}
+			pk, err := jwt.ParseEdPrivateKeyFromPEM(b)
+			if err != nil {
+				return nil, err
+			}
+			key.signingKey, key.verifyKey = pk, pk.(crypto.Signer).Public()
+		} else {
+			b, err := read(publicPath)
+			if err != nil {
+				return nil, err
+			}
+			if key.verifyKey, err = jwt.ParseEdPublicKeyFromPEM(b); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported alg %q, expected RS256 or EdDSA", alg)
+	}
+
+	switch key.verifyKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, errors.New("key type does not match alg")
+	}
+}
@@ -0,0 +1,259 @@
+package token
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/nuea/backend-golang-test/internal/util"
+)
+
+var (
+	ErrTokenExpired = errors.New("Access token has expired.")
+	ErrTokenInvalid = errors.New("Access token is invalid.")
+)
+
+// legacyExpiresAtMin tells the tokens issued before exp was in seconds apart,
+// their exp is in milliseconds. As seconds it is in the year 5138, as
+// milliseconds in 1973.
+const legacyExpiresAtMin = 1e11
+
+type TokenService interface {
+	// GenerateAccessToken issues a token for the user, bound to the session
+	// it was refreshed from so that revoking the session revokes it too.
+	GenerateAccessToken(userID, sessionID string, roles []string) (string, error)
+	// GenerateClientAccessToken issues a token for an OAuth client, it
+	// grants the scopes only and acts for no user.
+	GenerateClientAccessToken(clientID string, scopes []string) (string, error)
+	// GenerateImpersonationToken issues a token for the user that names
+	// the actor who acts as them, it expires after ImpersonationTTL.
+	GenerateImpersonationToken(userID, actorID string, roles []string) (string, error)
+	// VerifyAccessToken checks the signature and the registered claims of
+	// the token. An expired token fails with ErrTokenExpired.
+	VerifyAccessToken(accessToken string) (*JwtToken, error)
+	JWKS() *JSONWebKeySet
+}
+
+type tokenService struct {
+	cfg     *config.AuthConfig
+	keyring *Keyring
+	// legacyUntil is when legacy tokens stop being accepted, the zero time
+	// when they never are.
+	legacyUntil time.Time
+}
+
+func ProvideTokenService(cfg *config.AppConfig) (TokenService, error) {
+	keyring, err := NewKeyring(&cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	var legacyUntil time.Time
+	if cfg.Auth.AcceptLegacyTokens {
+		legacyUntil = cfg.Auth.LegacyTokensUntil
+		if legacyUntil.IsZero() {
+			legacyUntil = time.Now().Add(cfg.Auth.AccessTokenExpireTTL)
+		}
+	}
+
+	return &tokenService{
+		cfg:         &cfg.Auth,
+		keyring:     keyring,
+		legacyUntil: legacyUntil,
+	}, nil
+}
+
+// JwtToken carries the registered claims of RFC 7519. sub is the user id, or
+// the client id for a client token; uid is kept for the servers that read it
+// during an upgrade.
+type JwtToken struct {
+	jwt.StandardClaims
+	UserID    string   `json:"uid,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	// Scope lists the scopes of a client token separated by spaces, as in
+	// RFC 9068. It is split into Scopes when the token is verified.
+	Scope string `json:"scope,omitempty"`
+	// Actor is set on an impersonation token, see RFC 8693 section 4.1.
+	Actor *Actor `json:"act,omitempty"`
+
+	// The fields below are never signed, they are set when the caller
+	// authenticated with an API key instead of an access token.
+	APIKeyID         string   `json:"-"`
+	ServiceAccountID string   `json:"-"`
+	Scopes           []string `json:"-"`
+}
+
+// Actor is the user acting as the subject of the token.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// IsImpersonated reports whether the token was issued to an actor acting as
+// the user.
+func (t *JwtToken) IsImpersonated() bool {
+	return t.Actor != nil
+}
+
+// HasPermission reports whether the roles grant perm and, for an API key,
+// whether the key was created with perm in its scopes. Scopes only narrow
+// what the owner may do. A client token has no roles, its scopes are the
+// permissions granted when the client was registered.
+func (t *JwtToken) HasPermission(perm rbac.Permission) bool {
+	if t.ClientID != "" {
+		return t.HasScope(perm)
+	}
+	return rbac.HasPermission(t.Roles, perm) && t.HasScope(perm)
+}
+
+// CanManage reports whether the token holds every permission the roles
+// grant. Changing a user with more access, e.g. their email, could otherwise
+// be used to take over the account.
+func (t *JwtToken) CanManage(roles []string) bool {
+	for _, perm := range rbac.Permissions(roles) {
+		if !t.HasPermission(perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasScope is always true for the access tokens of users.
+func (t *JwtToken) HasScope(perm rbac.Permission) bool {
+	return (t.APIKeyID == "" && t.ClientID == "") || slices.Contains(t.Scopes, string(perm))
+}
+
+func (t *JwtToken) ExpiresAtTime() time.Time {
+	if t.isLegacy() {
+		return time.UnixMilli(t.ExpiresAt)
+	}
+	return time.Unix(t.ExpiresAt, 0)
+}
+
+// isLegacy reports whether the token was issued with exp in milliseconds and
+// without the other registered claims.
+func (t *JwtToken) isLegacy() bool {
+	return t.ExpiresAt >= legacyExpiresAtMin
+}
+
+func (s *tokenService) GenerateAccessToken(userID, sessionID string, roles []string) (string, error) {
+	return s.sign(userID, s.cfg.AccessTokenExpireTTL, &JwtToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		Roles:     roles,
+	})
+}
+
+func (s *tokenService) GenerateClientAccessToken(clientID string, scopes []string) (string, error) {
+	return s.sign(clientID, s.cfg.AccessTokenExpireTTL, &JwtToken{
+		ClientID: clientID,
+		Scope:    strings.Join(scopes, " "),
+	})
+}
+
+func (s *tokenService) GenerateImpersonationToken(userID, actorID string, roles []string) (string, error) {
+	return s.sign(userID, s.cfg.ImpersonationTTL, &JwtToken{
+		UserID: userID,
+		Roles:  roles,
+		Actor:  &Actor{Subject: actorID},
+	})
+}
+
+// sign sets the registered claims for subject, valid for ttl, and signs them
+// with the current key of the keyring.
+func (s *tokenService) sign(subject string, ttl time.Duration, claims *JwtToken) (string, error) {
+	key, err := s.keyring.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	jti, err := util.RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.StandardClaims = jwt.StandardClaims{
+		Id:        jti,
+		Subject:   subject,
+		Issuer:    s.cfg.TokenIssuer,
+		Audience:  s.cfg.TokenAudience,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.signingKey)
+}
+
+func (s *tokenService) VerifyAccessToken(accessToken string) (*JwtToken, error) {
+	if accessToken == "" {
+		return nil, errors.New("Access token is empty.")
+	}
+
+	// The claims are validated below, jwt.StandardClaims allows no clock
+	// skew and would take the exp of a legacy token for seconds.
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(accessToken, &JwtToken{},
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return s.keyring.VerificationKey(kid, t.Method.Alg())
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*JwtToken)
+	if !ok {
+		return nil, ErrTokenInvalid
+	}
+	if err := s.validate(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	if claims.ClientID != "" {
+		claims.Scopes = strings.Fields(claims.Scope)
+	}
+	return claims, nil
+}
+
+// validate checks the registered claims, allowing TokenClockSkew between the
+// clocks of the servers. Legacy tokens carry only exp, they are accepted
+// while AcceptLegacyTokens is set and until LegacyTokensUntil.
+func (s *tokenService) validate(claims *JwtToken, now time.Time) error {
+	skew := s.cfg.TokenClockSkew
+
+	if claims.isLegacy() {
+		if !now.Before(s.legacyUntil) {
+			return ErrTokenInvalid
+		}
+	} else {
+		if claims.ExpiresAt == 0 || claims.IssuedAt == 0 {
+			return ErrTokenInvalid
+		}
+		if now.Add(skew).Before(time.Unix(claims.NotBefore, 0)) || now.Add(skew).Before(time.Unix(claims.IssuedAt, 0)) {
+			return ErrTokenInvalid
+		}
+		if claims.Issuer != s.cfg.TokenIssuer || claims.Audience != s.cfg.TokenAudience {
+			return ErrTokenInvalid
+		}
+	}
+
+	if now.Add(-skew).After(claims.ExpiresAtTime()) {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+func (s *tokenService) JWKS() *JSONWebKeySet {
+	return newJSONWebKeySet(s.keyring.PublicKeys())
+}
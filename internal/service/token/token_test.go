@@ -0,0 +1,418 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/nuea/backend-golang-test/internal/config"
+	"github.com/nuea/backend-golang-test/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKey struct {
+	ID        string     `json:"kid"`
+	Algorithm string     `json:"alg"`
+	Private   string     `json:"private_key,omitempty"`
+	Public    string     `json:"public_key,omitempty"`
+	NotBefore time.Time  `json:"not_before"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func writeKeyring(t *testing.T, keys ...testKey) string {
+	dir := t.TempDir()
+	for i, k := range keys {
+		var der []byte
+		var err error
+		switch k.Algorithm {
+		case "RS256":
+			pk, _ := rsa.GenerateKey(rand.Reader, 2048)
+			der, err = x509.MarshalPKCS8PrivateKey(pk)
+		case "EdDSA":
+			_, pk, _ := ed25519.GenerateKey(rand.Reader)
+			der, err = x509.MarshalPKCS8PrivateKey(pk)
+		}
+		require.NoError(t, err)
+
+		keys[i].Private = k.ID + ".pem"
+		pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, keys[i].Private), pemBytes, 0o600))
+	}
+
+	b, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	path := filepath.Join(dir, "keyring.json")
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func newTestService(t *testing.T, cfg config.AuthConfig) *tokenService {
+	cfg.AccessTokenExpireTTL = time.Minute
+	sv, err := ProvideTokenService(&config.AppConfig{Auth: cfg})
+	require.NoError(t, err)
+	return sv.(*tokenService)
+}
+
+func headerOf(t *testing.T, ac string) map[string]interface{} {
+	tk, _, err := new(jwt.Parser).ParseUnverified(ac, &JwtToken{})
+	require.NoError(t, err)
+	return tk.Header
+}
+
+func TestProvideTokenService(t *testing.T) {
+	t.Run("no signing key", func(t *testing.T) {
+		sv, err := ProvideTokenService(&config.AppConfig{})
+
+		assert.Nil(t, sv)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid keyring", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kid":"a","alg":"HS256","private_key":"a.pem"}]}`), 0o600))
+
+		sv, err := ProvideTokenService(&config.AppConfig{Auth: config.AuthConfig{KeyringFile: path}})
+
+		assert.Nil(t, sv)
+		assert.ErrorContains(t, err, "unsupported alg")
+	})
+}
+
+func TestGenerateAndVerifyAccessToken(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	t.Run("RS256", func(t *testing.T) {
+		sv := newTestService(t, config.AuthConfig{KeyringFile: writeKeyring(t, testKey{ID: "rsa-1", Algorithm: "RS256", NotBefore: past})})
+
+		ac, err := sv.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "rsa-1", headerOf(t, ac)["kid"])
+		assert.Equal(t, "RS256", headerOf(t, ac)["alg"])
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Equal(t, "uid", claims.UserID)
+		assert.NotEmpty(t, claims.Id)
+	})
+
+	t.Run("session and roles claims", func(t *testing.T) {
+		sv := newTestService(t, config.AuthConfig{SecretKey: "secret"})
+
+		ac, err := sv.GenerateAccessToken("uid", "sid", []string{"admin"})
+		require.NoError(t, err)
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Equal(t, "sid", claims.SessionID)
+		assert.Equal(t, []string{"admin"}, claims.Roles)
+	})
+
+	t.Run("client token", func(t *testing.T) {
+		sv := newTestService(t, config.AuthConfig{SecretKey: "secret"})
+
+		ac, err := sv.GenerateClientAccessToken("client", []string{"users:read", "users:write"})
+		require.NoError(t, err)
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Empty(t, claims.UserID)
+		assert.Equal(t, "client", claims.ClientID)
+		assert.Equal(t, []string{"users:read", "users:write"}, claims.Scopes)
+		assert.True(t, claims.HasPermission(rbac.PermissionUsersRead))
+		assert.False(t, claims.HasPermission(rbac.PermissionUsersDelete))
+	})
+
+	t.Run("EdDSA", func(t *testing.T) {
+		sv := newTestService(t, config.AuthConfig{KeyringFile: writeKeyring(t, testKey{ID: "ed-1", Algorithm: "EdDSA", NotBefore: past})})
+
+		ac, err := sv.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "EdDSA", headerOf(t, ac)["alg"])
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Equal(t, "uid", claims.UserID)
+	})
+
+	t.Run("legacy shared secret", func(t *testing.T) {
+		sv := newTestService(t, config.AuthConfig{SecretKey: "secret"})
+
+		ac, err := sv.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+		assert.Nil(t, headerOf(t, ac)["kid"])
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Equal(t, "uid", claims.UserID)
+	})
+
+	t.Run("legacy token accepted after switching to keyring", func(t *testing.T) {
+		legacy := newTestService(t, config.AuthConfig{SecretKey: "secret"})
+		ac, err := legacy.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+
+		sv := newTestService(t, config.AuthConfig{
+			SecretKey:   "secret",
+			KeyringFile: writeKeyring(t, testKey{ID: "rsa-1", Algorithm: "RS256", NotBefore: past}),
+		})
+
+		_, err = sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+
+		next, err := sv.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "rsa-1", headerOf(t, next)["kid"])
+	})
+
+	t.Run("unknown kid", func(t *testing.T) {
+		issuer := newTestService(t, config.AuthConfig{KeyringFile: writeKeyring(t, testKey{ID: "rsa-1", Algorithm: "RS256", NotBefore: past})})
+		sv := newTestService(t, config.AuthConfig{KeyringFile: writeKeyring(t, testKey{ID: "rsa-2", Algorithm: "RS256", NotBefore: past})})
+
+		ac, err := issuer.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+
+		_, err = sv.VerifyAccessToken(ac)
+		assert.ErrorContains(t, err, "unknown key")
+	})
+
+	t.Run("algorithm confusion is rejected", func(t *testing.T) {
+		sv := newTestService(t, config.AuthConfig{KeyringFile: writeKeyring(t, testKey{ID: "rsa-1", Algorithm: "RS256", NotBefore: past})})
+
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &JwtToken{UserID: "uid"})
+		forged.Header["kid"] = "rsa-1"
+		ac, err := forged.SignedString([]byte("anything"))
+		require.NoError(t, err)
+
+		_, err = sv.VerifyAccessToken(ac)
+		assert.ErrorContains(t, err, "signing method is invalid")
+	})
+}
+
+func TestKeyRotation(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	path := writeKeyring(t,
+		testKey{ID: "retired", Algorithm: "RS256", NotBefore: now.Add(-48 * time.Hour), ExpiresAt: &expired},
+		testKey{ID: "current", Algorithm: "RS256", NotBefore: now.Add(-24 * time.Hour)},
+		testKey{ID: "next", Algorithm: "EdDSA", NotBefore: now.Add(24 * time.Hour)},
+	)
+	sv := newTestService(t, config.AuthConfig{KeyringFile: path})
+
+	t.Run("signs with the newest active key", func(t *testing.T) {
+		ac, err := sv.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "current", headerOf(t, ac)["kid"])
+	})
+
+	t.Run("publishes current and scheduled keys", func(t *testing.T) {
+		jwks := sv.JWKS()
+
+		kids := make([]string, 0)
+		for _, k := range jwks.Keys {
+			kids = append(kids, k.Kid)
+		}
+		assert.Equal(t, []string{"current", "next"}, kids)
+		assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+		assert.NotEmpty(t, jwks.Keys[0].N)
+		assert.Equal(t, "AQAB", jwks.Keys[0].E)
+		assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+		assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	})
+
+	t.Run("rejects tokens signed with a retired key", func(t *testing.T) {
+		_, err := sv.keyring.VerificationKey("retired", "RS256")
+		assert.ErrorContains(t, err, "unknown key")
+	})
+}
+
+func TestRegisteredClaims(t *testing.T) {
+	cfg := config.AuthConfig{
+		SecretKey:      "secret",
+		TokenIssuer:    "issuer",
+		TokenAudience:  "audience",
+		TokenClockSkew: 30 * time.Second,
+		// The default cutoff of legacy tokens.
+		AccessTokenExpireTTL: 5 * time.Minute,
+	}
+	sign := func(t *testing.T, claims *JwtToken) string {
+		ac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		return ac
+	}
+	now := time.Now()
+	valid := func() *JwtToken {
+		return &JwtToken{
+			StandardClaims: jwt.StandardClaims{
+				Id:        "jti",
+				Subject:   "uid",
+				Issuer:    "issuer",
+				Audience:  "audience",
+				IssuedAt:  now.Unix(),
+				NotBefore: now.Unix(),
+				ExpiresAt: now.Add(time.Minute).Unix(),
+			},
+			UserID: "uid",
+		}
+	}
+
+	t.Run("issued in seconds", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+
+		ac, err := sv.GenerateAccessToken("uid", "", nil)
+		require.NoError(t, err)
+
+		var claims JwtToken
+		_, _, err = new(jwt.Parser).ParseUnverified(ac, &claims)
+		require.NoError(t, err)
+		assert.Equal(t, "uid", claims.Subject)
+		assert.Equal(t, "issuer", claims.Issuer)
+		assert.Equal(t, "audience", claims.Audience)
+		assert.NotEmpty(t, claims.Id)
+		assert.InDelta(t, time.Now().Unix(), claims.IssuedAt, 1)
+		assert.Equal(t, claims.IssuedAt, claims.NotBefore)
+		assert.Equal(t, claims.IssuedAt+60, claims.ExpiresAt)
+		assert.NoError(t, claims.Valid())
+	})
+
+	t.Run("client token subject", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+
+		ac, err := sv.GenerateClientAccessToken("client", []string{"users:read"})
+		require.NoError(t, err)
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Equal(t, "client", claims.Subject)
+	})
+
+	t.Run("expired within the clock skew", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+		claims := valid()
+		claims.ExpiresAt = now.Add(-10 * time.Second).Unix()
+
+		_, err := sv.VerifyAccessToken(sign(t, claims))
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+		claims := valid()
+		claims.ExpiresAt = now.Add(-time.Minute).Unix()
+
+		_, err := sv.VerifyAccessToken(sign(t, claims))
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("not valid yet", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+		claims := valid()
+		claims.NotBefore = now.Add(time.Minute).Unix()
+
+		_, err := sv.VerifyAccessToken(sign(t, claims))
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+	})
+
+	t.Run("other issuer", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+		claims := valid()
+		claims.Issuer = "other"
+
+		_, err := sv.VerifyAccessToken(sign(t, claims))
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+	})
+
+	t.Run("other audience", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+		claims := valid()
+		claims.Audience = "other"
+
+		_, err := sv.VerifyAccessToken(sign(t, claims))
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+	})
+
+	t.Run("legacy token in the migration window", func(t *testing.T) {
+		legacyCfg := cfg
+		legacyCfg.AcceptLegacyTokens = true
+		sv := newTestService(t, legacyCfg)
+		ac := sign(t, &JwtToken{StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: now.Add(time.Minute).UnixMilli()}, UserID: "uid"})
+
+		claims, err := sv.VerifyAccessToken(ac)
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(time.Minute).UnixMilli(), claims.ExpiresAtTime().UnixMilli())
+	})
+
+	t.Run("expired legacy token", func(t *testing.T) {
+		legacyCfg := cfg
+		legacyCfg.AcceptLegacyTokens = true
+		sv := newTestService(t, legacyCfg)
+		ac := sign(t, &JwtToken{StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: now.Add(-time.Minute).UnixMilli()}, UserID: "uid"})
+
+		_, err := sv.VerifyAccessToken(ac)
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("legacy token after the cutoff", func(t *testing.T) {
+		legacyCfg := cfg
+		legacyCfg.AcceptLegacyTokens = true
+		legacyCfg.LegacyTokensUntil = now.Add(-time.Second)
+		sv := newTestService(t, legacyCfg)
+		ac := sign(t, &JwtToken{StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: now.Add(time.Minute).UnixMilli()}, UserID: "uid"})
+
+		_, err := sv.VerifyAccessToken(ac)
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+	})
+
+	t.Run("legacy token after the default cutoff", func(t *testing.T) {
+		legacyCfg := cfg
+		legacyCfg.AcceptLegacyTokens = true
+		sv := newTestService(t, legacyCfg)
+		claims := &JwtToken{StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: now.Add(time.Hour).UnixMilli()}, UserID: "uid"}
+
+		assert.NoError(t, sv.validate(claims, now))
+		assert.ErrorIs(t, sv.validate(claims, now.Add(cfg.AccessTokenExpireTTL+time.Minute)), ErrTokenInvalid)
+	})
+
+	t.Run("legacy token after the migration window", func(t *testing.T) {
+		sv := newTestService(t, cfg)
+		ac := sign(t, &JwtToken{StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: now.Add(time.Minute).UnixMilli()}, UserID: "uid"})
+
+		_, err := sv.VerifyAccessToken(ac)
+		assert.ErrorIs(t, err, ErrTokenInvalid)
+	})
+}
+
+func TestGenerateImpersonationToken(t *testing.T) {
+	sv := newTestService(t, config.AuthConfig{SecretKey: "secret", ImpersonationTTL: 15 * time.Minute})
+
+	ac, err := sv.GenerateImpersonationToken("uid", "admin", []string{"user"})
+	require.NoError(t, err)
+
+	claims, err := sv.VerifyAccessToken(ac)
+	assert.NoError(t, err)
+	assert.Equal(t, "uid", claims.Subject)
+	assert.Equal(t, "uid", claims.UserID)
+	assert.True(t, claims.IsImpersonated())
+	assert.Equal(t, "admin", claims.Actor.Subject)
+	assert.Equal(t, claims.IssuedAt+900, claims.ExpiresAt)
+}
+
+func TestCanManage(t *testing.T) {
+	admin := &JwtToken{UserID: "admin", Roles: []string{"admin"}}
+	support := &JwtToken{UserID: "support", Roles: []string{"support"}}
+	key := &JwtToken{UserID: "admin", Roles: []string{"admin"}, APIKeyID: "key", Scopes: []string{"users:read", "users:write"}}
+
+	assert.True(t, admin.CanManage([]string{"admin"}))
+	assert.True(t, support.CanManage([]string{"user", "support"}))
+	assert.False(t, support.CanManage([]string{"admin"}))
+	assert.True(t, key.CanManage([]string{"support"}))
+	assert.False(t, key.CanManage([]string{"admin"}))
+}
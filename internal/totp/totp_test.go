@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code, unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	t.Run("current period", func(t *testing.T) {
+		step, ok := Validate(rfcSecret, "050471", now)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now), step)
+	})
+
+	t.Run("previous period", func(t *testing.T) {
+		code, _ := Code(rfcSecret, Step(now)-1)
+		step, ok := Validate(rfcSecret, code, now)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now)-1, step)
+	})
+
+	t.Run("outside the skew", func(t *testing.T) {
+		code, _ := Code(rfcSecret, Step(now)-2)
+		_, ok := Validate(rfcSecret, code, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("wrong length", func(t *testing.T) {
+		_, ok := Validate(rfcSecret, "50471", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	uri := URI("backend-golang-test", "test@example.com", secret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/backend-golang-test:test@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+}
@@ -0,0 +1,61 @@
+package util
+
+// Redacted replaces the value of a sensitive field in the logs.
+const Redacted = "[REDACTED]"
+
+// sensitiveFields are the fields never written to the logs, by their proto
+// and JSON name.
+var sensitiveFields = map[string]struct{}{
+	// Raw API keys and OAuth client secrets.
+	"key":           {},
+	"client_secret": {},
+	// The OIDC authorization code and state, which stand for the PKCE
+	// verifier. MFA codes share "code" with them.
+	"code":  {},
+	"state": {},
+	// Tokens, including the password reset token.
+	"access_token":  {},
+	"refresh_token": {},
+	"csrf_token":    {},
+	"mfa_token":     {},
+	"token":         {},
+	// The TOTP secret of an enrollment, and the codes that replace it.
+	"secret":         {},
+	"otpauth_uri":    {},
+	"recovery_codes": {},
+	// Passwords.
+	"password":         {},
+	"current_password": {},
+	"new_password":     {},
+}
+
+// IsSensitiveField reports whether the field must not be logged.
+func IsSensitiveField(name string) bool {
+	_, ok := sensitiveFields[name]
+	return ok
+}
+
+// RedactJSON returns a copy of a decoded JSON value with the sensitive
+// fields replaced, at any depth.
+func RedactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			if IsSensitiveField(k) {
+				out[k] = Redacted
+			} else {
+				out[k] = RedactJSON(e)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = RedactJSON(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
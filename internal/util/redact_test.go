@@ -0,0 +1,38 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactJSON(t *testing.T) {
+	var body any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"access_token": "at",
+		"refresh_token": "rt",
+		"csrf_token": "csrf",
+		"api_key": {"id": "kid", "prefix": "bgt_1234"},
+		"key": "bgt_secret",
+		"client_secret": "cs",
+		"secret": "totp",
+		"otpauth_uri": "otpauth://totp/x?secret=totp",
+		"recovery_codes": ["a", "b"],
+		"data": [{"id": "uid", "mfa_token": "mt"}]
+	}`), &body))
+
+	assert.Equal(t, map[string]any{
+		"access_token":   Redacted,
+		"refresh_token":  Redacted,
+		"csrf_token":     Redacted,
+		"api_key":        map[string]any{"id": "kid", "prefix": "bgt_1234"},
+		"key":            Redacted,
+		"client_secret":  Redacted,
+		"secret":         Redacted,
+		"otpauth_uri":    Redacted,
+		"recovery_codes": Redacted,
+		"data":           []any{map[string]any{"id": "uid", "mfa_token": Redacted}},
+	}, RedactJSON(body))
+}
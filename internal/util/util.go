@@ -1,11 +1,21 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func MapToSlice[I, O any](mapper func(I) (O, error), input []I) ([]O, error) {
@@ -40,3 +50,91 @@ func ValidateStruct(s interface{}) error {
 
 	return nil
 }
+
+func RandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the value persisted for an opaque token, so a leaked
+// collection cannot be replayed against the endpoint that accepts it.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HTTPStatusFromError maps the gRPC status code carried by err to the HTTP
+// status returned by the gateway. Errors without a status are treated as
+// internal errors.
+func HTTPStatusFromError(err error) int {
+	switch status.Code(err) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ErrorWithRetryAfter returns a status error that tells the caller how long
+// to wait before trying again.
+func ErrorWithRetryAfter(c codes.Code, msg string, retryAfter time.Duration) error {
+	st := status.New(c, msg)
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// RetryAfterFromError returns the delay set by ErrorWithRetryAfter.
+func RetryAfterFromError(err error) (time.Duration, bool) {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// FieldViolation describes why a request field was rejected.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// FieldViolationsFromError returns the BadRequest field violations attached
+// to a status error.
+func FieldViolationsFromError(err error) []FieldViolation {
+	var violations []FieldViolation
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				violations = append(violations, FieldViolation{
+					Field:   v.GetField(),
+					Reason:  v.GetReason(),
+					Message: v.GetDescription(),
+				})
+			}
+		}
+	}
+	return violations
+}
@@ -9,6 +9,7 @@ package userv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -218,6 +219,20 @@ type GetUsersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          *string                `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
 	Email         *string                `protobuf:"bytes,2,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	OrderBy       string                 `protobuf:"bytes,5,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	IncludeTotal  bool                   `protobuf:"varint,6,opt,name=include_total,json=includeTotal,proto3" json:"include_total,omitempty"`
+	NamePrefix    *string                `protobuf:"bytes,7,opt,name=name_prefix,json=namePrefix,proto3,oneof" json:"name_prefix,omitempty"`
+	NameContains  *string                `protobuf:"bytes,8,opt,name=name_contains,json=nameContains,proto3,oneof" json:"name_contains,omitempty"`
+	EmailPrefix   *string                `protobuf:"bytes,9,opt,name=email_prefix,json=emailPrefix,proto3,oneof" json:"email_prefix,omitempty"`
+	EmailContains *string                `protobuf:"bytes,10,opt,name=email_contains,json=emailContains,proto3,oneof" json:"email_contains,omitempty"`
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	UpdatedAfter  *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_after,json=updatedAfter,proto3" json:"updated_after,omitempty"`
+	UpdatedBefore *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=updated_before,json=updatedBefore,proto3" json:"updated_before,omitempty"`
+	CreatedBy     *string                `protobuf:"bytes,15,opt,name=created_by,json=createdBy,proto3,oneof" json:"created_by,omitempty"`
+	Q             *string                `protobuf:"bytes,16,opt,name=q,proto3,oneof" json:"q,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -266,9 +281,109 @@ func (x *GetUsersRequest) GetEmail() string {
 	return ""
 }
 
+func (x *GetUsersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetUsersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetIncludeTotal() bool {
+	if x != nil {
+		return x.IncludeTotal
+	}
+	return false
+}
+
+func (x *GetUsersRequest) GetNamePrefix() string {
+	if x != nil && x.NamePrefix != nil {
+		return *x.NamePrefix
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetNameContains() string {
+	if x != nil && x.NameContains != nil {
+		return *x.NameContains
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetEmailPrefix() string {
+	if x != nil && x.EmailPrefix != nil {
+		return *x.EmailPrefix
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetEmailContains() string {
+	if x != nil && x.EmailContains != nil {
+		return *x.EmailContains
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *GetUsersRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *GetUsersRequest) GetUpdatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAfter
+	}
+	return nil
+}
+
+func (x *GetUsersRequest) GetUpdatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedBefore
+	}
+	return nil
+}
+
+func (x *GetUsersRequest) GetCreatedBy() string {
+	if x != nil && x.CreatedBy != nil {
+		return *x.CreatedBy
+	}
+	return ""
+}
+
+func (x *GetUsersRequest) GetQ() string {
+	if x != nil && x.Q != nil {
+		return *x.Q
+	}
+	return ""
+}
+
 type GetUsersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Data          []*User                `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	Total         *int64                 `protobuf:"varint,4,opt,name=total,proto3,oneof" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -310,11 +425,27 @@ func (x *GetUsersResponse) GetData() []*User {
 	return nil
 }
 
+func (x *GetUsersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *GetUsersResponse) GetTotal() int64 {
+	if x != nil && x.Total != nil {
+		return *x.Total
+	}
+	return 0
+}
+
 type UpdateUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
 	Email         *string                `protobuf:"bytes,3,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	Etag          string                 `protobuf:"bytes,5,opt,name=etag,proto3" json:"etag,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -370,8 +501,23 @@ func (x *UpdateUserRequest) GetEmail() string {
 	return ""
 }
 
+func (x *UpdateUserRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateUserRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
 type UpdateUserResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Etag          string                 `protobuf:"bytes,1,opt,name=etag,proto3" json:"etag,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -406,9 +552,17 @@ func (*UpdateUserResponse) Descriptor() ([]byte, []int) {
 	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{7}
 }
 
+func (x *UpdateUserResponse) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
 type DeleteUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Etag          string                 `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -450,6 +604,13 @@ func (x *DeleteUserRequest) GetId() string {
 	return ""
 }
 
+func (x *DeleteUserRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
 type DeleteUserResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -486,33 +647,27 @@ func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
 	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{9}
 }
 
-type User struct {
+type VerifyEmailRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
-	CreatedBy     *string                `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3,oneof" json:"created_by,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3,oneof" json:"deleted_at,omitempty"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *User) Reset() {
-	*x = User{}
+func (x *VerifyEmailRequest) Reset() {
+	*x = VerifyEmailRequest{}
 	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *User) String() string {
+func (x *VerifyEmailRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*User) ProtoMessage() {}
+func (*VerifyEmailRequest) ProtoMessage() {}
 
-func (x *User) ProtoReflect() protoreflect.Message {
+func (x *VerifyEmailRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -524,94 +679,903 @@ func (x *User) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use User.ProtoReflect.Descriptor instead.
-func (*User) Descriptor() ([]byte, []int) {
+// Deprecated: Use VerifyEmailRequest.ProtoReflect.Descriptor instead.
+func (*VerifyEmailRequest) Descriptor() ([]byte, []int) {
 	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *User) GetId() string {
+func (x *VerifyEmailRequest) GetToken() string {
 	if x != nil {
-		return x.Id
+		return x.Token
 	}
 	return ""
 }
 
-func (x *User) GetName() string {
+type VerifyEmailResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyEmailResponse) Reset() {
+	*x = VerifyEmailResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyEmailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEmailResponse) ProtoMessage() {}
+
+func (x *VerifyEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[11]
 	if x != nil {
-		return x.Name
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *User) GetEmail() string {
+// Deprecated: Use VerifyEmailResponse.ProtoReflect.Descriptor instead.
+func (*VerifyEmailResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{11}
+}
+
+type ResendVerificationEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResendVerificationEmailRequest) Reset() {
+	*x = ResendVerificationEmailRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResendVerificationEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResendVerificationEmailRequest) ProtoMessage() {}
+
+func (x *ResendVerificationEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResendVerificationEmailRequest.ProtoReflect.Descriptor instead.
+func (*ResendVerificationEmailRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ResendVerificationEmailRequest) GetEmail() string {
 	if x != nil {
 		return x.Email
 	}
 	return ""
 }
 
-func (x *User) GetCreatedBy() string {
-	if x != nil && x.CreatedBy != nil {
-		return *x.CreatedBy
+type ResendVerificationEmailResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResendVerificationEmailResponse) Reset() {
+	*x = ResendVerificationEmailResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResendVerificationEmailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResendVerificationEmailResponse) ProtoMessage() {}
+
+func (x *ResendVerificationEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+// Deprecated: Use ResendVerificationEmailResponse.ProtoReflect.Descriptor instead.
+func (*ResendVerificationEmailResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{13}
+}
+
+type GetCurrentUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentUserRequest) Reset() {
+	*x = GetCurrentUserRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentUserRequest) ProtoMessage() {}
+
+func (x *GetCurrentUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[14]
 	if x != nil {
-		return x.CreatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+// Deprecated: Use GetCurrentUserRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentUserRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{14}
+}
+
+type GetCurrentUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentUserResponse) Reset() {
+	*x = GetCurrentUserResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentUserResponse) ProtoMessage() {}
+
+func (x *GetCurrentUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[15]
 	if x != nil {
-		return x.UpdatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *User) GetDeletedAt() *timestamppb.Timestamp {
+// Deprecated: Use GetCurrentUserResponse.ProtoReflect.Descriptor instead.
+func (*GetCurrentUserResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetCurrentUserResponse) GetUser() *User {
 	if x != nil {
-		return x.DeletedAt
+		return x.User
 	}
 	return nil
 }
 
-var File_backend_golang_test_user_v1_user_proto protoreflect.FileDescriptor
+type UpdateCurrentUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Email         *string                `protobuf:"bytes,2,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	Etag          string                 `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_backend_golang_test_user_v1_user_proto_rawDesc = "" +
-	"\n" +
-	"&backend_golang_test/user/v1/user.proto\x12\x1bbackend_golang_test.user.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8c\x01\n" +
-	"\x11CreateUserRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x14\n" +
-	"\x05email\x18\x03 \x01(\tR\x05email\x12\"\n" +
-	"\n" +
-	"created_by\x18\x04 \x01(\tH\x00R\tcreatedBy\x88\x01\x01B\r\n" +
-	"\v_created_by\"\x14\n" +
-	"\x12CreateUserResponse\" \n" +
-	"\x0eGetUserRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
-	"\x0fGetUserResponse\x125\n" +
-	"\x04user\x18\x01 \x01(\v2!.backend_golang_test.user.v1.UserR\x04user\"X\n" +
-	"\x0fGetUsersRequest\x12\x17\n" +
-	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
-	"\x05email\x18\x02 \x01(\tH\x01R\x05email\x88\x01\x01B\a\n" +
-	"\x05_nameB\b\n" +
-	"\x06_email\"I\n" +
+func (x *UpdateCurrentUserRequest) Reset() {
+	*x = UpdateCurrentUserRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCurrentUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCurrentUserRequest) ProtoMessage() {}
+
+func (x *UpdateCurrentUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCurrentUserRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCurrentUserRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UpdateCurrentUserRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateCurrentUserRequest) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *UpdateCurrentUserRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateCurrentUserRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type UpdateCurrentUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Etag          string                 `protobuf:"bytes,1,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCurrentUserResponse) Reset() {
+	*x = UpdateCurrentUserResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCurrentUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCurrentUserResponse) ProtoMessage() {}
+
+func (x *UpdateCurrentUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCurrentUserResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCurrentUserResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UpdateCurrentUserResponse) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type DeleteCurrentUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Etag          string                 `protobuf:"bytes,1,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCurrentUserRequest) Reset() {
+	*x = DeleteCurrentUserRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCurrentUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCurrentUserRequest) ProtoMessage() {}
+
+func (x *DeleteCurrentUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCurrentUserRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCurrentUserRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteCurrentUserRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type DeleteCurrentUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCurrentUserResponse) Reset() {
+	*x = DeleteCurrentUserResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCurrentUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCurrentUserResponse) ProtoMessage() {}
+
+func (x *DeleteCurrentUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCurrentUserResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCurrentUserResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{19}
+}
+
+type GrantRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantRoleRequest) Reset() {
+	*x = GrantRoleRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantRoleRequest) ProtoMessage() {}
+
+func (x *GrantRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantRoleRequest.ProtoReflect.Descriptor instead.
+func (*GrantRoleRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GrantRoleRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GrantRoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type GrantRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []string               `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantRoleResponse) Reset() {
+	*x = GrantRoleResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantRoleResponse) ProtoMessage() {}
+
+func (x *GrantRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantRoleResponse.ProtoReflect.Descriptor instead.
+func (*GrantRoleResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GrantRoleResponse) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type RevokeRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRoleRequest) Reset() {
+	*x = RevokeRoleRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRoleRequest) ProtoMessage() {}
+
+func (x *RevokeRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRoleRequest.ProtoReflect.Descriptor instead.
+func (*RevokeRoleRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RevokeRoleRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RevokeRoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type RevokeRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []string               `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeRoleResponse) Reset() {
+	*x = RevokeRoleResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeRoleResponse) ProtoMessage() {}
+
+func (x *RevokeRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeRoleResponse.ProtoReflect.Descriptor instead.
+func (*RevokeRoleResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RevokeRoleResponse) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type UnlockUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockUserRequest) Reset() {
+	*x = UnlockUserRequest{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockUserRequest) ProtoMessage() {}
+
+func (x *UnlockUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockUserRequest.ProtoReflect.Descriptor instead.
+func (*UnlockUserRequest) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UnlockUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type UnlockUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockUserResponse) Reset() {
+	*x = UnlockUserResponse{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockUserResponse) ProtoMessage() {}
+
+func (x *UnlockUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockUserResponse.ProtoReflect.Descriptor instead.
+func (*UnlockUserResponse) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{25}
+}
+
+type User struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email           string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	CreatedBy       *string                `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3,oneof" json:"created_by,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=deleted_at,json=deletedAt,proto3,oneof" json:"deleted_at,omitempty"`
+	Roles           []string               `protobuf:"bytes,8,rep,name=roles,proto3" json:"roles,omitempty"`
+	EmailVerifiedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=email_verified_at,json=emailVerifiedAt,proto3,oneof" json:"email_verified_at,omitempty"`
+	MfaEnabled      bool                   `protobuf:"varint,10,opt,name=mfa_enabled,json=mfaEnabled,proto3" json:"mfa_enabled,omitempty"`
+	Etag            string                 `protobuf:"bytes,11,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_golang_test_user_v1_user_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_backend_golang_test_user_v1_user_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetCreatedBy() string {
+	if x != nil && x.CreatedBy != nil {
+		return *x.CreatedBy
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *User) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *User) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *User) GetEmailVerifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EmailVerifiedAt
+	}
+	return nil
+}
+
+func (x *User) GetMfaEnabled() bool {
+	if x != nil {
+		return x.MfaEnabled
+	}
+	return false
+}
+
+func (x *User) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+var File_backend_golang_test_user_v1_user_proto protoreflect.FileDescriptor
+
+const file_backend_golang_test_user_v1_user_proto_rawDesc = "" +
+	"\n" +
+	"&backend_golang_test/user/v1/user.proto\x12\x1bbackend_golang_test.user.v1\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8c\x01\n" +
+	"\x11CreateUserRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\"\n" +
+	"\n" +
+	"created_by\x18\x04 \x01(\tH\x00R\tcreatedBy\x88\x01\x01B\r\n" +
+	"\v_created_by\"\x14\n" +
+	"\x12CreateUserResponse\" \n" +
+	"\x0eGetUserRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
+	"\x0fGetUserResponse\x125\n" +
+	"\x04user\x18\x01 \x01(\v2!.backend_golang_test.user.v1.UserR\x04user\"\x92\x06\n" +
+	"\x0fGetUsersRequest\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
+	"\x05email\x18\x02 \x01(\tH\x01R\x05email\x88\x01\x01\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x19\n" +
+	"\border_by\x18\x05 \x01(\tR\aorderBy\x12#\n" +
+	"\rinclude_total\x18\x06 \x01(\bR\fincludeTotal\x12$\n" +
+	"\vname_prefix\x18\a \x01(\tH\x02R\n" +
+	"namePrefix\x88\x01\x01\x12(\n" +
+	"\rname_contains\x18\b \x01(\tH\x03R\fnameContains\x88\x01\x01\x12&\n" +
+	"\femail_prefix\x18\t \x01(\tH\x04R\vemailPrefix\x88\x01\x01\x12*\n" +
+	"\x0eemail_contains\x18\n" +
+	" \x01(\tH\x05R\remailContains\x88\x01\x01\x12?\n" +
+	"\rcreated_after\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12?\n" +
+	"\rupdated_after\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\fupdatedAfter\x12A\n" +
+	"\x0eupdated_before\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\rupdatedBefore\x12\"\n" +
+	"\n" +
+	"created_by\x18\x0f \x01(\tH\x06R\tcreatedBy\x88\x01\x01\x12\x11\n" +
+	"\x01q\x18\x10 \x01(\tH\aR\x01q\x88\x01\x01B\a\n" +
+	"\x05_nameB\b\n" +
+	"\x06_emailB\x0e\n" +
+	"\f_name_prefixB\x10\n" +
+	"\x0e_name_containsB\x0f\n" +
+	"\r_email_prefixB\x11\n" +
+	"\x0f_email_containsB\r\n" +
+	"\v_created_byB\x04\n" +
+	"\x02_q\"\x96\x01\n" +
 	"\x10GetUsersResponse\x125\n" +
-	"\x04data\x18\x02 \x03(\v2!.backend_golang_test.user.v1.UserR\x04data\"j\n" +
+	"\x04data\x18\x02 \x03(\v2!.backend_golang_test.user.v1.UserR\x04data\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\x12\x19\n" +
+	"\x05total\x18\x04 \x01(\x03H\x00R\x05total\x88\x01\x01B\b\n" +
+	"\x06_total\"\xbb\x01\n" +
 	"\x11UpdateUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
-	"\x05email\x18\x03 \x01(\tH\x01R\x05email\x88\x01\x01B\a\n" +
+	"\x05email\x18\x03 \x01(\tH\x01R\x05email\x88\x01\x01\x12;\n" +
+	"\vupdate_mask\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x12\n" +
+	"\x04etag\x18\x05 \x01(\tR\x04etagB\a\n" +
 	"\x05_nameB\b\n" +
-	"\x06_email\"\x14\n" +
-	"\x12UpdateUserResponse\"#\n" +
+	"\x06_email\"(\n" +
+	"\x12UpdateUserResponse\x12\x12\n" +
+	"\x04etag\x18\x01 \x01(\tR\x04etag\"7\n" +
 	"\x11DeleteUserRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"\x14\n" +
-	"\x12DeleteUserResponse\"\xb8\x02\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04etag\x18\x02 \x01(\tR\x04etag\"\x14\n" +
+	"\x12DeleteUserResponse\"*\n" +
+	"\x12VerifyEmailRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x15\n" +
+	"\x13VerifyEmailResponse\"6\n" +
+	"\x1eResendVerificationEmailRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"!\n" +
+	"\x1fResendVerificationEmailResponse\"\x17\n" +
+	"\x15GetCurrentUserRequest\"O\n" +
+	"\x16GetCurrentUserResponse\x125\n" +
+	"\x04user\x18\x01 \x01(\v2!.backend_golang_test.user.v1.UserR\x04user\"\xb2\x01\n" +
+	"\x18UpdateCurrentUserRequest\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
+	"\x05email\x18\x02 \x01(\tH\x01R\x05email\x88\x01\x01\x12;\n" +
+	"\vupdate_mask\x18\x03 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x12\n" +
+	"\x04etag\x18\x04 \x01(\tR\x04etagB\a\n" +
+	"\x05_nameB\b\n" +
+	"\x06_email\"/\n" +
+	"\x19UpdateCurrentUserResponse\x12\x12\n" +
+	"\x04etag\x18\x01 \x01(\tR\x04etag\".\n" +
+	"\x18DeleteCurrentUserRequest\x12\x12\n" +
+	"\x04etag\x18\x01 \x01(\tR\x04etag\"\x1b\n" +
+	"\x19DeleteCurrentUserResponse\"?\n" +
+	"\x10GrantRoleRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\")\n" +
+	"\x11GrantRoleResponse\x12\x14\n" +
+	"\x05roles\x18\x01 \x03(\tR\x05roles\"@\n" +
+	"\x11RevokeRoleRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"*\n" +
+	"\x12RevokeRoleResponse\x12\x14\n" +
+	"\x05roles\x18\x01 \x03(\tR\x05roles\",\n" +
+	"\x11UnlockUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x14\n" +
+	"\x12UnlockUserResponse\"\xe6\x03\n" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -623,9 +1587,16 @@ const file_backend_golang_test_user_v1_user_proto_rawDesc = "" +
 	"\n" +
 	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12>\n" +
 	"\n" +
-	"deleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampH\x01R\tdeletedAt\x88\x01\x01B\r\n" +
+	"deleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampH\x01R\tdeletedAt\x88\x01\x01\x12\x14\n" +
+	"\x05roles\x18\b \x03(\tR\x05roles\x12K\n" +
+	"\x11email_verified_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampH\x02R\x0femailVerifiedAt\x88\x01\x01\x12\x1f\n" +
+	"\vmfa_enabled\x18\n" +
+	" \x01(\bR\n" +
+	"mfaEnabled\x12\x12\n" +
+	"\x04etag\x18\v \x01(\tR\x04etagB\r\n" +
 	"\v_created_byB\r\n" +
-	"\v_deleted_at2\xa9\x04\n" +
+	"\v_deleted_atB\x14\n" +
+	"\x12_email_verified_at2\x81\f\n" +
 	"\vUserService\x12m\n" +
 	"\n" +
 	"CreateUser\x12..backend_golang_test.user.v1.CreateUserRequest\x1a/.backend_golang_test.user.v1.CreateUserResponse\x12d\n" +
@@ -634,7 +1605,17 @@ const file_backend_golang_test_user_v1_user_proto_rawDesc = "" +
 	"\n" +
 	"UpdateUser\x12..backend_golang_test.user.v1.UpdateUserRequest\x1a/.backend_golang_test.user.v1.UpdateUserResponse\x12m\n" +
 	"\n" +
-	"DeleteUser\x12..backend_golang_test.user.v1.DeleteUserRequest\x1a/.backend_golang_test.user.v1.DeleteUserResponseB\x84\x02\n" +
+	"DeleteUser\x12..backend_golang_test.user.v1.DeleteUserRequest\x1a/.backend_golang_test.user.v1.DeleteUserResponse\x12p\n" +
+	"\vVerifyEmail\x12/.backend_golang_test.user.v1.VerifyEmailRequest\x1a0.backend_golang_test.user.v1.VerifyEmailResponse\x12\x94\x01\n" +
+	"\x17ResendVerificationEmail\x12;.backend_golang_test.user.v1.ResendVerificationEmailRequest\x1a<.backend_golang_test.user.v1.ResendVerificationEmailResponse\x12y\n" +
+	"\x0eGetCurrentUser\x122.backend_golang_test.user.v1.GetCurrentUserRequest\x1a3.backend_golang_test.user.v1.GetCurrentUserResponse\x12\x82\x01\n" +
+	"\x11UpdateCurrentUser\x125.backend_golang_test.user.v1.UpdateCurrentUserRequest\x1a6.backend_golang_test.user.v1.UpdateCurrentUserResponse\x12\x82\x01\n" +
+	"\x11DeleteCurrentUser\x125.backend_golang_test.user.v1.DeleteCurrentUserRequest\x1a6.backend_golang_test.user.v1.DeleteCurrentUserResponse\x12j\n" +
+	"\tGrantRole\x12-.backend_golang_test.user.v1.GrantRoleRequest\x1a..backend_golang_test.user.v1.GrantRoleResponse\x12m\n" +
+	"\n" +
+	"RevokeRole\x12..backend_golang_test.user.v1.RevokeRoleRequest\x1a/.backend_golang_test.user.v1.RevokeRoleResponse\x12m\n" +
+	"\n" +
+	"UnlockUser\x12..backend_golang_test.user.v1.UnlockUserRequest\x1a/.backend_golang_test.user.v1.UnlockUserResponseB\x84\x02\n" +
 	"\x1fcom.backend_golang_test.user.v1B\tUserProtoP\x01ZPgithub.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1;userv1\xa2\x02\x03BUX\xaa\x02\x19BackendGolangTest.User.V1\xca\x02\x19BackendGolangTest\\User\\V1\xe2\x02%BackendGolangTest\\User\\V1\\GPBMetadata\xea\x02\x1bBackendGolangTest::User::V1b\x06proto3"
 
 var (
@@ -649,42 +1630,83 @@ func file_backend_golang_test_user_v1_user_proto_rawDescGZIP() []byte {
 	return file_backend_golang_test_user_v1_user_proto_rawDescData
 }
 
-var file_backend_golang_test_user_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_backend_golang_test_user_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_backend_golang_test_user_v1_user_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),     // 0: backend_golang_test.user.v1.CreateUserRequest
-	(*CreateUserResponse)(nil),    // 1: backend_golang_test.user.v1.CreateUserResponse
-	(*GetUserRequest)(nil),        // 2: backend_golang_test.user.v1.GetUserRequest
-	(*GetUserResponse)(nil),       // 3: backend_golang_test.user.v1.GetUserResponse
-	(*GetUsersRequest)(nil),       // 4: backend_golang_test.user.v1.GetUsersRequest
-	(*GetUsersResponse)(nil),      // 5: backend_golang_test.user.v1.GetUsersResponse
-	(*UpdateUserRequest)(nil),     // 6: backend_golang_test.user.v1.UpdateUserRequest
-	(*UpdateUserResponse)(nil),    // 7: backend_golang_test.user.v1.UpdateUserResponse
-	(*DeleteUserRequest)(nil),     // 8: backend_golang_test.user.v1.DeleteUserRequest
-	(*DeleteUserResponse)(nil),    // 9: backend_golang_test.user.v1.DeleteUserResponse
-	(*User)(nil),                  // 10: backend_golang_test.user.v1.User
-	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*CreateUserRequest)(nil),               // 0: backend_golang_test.user.v1.CreateUserRequest
+	(*CreateUserResponse)(nil),              // 1: backend_golang_test.user.v1.CreateUserResponse
+	(*GetUserRequest)(nil),                  // 2: backend_golang_test.user.v1.GetUserRequest
+	(*GetUserResponse)(nil),                 // 3: backend_golang_test.user.v1.GetUserResponse
+	(*GetUsersRequest)(nil),                 // 4: backend_golang_test.user.v1.GetUsersRequest
+	(*GetUsersResponse)(nil),                // 5: backend_golang_test.user.v1.GetUsersResponse
+	(*UpdateUserRequest)(nil),               // 6: backend_golang_test.user.v1.UpdateUserRequest
+	(*UpdateUserResponse)(nil),              // 7: backend_golang_test.user.v1.UpdateUserResponse
+	(*DeleteUserRequest)(nil),               // 8: backend_golang_test.user.v1.DeleteUserRequest
+	(*DeleteUserResponse)(nil),              // 9: backend_golang_test.user.v1.DeleteUserResponse
+	(*VerifyEmailRequest)(nil),              // 10: backend_golang_test.user.v1.VerifyEmailRequest
+	(*VerifyEmailResponse)(nil),             // 11: backend_golang_test.user.v1.VerifyEmailResponse
+	(*ResendVerificationEmailRequest)(nil),  // 12: backend_golang_test.user.v1.ResendVerificationEmailRequest
+	(*ResendVerificationEmailResponse)(nil), // 13: backend_golang_test.user.v1.ResendVerificationEmailResponse
+	(*GetCurrentUserRequest)(nil),           // 14: backend_golang_test.user.v1.GetCurrentUserRequest
+	(*GetCurrentUserResponse)(nil),          // 15: backend_golang_test.user.v1.GetCurrentUserResponse
+	(*UpdateCurrentUserRequest)(nil),        // 16: backend_golang_test.user.v1.UpdateCurrentUserRequest
+	(*UpdateCurrentUserResponse)(nil),       // 17: backend_golang_test.user.v1.UpdateCurrentUserResponse
+	(*DeleteCurrentUserRequest)(nil),        // 18: backend_golang_test.user.v1.DeleteCurrentUserRequest
+	(*DeleteCurrentUserResponse)(nil),       // 19: backend_golang_test.user.v1.DeleteCurrentUserResponse
+	(*GrantRoleRequest)(nil),                // 20: backend_golang_test.user.v1.GrantRoleRequest
+	(*GrantRoleResponse)(nil),               // 21: backend_golang_test.user.v1.GrantRoleResponse
+	(*RevokeRoleRequest)(nil),               // 22: backend_golang_test.user.v1.RevokeRoleRequest
+	(*RevokeRoleResponse)(nil),              // 23: backend_golang_test.user.v1.RevokeRoleResponse
+	(*UnlockUserRequest)(nil),               // 24: backend_golang_test.user.v1.UnlockUserRequest
+	(*UnlockUserResponse)(nil),              // 25: backend_golang_test.user.v1.UnlockUserResponse
+	(*User)(nil),                            // 26: backend_golang_test.user.v1.User
+	(*timestamppb.Timestamp)(nil),           // 27: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),           // 28: google.protobuf.FieldMask
 }
 var file_backend_golang_test_user_v1_user_proto_depIdxs = []int32{
-	10, // 0: backend_golang_test.user.v1.GetUserResponse.user:type_name -> backend_golang_test.user.v1.User
-	10, // 1: backend_golang_test.user.v1.GetUsersResponse.data:type_name -> backend_golang_test.user.v1.User
-	11, // 2: backend_golang_test.user.v1.User.created_at:type_name -> google.protobuf.Timestamp
-	11, // 3: backend_golang_test.user.v1.User.updated_at:type_name -> google.protobuf.Timestamp
-	11, // 4: backend_golang_test.user.v1.User.deleted_at:type_name -> google.protobuf.Timestamp
-	0,  // 5: backend_golang_test.user.v1.UserService.CreateUser:input_type -> backend_golang_test.user.v1.CreateUserRequest
-	2,  // 6: backend_golang_test.user.v1.UserService.GetUser:input_type -> backend_golang_test.user.v1.GetUserRequest
-	4,  // 7: backend_golang_test.user.v1.UserService.GetUsers:input_type -> backend_golang_test.user.v1.GetUsersRequest
-	6,  // 8: backend_golang_test.user.v1.UserService.UpdateUser:input_type -> backend_golang_test.user.v1.UpdateUserRequest
-	8,  // 9: backend_golang_test.user.v1.UserService.DeleteUser:input_type -> backend_golang_test.user.v1.DeleteUserRequest
-	1,  // 10: backend_golang_test.user.v1.UserService.CreateUser:output_type -> backend_golang_test.user.v1.CreateUserResponse
-	3,  // 11: backend_golang_test.user.v1.UserService.GetUser:output_type -> backend_golang_test.user.v1.GetUserResponse
-	5,  // 12: backend_golang_test.user.v1.UserService.GetUsers:output_type -> backend_golang_test.user.v1.GetUsersResponse
-	7,  // 13: backend_golang_test.user.v1.UserService.UpdateUser:output_type -> backend_golang_test.user.v1.UpdateUserResponse
-	9,  // 14: backend_golang_test.user.v1.UserService.DeleteUser:output_type -> backend_golang_test.user.v1.DeleteUserResponse
-	10, // [10:15] is the sub-list for method output_type
-	5,  // [5:10] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	26, // 0: backend_golang_test.user.v1.GetUserResponse.user:type_name -> backend_golang_test.user.v1.User
+	27, // 1: backend_golang_test.user.v1.GetUsersRequest.created_after:type_name -> google.protobuf.Timestamp
+	27, // 2: backend_golang_test.user.v1.GetUsersRequest.created_before:type_name -> google.protobuf.Timestamp
+	27, // 3: backend_golang_test.user.v1.GetUsersRequest.updated_after:type_name -> google.protobuf.Timestamp
+	27, // 4: backend_golang_test.user.v1.GetUsersRequest.updated_before:type_name -> google.protobuf.Timestamp
+	26, // 5: backend_golang_test.user.v1.GetUsersResponse.data:type_name -> backend_golang_test.user.v1.User
+	28, // 6: backend_golang_test.user.v1.UpdateUserRequest.update_mask:type_name -> google.protobuf.FieldMask
+	26, // 7: backend_golang_test.user.v1.GetCurrentUserResponse.user:type_name -> backend_golang_test.user.v1.User
+	28, // 8: backend_golang_test.user.v1.UpdateCurrentUserRequest.update_mask:type_name -> google.protobuf.FieldMask
+	27, // 9: backend_golang_test.user.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	27, // 10: backend_golang_test.user.v1.User.updated_at:type_name -> google.protobuf.Timestamp
+	27, // 11: backend_golang_test.user.v1.User.deleted_at:type_name -> google.protobuf.Timestamp
+	27, // 12: backend_golang_test.user.v1.User.email_verified_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: backend_golang_test.user.v1.UserService.CreateUser:input_type -> backend_golang_test.user.v1.CreateUserRequest
+	2,  // 14: backend_golang_test.user.v1.UserService.GetUser:input_type -> backend_golang_test.user.v1.GetUserRequest
+	4,  // 15: backend_golang_test.user.v1.UserService.GetUsers:input_type -> backend_golang_test.user.v1.GetUsersRequest
+	6,  // 16: backend_golang_test.user.v1.UserService.UpdateUser:input_type -> backend_golang_test.user.v1.UpdateUserRequest
+	8,  // 17: backend_golang_test.user.v1.UserService.DeleteUser:input_type -> backend_golang_test.user.v1.DeleteUserRequest
+	10, // 18: backend_golang_test.user.v1.UserService.VerifyEmail:input_type -> backend_golang_test.user.v1.VerifyEmailRequest
+	12, // 19: backend_golang_test.user.v1.UserService.ResendVerificationEmail:input_type -> backend_golang_test.user.v1.ResendVerificationEmailRequest
+	14, // 20: backend_golang_test.user.v1.UserService.GetCurrentUser:input_type -> backend_golang_test.user.v1.GetCurrentUserRequest
+	16, // 21: backend_golang_test.user.v1.UserService.UpdateCurrentUser:input_type -> backend_golang_test.user.v1.UpdateCurrentUserRequest
+	18, // 22: backend_golang_test.user.v1.UserService.DeleteCurrentUser:input_type -> backend_golang_test.user.v1.DeleteCurrentUserRequest
+	20, // 23: backend_golang_test.user.v1.UserService.GrantRole:input_type -> backend_golang_test.user.v1.GrantRoleRequest
+	22, // 24: backend_golang_test.user.v1.UserService.RevokeRole:input_type -> backend_golang_test.user.v1.RevokeRoleRequest
+	24, // 25: backend_golang_test.user.v1.UserService.UnlockUser:input_type -> backend_golang_test.user.v1.UnlockUserRequest
+	1,  // 26: backend_golang_test.user.v1.UserService.CreateUser:output_type -> backend_golang_test.user.v1.CreateUserResponse
+	3,  // 27: backend_golang_test.user.v1.UserService.GetUser:output_type -> backend_golang_test.user.v1.GetUserResponse
+	5,  // 28: backend_golang_test.user.v1.UserService.GetUsers:output_type -> backend_golang_test.user.v1.GetUsersResponse
+	7,  // 29: backend_golang_test.user.v1.UserService.UpdateUser:output_type -> backend_golang_test.user.v1.UpdateUserResponse
+	9,  // 30: backend_golang_test.user.v1.UserService.DeleteUser:output_type -> backend_golang_test.user.v1.DeleteUserResponse
+	11, // 31: backend_golang_test.user.v1.UserService.VerifyEmail:output_type -> backend_golang_test.user.v1.VerifyEmailResponse
+	13, // 32: backend_golang_test.user.v1.UserService.ResendVerificationEmail:output_type -> backend_golang_test.user.v1.ResendVerificationEmailResponse
+	15, // 33: backend_golang_test.user.v1.UserService.GetCurrentUser:output_type -> backend_golang_test.user.v1.GetCurrentUserResponse
+	17, // 34: backend_golang_test.user.v1.UserService.UpdateCurrentUser:output_type -> backend_golang_test.user.v1.UpdateCurrentUserResponse
+	19, // 35: backend_golang_test.user.v1.UserService.DeleteCurrentUser:output_type -> backend_golang_test.user.v1.DeleteCurrentUserResponse
+	21, // 36: backend_golang_test.user.v1.UserService.GrantRole:output_type -> backend_golang_test.user.v1.GrantRoleResponse
+	23, // 37: backend_golang_test.user.v1.UserService.RevokeRole:output_type -> backend_golang_test.user.v1.RevokeRoleResponse
+	25, // 38: backend_golang_test.user.v1.UserService.UnlockUser:output_type -> backend_golang_test.user.v1.UnlockUserResponse
+	26, // [26:39] is the sub-list for method output_type
+	13, // [13:26] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_backend_golang_test_user_v1_user_proto_init() }
@@ -694,15 +1716,17 @@ func file_backend_golang_test_user_v1_user_proto_init() {
 	}
 	file_backend_golang_test_user_v1_user_proto_msgTypes[0].OneofWrappers = []any{}
 	file_backend_golang_test_user_v1_user_proto_msgTypes[4].OneofWrappers = []any{}
+	file_backend_golang_test_user_v1_user_proto_msgTypes[5].OneofWrappers = []any{}
 	file_backend_golang_test_user_v1_user_proto_msgTypes[6].OneofWrappers = []any{}
-	file_backend_golang_test_user_v1_user_proto_msgTypes[10].OneofWrappers = []any{}
+	file_backend_golang_test_user_v1_user_proto_msgTypes[16].OneofWrappers = []any{}
+	file_backend_golang_test_user_v1_user_proto_msgTypes[26].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_backend_golang_test_user_v1_user_proto_rawDesc), len(file_backend_golang_test_user_v1_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
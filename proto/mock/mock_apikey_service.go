@@ -0,0 +1,492 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: proto/gen/backend_golang_test/user/v1/apikey_grpc.pb.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	userv1 "github.com/nuea/backend-golang-test/proto/gen/backend_golang_test/user/v1"
+	grpc "google.golang.org/grpc"
+)
+
+// MockAPIKeyServiceClient is a mock of APIKeyServiceClient interface.
+type MockAPIKeyServiceClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyServiceClientMockRecorder
+}
+
+// MockAPIKeyServiceClientMockRecorder is the mock recorder for MockAPIKeyServiceClient.
+type MockAPIKeyServiceClientMockRecorder struct {
+	mock *MockAPIKeyServiceClient
+}
+
+// NewMockAPIKeyServiceClient creates a new mock instance.
+func NewMockAPIKeyServiceClient(ctrl *gomock.Controller) *MockAPIKeyServiceClient {
+	mock := &MockAPIKeyServiceClient{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyServiceClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyServiceClient) EXPECT() *MockAPIKeyServiceClientMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyServiceClient) CreateAPIKey(ctx context.Context, in *userv1.CreateAPIKeyRequest, opts ...grpc.CallOption) (*userv1.CreateAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateAPIKey", varargs...)
+	ret0, _ := ret[0].(*userv1.CreateAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyServiceClientMockRecorder) CreateAPIKey(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).CreateAPIKey), varargs...)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockAPIKeyServiceClient) CreateOAuthClient(ctx context.Context, in *userv1.CreateOAuthClientRequest, opts ...grpc.CallOption) (*userv1.CreateOAuthClientResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateOAuthClient", varargs...)
+	ret0, _ := ret[0].(*userv1.CreateOAuthClientResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockAPIKeyServiceClientMockRecorder) CreateOAuthClient(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).CreateOAuthClient), varargs...)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockAPIKeyServiceClient) CreateServiceAccount(ctx context.Context, in *userv1.CreateServiceAccountRequest, opts ...grpc.CallOption) (*userv1.CreateServiceAccountResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateServiceAccount", varargs...)
+	ret0, _ := ret[0].(*userv1.CreateServiceAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockAPIKeyServiceClientMockRecorder) CreateServiceAccount(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).CreateServiceAccount), varargs...)
+}
+
+// DeleteOAuthClient mocks base method.
+func (m *MockAPIKeyServiceClient) DeleteOAuthClient(ctx context.Context, in *userv1.DeleteOAuthClientRequest, opts ...grpc.CallOption) (*userv1.DeleteOAuthClientResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", varargs...)
+	ret0, _ := ret[0].(*userv1.DeleteOAuthClientResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockAPIKeyServiceClientMockRecorder) DeleteOAuthClient(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).DeleteOAuthClient), varargs...)
+}
+
+// DeleteServiceAccount mocks base method.
+func (m *MockAPIKeyServiceClient) DeleteServiceAccount(ctx context.Context, in *userv1.DeleteServiceAccountRequest, opts ...grpc.CallOption) (*userv1.DeleteServiceAccountResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteServiceAccount", varargs...)
+	ret0, _ := ret[0].(*userv1.DeleteServiceAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteServiceAccount indicates an expected call of DeleteServiceAccount.
+func (mr *MockAPIKeyServiceClientMockRecorder) DeleteServiceAccount(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceAccount", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).DeleteServiceAccount), varargs...)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyServiceClient) ListAPIKeys(ctx context.Context, in *userv1.ListAPIKeysRequest, opts ...grpc.CallOption) (*userv1.ListAPIKeysResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListAPIKeys", varargs...)
+	ret0, _ := ret[0].(*userv1.ListAPIKeysResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyServiceClientMockRecorder) ListAPIKeys(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).ListAPIKeys), varargs...)
+}
+
+// ListOAuthClients mocks base method.
+func (m *MockAPIKeyServiceClient) ListOAuthClients(ctx context.Context, in *userv1.ListOAuthClientsRequest, opts ...grpc.CallOption) (*userv1.ListOAuthClientsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListOAuthClients", varargs...)
+	ret0, _ := ret[0].(*userv1.ListOAuthClientsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockAPIKeyServiceClientMockRecorder) ListOAuthClients(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).ListOAuthClients), varargs...)
+}
+
+// ListServiceAccounts mocks base method.
+func (m *MockAPIKeyServiceClient) ListServiceAccounts(ctx context.Context, in *userv1.ListServiceAccountsRequest, opts ...grpc.CallOption) (*userv1.ListServiceAccountsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListServiceAccounts", varargs...)
+	ret0, _ := ret[0].(*userv1.ListServiceAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccounts indicates an expected call of ListServiceAccounts.
+func (mr *MockAPIKeyServiceClientMockRecorder) ListServiceAccounts(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccounts", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).ListServiceAccounts), varargs...)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyServiceClient) RevokeAPIKey(ctx context.Context, in *userv1.RevokeAPIKeyRequest, opts ...grpc.CallOption) (*userv1.RevokeAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevokeAPIKey", varargs...)
+	ret0, _ := ret[0].(*userv1.RevokeAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyServiceClientMockRecorder) RevokeAPIKey(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).RevokeAPIKey), varargs...)
+}
+
+// VerifyAPIKey mocks base method.
+func (m *MockAPIKeyServiceClient) VerifyAPIKey(ctx context.Context, in *userv1.VerifyAPIKeyRequest, opts ...grpc.CallOption) (*userv1.VerifyAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyAPIKey", varargs...)
+	ret0, _ := ret[0].(*userv1.VerifyAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAPIKey indicates an expected call of VerifyAPIKey.
+func (mr *MockAPIKeyServiceClientMockRecorder) VerifyAPIKey(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAPIKey", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).VerifyAPIKey), varargs...)
+}
+
+// VerifyClientCredentials mocks base method.
+func (m *MockAPIKeyServiceClient) VerifyClientCredentials(ctx context.Context, in *userv1.VerifyClientCredentialsRequest, opts ...grpc.CallOption) (*userv1.VerifyClientCredentialsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyClientCredentials", varargs...)
+	ret0, _ := ret[0].(*userv1.VerifyClientCredentialsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyClientCredentials indicates an expected call of VerifyClientCredentials.
+func (mr *MockAPIKeyServiceClientMockRecorder) VerifyClientCredentials(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyClientCredentials", reflect.TypeOf((*MockAPIKeyServiceClient)(nil).VerifyClientCredentials), varargs...)
+}
+
+// MockAPIKeyServiceServer is a mock of APIKeyServiceServer interface.
+type MockAPIKeyServiceServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyServiceServerMockRecorder
+}
+
+// MockAPIKeyServiceServerMockRecorder is the mock recorder for MockAPIKeyServiceServer.
+type MockAPIKeyServiceServerMockRecorder struct {
+	mock *MockAPIKeyServiceServer
+}
+
+// NewMockAPIKeyServiceServer creates a new mock instance.
+func NewMockAPIKeyServiceServer(ctrl *gomock.Controller) *MockAPIKeyServiceServer {
+	mock := &MockAPIKeyServiceServer{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyServiceServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyServiceServer) EXPECT() *MockAPIKeyServiceServerMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyServiceServer) CreateAPIKey(arg0 context.Context, arg1 *userv1.CreateAPIKeyRequest) (*userv1.CreateAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.CreateAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyServiceServerMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockAPIKeyServiceServer) CreateOAuthClient(arg0 context.Context, arg1 *userv1.CreateOAuthClientRequest) (*userv1.CreateOAuthClientResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.CreateOAuthClientResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockAPIKeyServiceServerMockRecorder) CreateOAuthClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).CreateOAuthClient), arg0, arg1)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockAPIKeyServiceServer) CreateServiceAccount(arg0 context.Context, arg1 *userv1.CreateServiceAccountRequest) (*userv1.CreateServiceAccountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.CreateServiceAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockAPIKeyServiceServerMockRecorder) CreateServiceAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).CreateServiceAccount), arg0, arg1)
+}
+
+// DeleteOAuthClient mocks base method.
+func (m *MockAPIKeyServiceServer) DeleteOAuthClient(arg0 context.Context, arg1 *userv1.DeleteOAuthClientRequest) (*userv1.DeleteOAuthClientResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.DeleteOAuthClientResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockAPIKeyServiceServerMockRecorder) DeleteOAuthClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).DeleteOAuthClient), arg0, arg1)
+}
+
+// DeleteServiceAccount mocks base method.
+func (m *MockAPIKeyServiceServer) DeleteServiceAccount(arg0 context.Context, arg1 *userv1.DeleteServiceAccountRequest) (*userv1.DeleteServiceAccountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteServiceAccount", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.DeleteServiceAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteServiceAccount indicates an expected call of DeleteServiceAccount.
+func (mr *MockAPIKeyServiceServerMockRecorder) DeleteServiceAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceAccount", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).DeleteServiceAccount), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyServiceServer) ListAPIKeys(arg0 context.Context, arg1 *userv1.ListAPIKeysRequest) (*userv1.ListAPIKeysResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.ListAPIKeysResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyServiceServerMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListOAuthClients mocks base method.
+func (m *MockAPIKeyServiceServer) ListOAuthClients(arg0 context.Context, arg1 *userv1.ListOAuthClientsRequest) (*userv1.ListOAuthClientsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthClients", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.ListOAuthClientsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockAPIKeyServiceServerMockRecorder) ListOAuthClients(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).ListOAuthClients), arg0, arg1)
+}
+
+// ListServiceAccounts mocks base method.
+func (m *MockAPIKeyServiceServer) ListServiceAccounts(arg0 context.Context, arg1 *userv1.ListServiceAccountsRequest) (*userv1.ListServiceAccountsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAccounts", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.ListServiceAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccounts indicates an expected call of ListServiceAccounts.
+func (mr *MockAPIKeyServiceServerMockRecorder) ListServiceAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccounts", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).ListServiceAccounts), arg0, arg1)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyServiceServer) RevokeAPIKey(arg0 context.Context, arg1 *userv1.RevokeAPIKeyRequest) (*userv1.RevokeAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.RevokeAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyServiceServerMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// VerifyAPIKey mocks base method.
+func (m *MockAPIKeyServiceServer) VerifyAPIKey(arg0 context.Context, arg1 *userv1.VerifyAPIKeyRequest) (*userv1.VerifyAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.VerifyAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAPIKey indicates an expected call of VerifyAPIKey.
+func (mr *MockAPIKeyServiceServerMockRecorder) VerifyAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAPIKey", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).VerifyAPIKey), arg0, arg1)
+}
+
+// VerifyClientCredentials mocks base method.
+func (m *MockAPIKeyServiceServer) VerifyClientCredentials(arg0 context.Context, arg1 *userv1.VerifyClientCredentialsRequest) (*userv1.VerifyClientCredentialsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyClientCredentials", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.VerifyClientCredentialsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyClientCredentials indicates an expected call of VerifyClientCredentials.
+func (mr *MockAPIKeyServiceServerMockRecorder) VerifyClientCredentials(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyClientCredentials", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).VerifyClientCredentials), arg0, arg1)
+}
+
+// mustEmbedUnimplementedAPIKeyServiceServer mocks base method.
+func (m *MockAPIKeyServiceServer) mustEmbedUnimplementedAPIKeyServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedAPIKeyServiceServer")
+}
+
+// mustEmbedUnimplementedAPIKeyServiceServer indicates an expected call of mustEmbedUnimplementedAPIKeyServiceServer.
+func (mr *MockAPIKeyServiceServerMockRecorder) mustEmbedUnimplementedAPIKeyServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedAPIKeyServiceServer", reflect.TypeOf((*MockAPIKeyServiceServer)(nil).mustEmbedUnimplementedAPIKeyServiceServer))
+}
+
+// MockUnsafeAPIKeyServiceServer is a mock of UnsafeAPIKeyServiceServer interface.
+type MockUnsafeAPIKeyServiceServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnsafeAPIKeyServiceServerMockRecorder
+}
+
+// MockUnsafeAPIKeyServiceServerMockRecorder is the mock recorder for MockUnsafeAPIKeyServiceServer.
+type MockUnsafeAPIKeyServiceServerMockRecorder struct {
+	mock *MockUnsafeAPIKeyServiceServer
+}
+
+// NewMockUnsafeAPIKeyServiceServer creates a new mock instance.
+func NewMockUnsafeAPIKeyServiceServer(ctrl *gomock.Controller) *MockUnsafeAPIKeyServiceServer {
+	mock := &MockUnsafeAPIKeyServiceServer{ctrl: ctrl}
+	mock.recorder = &MockUnsafeAPIKeyServiceServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnsafeAPIKeyServiceServer) EXPECT() *MockUnsafeAPIKeyServiceServerMockRecorder {
+	return m.recorder
+}
+
+// mustEmbedUnimplementedAPIKeyServiceServer mocks base method.
+func (m *MockUnsafeAPIKeyServiceServer) mustEmbedUnimplementedAPIKeyServiceServer() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "mustEmbedUnimplementedAPIKeyServiceServer")
+}
+
+// mustEmbedUnimplementedAPIKeyServiceServer indicates an expected call of mustEmbedUnimplementedAPIKeyServiceServer.
+func (mr *MockUnsafeAPIKeyServiceServerMockRecorder) mustEmbedUnimplementedAPIKeyServiceServer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "mustEmbedUnimplementedAPIKeyServiceServer", reflect.TypeOf((*MockUnsafeAPIKeyServiceServer)(nil).mustEmbedUnimplementedAPIKeyServiceServer))
+}
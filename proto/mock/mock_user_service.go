@@ -56,6 +56,26 @@ func (mr *MockUserServiceClientMockRecorder) CreateUser(ctx, in interface{}, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserServiceClient)(nil).CreateUser), varargs...)
 }
 
+// DeleteCurrentUser mocks base method.
+func (m *MockUserServiceClient) DeleteCurrentUser(ctx context.Context, in *userv1.DeleteCurrentUserRequest, opts ...grpc.CallOption) (*userv1.DeleteCurrentUserResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteCurrentUser", varargs...)
+	ret0, _ := ret[0].(*userv1.DeleteCurrentUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCurrentUser indicates an expected call of DeleteCurrentUser.
+func (mr *MockUserServiceClientMockRecorder) DeleteCurrentUser(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCurrentUser", reflect.TypeOf((*MockUserServiceClient)(nil).DeleteCurrentUser), varargs...)
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserServiceClient) DeleteUser(ctx context.Context, in *userv1.DeleteUserRequest, opts ...grpc.CallOption) (*userv1.DeleteUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -76,6 +96,26 @@ func (mr *MockUserServiceClientMockRecorder) DeleteUser(ctx, in interface{}, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserServiceClient)(nil).DeleteUser), varargs...)
 }
 
+// GetCurrentUser mocks base method.
+func (m *MockUserServiceClient) GetCurrentUser(ctx context.Context, in *userv1.GetCurrentUserRequest, opts ...grpc.CallOption) (*userv1.GetCurrentUserResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetCurrentUser", varargs...)
+	ret0, _ := ret[0].(*userv1.GetCurrentUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentUser indicates an expected call of GetCurrentUser.
+func (mr *MockUserServiceClientMockRecorder) GetCurrentUser(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentUser", reflect.TypeOf((*MockUserServiceClient)(nil).GetCurrentUser), varargs...)
+}
+
 // GetUser mocks base method.
 func (m *MockUserServiceClient) GetUser(ctx context.Context, in *userv1.GetUserRequest, opts ...grpc.CallOption) (*userv1.GetUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +156,106 @@ func (mr *MockUserServiceClientMockRecorder) GetUsers(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserServiceClient)(nil).GetUsers), varargs...)
 }
 
+// GrantRole mocks base method.
+func (m *MockUserServiceClient) GrantRole(ctx context.Context, in *userv1.GrantRoleRequest, opts ...grpc.CallOption) (*userv1.GrantRoleResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GrantRole", varargs...)
+	ret0, _ := ret[0].(*userv1.GrantRoleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockUserServiceClientMockRecorder) GrantRole(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockUserServiceClient)(nil).GrantRole), varargs...)
+}
+
+// ResendVerificationEmail mocks base method.
+func (m *MockUserServiceClient) ResendVerificationEmail(ctx context.Context, in *userv1.ResendVerificationEmailRequest, opts ...grpc.CallOption) (*userv1.ResendVerificationEmailResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResendVerificationEmail", varargs...)
+	ret0, _ := ret[0].(*userv1.ResendVerificationEmailResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResendVerificationEmail indicates an expected call of ResendVerificationEmail.
+func (mr *MockUserServiceClientMockRecorder) ResendVerificationEmail(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockUserServiceClient)(nil).ResendVerificationEmail), varargs...)
+}
+
+// RevokeRole mocks base method.
+func (m *MockUserServiceClient) RevokeRole(ctx context.Context, in *userv1.RevokeRoleRequest, opts ...grpc.CallOption) (*userv1.RevokeRoleResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevokeRole", varargs...)
+	ret0, _ := ret[0].(*userv1.RevokeRoleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockUserServiceClientMockRecorder) RevokeRole(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockUserServiceClient)(nil).RevokeRole), varargs...)
+}
+
+// UnlockUser mocks base method.
+func (m *MockUserServiceClient) UnlockUser(ctx context.Context, in *userv1.UnlockUserRequest, opts ...grpc.CallOption) (*userv1.UnlockUserResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UnlockUser", varargs...)
+	ret0, _ := ret[0].(*userv1.UnlockUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockUserServiceClientMockRecorder) UnlockUser(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockUserServiceClient)(nil).UnlockUser), varargs...)
+}
+
+// UpdateCurrentUser mocks base method.
+func (m *MockUserServiceClient) UpdateCurrentUser(ctx context.Context, in *userv1.UpdateCurrentUserRequest, opts ...grpc.CallOption) (*userv1.UpdateCurrentUserResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateCurrentUser", varargs...)
+	ret0, _ := ret[0].(*userv1.UpdateCurrentUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCurrentUser indicates an expected call of UpdateCurrentUser.
+func (mr *MockUserServiceClientMockRecorder) UpdateCurrentUser(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrentUser", reflect.TypeOf((*MockUserServiceClient)(nil).UpdateCurrentUser), varargs...)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserServiceClient) UpdateUser(ctx context.Context, in *userv1.UpdateUserRequest, opts ...grpc.CallOption) (*userv1.UpdateUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -136,6 +276,26 @@ func (mr *MockUserServiceClientMockRecorder) UpdateUser(ctx, in interface{}, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserServiceClient)(nil).UpdateUser), varargs...)
 }
 
+// VerifyEmail mocks base method.
+func (m *MockUserServiceClient) VerifyEmail(ctx context.Context, in *userv1.VerifyEmailRequest, opts ...grpc.CallOption) (*userv1.VerifyEmailResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyEmail", varargs...)
+	ret0, _ := ret[0].(*userv1.VerifyEmailResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserServiceClientMockRecorder) VerifyEmail(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserServiceClient)(nil).VerifyEmail), varargs...)
+}
+
 // MockUserServiceServer is a mock of UserServiceServer interface.
 type MockUserServiceServer struct {
 	ctrl     *gomock.Controller
@@ -174,6 +334,21 @@ func (mr *MockUserServiceServerMockRecorder) CreateUser(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserServiceServer)(nil).CreateUser), arg0, arg1)
 }
 
+// DeleteCurrentUser mocks base method.
+func (m *MockUserServiceServer) DeleteCurrentUser(arg0 context.Context, arg1 *userv1.DeleteCurrentUserRequest) (*userv1.DeleteCurrentUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCurrentUser", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.DeleteCurrentUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCurrentUser indicates an expected call of DeleteCurrentUser.
+func (mr *MockUserServiceServerMockRecorder) DeleteCurrentUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCurrentUser", reflect.TypeOf((*MockUserServiceServer)(nil).DeleteCurrentUser), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserServiceServer) DeleteUser(arg0 context.Context, arg1 *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -189,6 +364,21 @@ func (mr *MockUserServiceServerMockRecorder) DeleteUser(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserServiceServer)(nil).DeleteUser), arg0, arg1)
 }
 
+// GetCurrentUser mocks base method.
+func (m *MockUserServiceServer) GetCurrentUser(arg0 context.Context, arg1 *userv1.GetCurrentUserRequest) (*userv1.GetCurrentUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentUser", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.GetCurrentUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentUser indicates an expected call of GetCurrentUser.
+func (mr *MockUserServiceServerMockRecorder) GetCurrentUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentUser", reflect.TypeOf((*MockUserServiceServer)(nil).GetCurrentUser), arg0, arg1)
+}
+
 // GetUser mocks base method.
 func (m *MockUserServiceServer) GetUser(arg0 context.Context, arg1 *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -219,6 +409,81 @@ func (mr *MockUserServiceServerMockRecorder) GetUsers(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserServiceServer)(nil).GetUsers), arg0, arg1)
 }
 
+// GrantRole mocks base method.
+func (m *MockUserServiceServer) GrantRole(arg0 context.Context, arg1 *userv1.GrantRoleRequest) (*userv1.GrantRoleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.GrantRoleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockUserServiceServerMockRecorder) GrantRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockUserServiceServer)(nil).GrantRole), arg0, arg1)
+}
+
+// ResendVerificationEmail mocks base method.
+func (m *MockUserServiceServer) ResendVerificationEmail(arg0 context.Context, arg1 *userv1.ResendVerificationEmailRequest) (*userv1.ResendVerificationEmailResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerificationEmail", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.ResendVerificationEmailResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResendVerificationEmail indicates an expected call of ResendVerificationEmail.
+func (mr *MockUserServiceServerMockRecorder) ResendVerificationEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockUserServiceServer)(nil).ResendVerificationEmail), arg0, arg1)
+}
+
+// RevokeRole mocks base method.
+func (m *MockUserServiceServer) RevokeRole(arg0 context.Context, arg1 *userv1.RevokeRoleRequest) (*userv1.RevokeRoleResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.RevokeRoleResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockUserServiceServerMockRecorder) RevokeRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockUserServiceServer)(nil).RevokeRole), arg0, arg1)
+}
+
+// UnlockUser mocks base method.
+func (m *MockUserServiceServer) UnlockUser(arg0 context.Context, arg1 *userv1.UnlockUserRequest) (*userv1.UnlockUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.UnlockUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockUserServiceServerMockRecorder) UnlockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockUserServiceServer)(nil).UnlockUser), arg0, arg1)
+}
+
+// UpdateCurrentUser mocks base method.
+func (m *MockUserServiceServer) UpdateCurrentUser(arg0 context.Context, arg1 *userv1.UpdateCurrentUserRequest) (*userv1.UpdateCurrentUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCurrentUser", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.UpdateCurrentUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCurrentUser indicates an expected call of UpdateCurrentUser.
+func (mr *MockUserServiceServerMockRecorder) UpdateCurrentUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrentUser", reflect.TypeOf((*MockUserServiceServer)(nil).UpdateCurrentUser), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserServiceServer) UpdateUser(arg0 context.Context, arg1 *userv1.UpdateUserRequest) (*userv1.UpdateUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -234,6 +499,21 @@ func (mr *MockUserServiceServerMockRecorder) UpdateUser(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserServiceServer)(nil).UpdateUser), arg0, arg1)
 }
 
+// VerifyEmail mocks base method.
+func (m *MockUserServiceServer) VerifyEmail(arg0 context.Context, arg1 *userv1.VerifyEmailRequest) (*userv1.VerifyEmailResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0, arg1)
+	ret0, _ := ret[0].(*userv1.VerifyEmailResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserServiceServerMockRecorder) VerifyEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserServiceServer)(nil).VerifyEmail), arg0, arg1)
+}
+
 // mustEmbedUnimplementedUserServiceServer mocks base method.
 func (m *MockUserServiceServer) mustEmbedUnimplementedUserServiceServer() {
 	m.ctrl.T.Helper()